@@ -0,0 +1,135 @@
+package cea608
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Colors are the foreground colours of preamble address and mid-row codes, in the order of their attribute codes
+var Colors = []string{"white", "green", "blue", "cyan", "red", "yellow", "magenta"}
+
+// basicCharacters are the characters of the basic set that differ from ASCII
+var basicCharacters = map[byte]rune{
+	0x2A: 'á', 0x5C: 'é', 0x5E: 'í', 0x5F: 'ó', 0x60: 'ú', 0x7B: 'ç', 0x7C: '÷', 0x7D: 'Ñ', 0x7E: 'ñ', 0x7F: '█',
+}
+
+// specialCharacters are sent with a first byte of 0x11 (0x19 on data channel 2), 0x39 is a transparent space
+var specialCharacters = []rune{
+	'®', '°', '½', '¿', '™', '¢', '£', '♪', 'à', '\u00A0', 'è', 'â', 'ê', 'î', 'ô', 'û',
+}
+
+// extendedCharacters are sent with a first byte of 0x12 or 0x13 (0x1A or 0x1B on data channel 2) and replace the
+// basic character sent before them, which decoders without the extended set show instead
+var extendedCharacters = [2][]rune{
+	{
+		'Á', 'É', 'Ó', 'Ú', 'Ü', 'ü', '‘', '¡', '*', '\'', '—', '©', '℠', '•', '“', '”',
+		'À', 'Â', 'Ç', 'È', 'Ê', 'Ë', 'ë', 'Î', 'Ï', 'ï', 'Ô', 'Ù', 'ù', 'Û', '«', '»',
+	},
+	{
+		'Ã', 'ã', 'Í', 'Ì', 'ì', 'Ò', 'ò', 'Õ', 'õ', '{', '}', '\\', '^', '_', '|', '~',
+		'Ä', 'ä', 'Ö', 'ö', 'ß', '¥', '¤', '│', 'Å', 'å', 'Ø', 'ø', '┌', '┐', '└', '┘',
+	},
+}
+
+// basicCharacter returns the character of a byte of the basic set
+func basicCharacter(b byte) rune {
+	if character, exists := basicCharacters[b]; exists {
+		return character
+	}
+
+	return rune(b)
+}
+
+// ColorIndex returns the index in Colors of the colour closest to an HTML colour name or hex value, colours
+// without a caption colour such as black are white
+func ColorIndex(color string) int {
+	color = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(color), "#"))
+	switch color {
+	case "lime":
+		color = "green"
+	case "fuchsia":
+		color = "magenta"
+	case "aqua":
+		color = "cyan"
+	}
+
+	for i, name := range Colors {
+		if name == color {
+			return i
+		}
+	}
+
+	value, parseErr := strconv.ParseUint(color, 16, 32)
+	if parseErr != nil || len(color) != 6 {
+		return 0
+	}
+
+	//Each of the red, green and blue components is either on or off
+	switch red, green, blue := value>>16&0xFF >= 0x80, value>>8&0xFF >= 0x80, value&0xFF >= 0x80; {
+	case red && green && !blue:
+		return 5
+	case red && !green && blue:
+		return 6
+	case !red && green && blue:
+		return 3
+	case red && !green && !blue:
+		return 4
+	case !red && green && !blue:
+		return 1
+	case !red && !green && blue:
+		return 2
+	}
+
+	return 0
+}
+
+// encodeCharacter returns the byte of a character of the basic set, or the byte pair of a special or extended
+// character together with the basic character to send before it. The first byte of pairs is for data channel 1
+func encodeCharacter(character rune) (byte, [2]byte, bool) {
+	for b, basic := range basicCharacters {
+		if basic == character {
+			return b, [2]byte{}, true
+		}
+	}
+	if character >= 0x20 && character < 0x7F {
+		if _, isReplaced := basicCharacters[byte(character)]; !isReplaced {
+			return byte(character), [2]byte{}, true
+		}
+	}
+
+	for i, special := range specialCharacters {
+		if special == character {
+			return 0, [2]byte{0x11, 0x30 + byte(i)}, true
+		}
+	}
+
+	for set, characters := range extendedCharacters {
+		for i, extended := range characters {
+			if extended == character {
+				return fallbackCharacter(character), [2]byte{0x12 + byte(set), 0x20 + byte(i)}, true
+			}
+		}
+	}
+
+	//Letters with other accents are sent without the accent
+	if decomposed := []rune(norm.NFD.String(string(character))); len(decomposed) > 1 && decomposed[0] < 0x7F {
+		return encodeCharacter(decomposed[0])
+	}
+
+	return 0, [2]byte{}, false
+}
+
+// fallbackCharacter returns the basic character shown for an extended character by decoders that lack the
+// extended set: the letter without its accent, or a space
+func fallbackCharacter(character rune) byte {
+	decomposed := []rune(norm.NFD.String(string(character)))
+	if len(decomposed) > 0 && decomposed[0] > 0x20 && decomposed[0] < 0x7F {
+		if _, isReplaced := basicCharacters[byte(decomposed[0])]; !isReplaced {
+			return byte(decomposed[0])
+		}
+	}
+
+	return ' '
+}
@@ -0,0 +1,461 @@
+package cea608
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	Columns = 32
+	Rows    = 15
+)
+
+type captionMode int
+
+const (
+	captionModePopOn captionMode = iota
+	captionModePaintOn
+	captionModeRollUp
+	captionModeText
+)
+
+// Miscellaneous control codes, the second byte after a first byte of 0x14 (0x1C on data channel 2)
+const (
+	controlResumeCaptionLoading    = 0x20
+	controlBackspace               = 0x21
+	controlDeleteToEndOfRow        = 0x24
+	controlRollUp2                 = 0x25
+	controlRollUp3                 = 0x26
+	controlRollUp4                 = 0x27
+	controlResumeDirectCaptioning  = 0x29
+	controlTextRestart             = 0x2A
+	controlResumeTextDisplay       = 0x2B
+	controlEraseDisplayedMemory    = 0x2C
+	controlCarriageReturn          = 0x2D
+	controlEraseNonDisplayedMemory = 0x2E
+	controlEndOfCaption            = 0x2F
+)
+
+// Tab offsets move the cursor one to three columns, the second byte after a first byte of 0x17 (0x1F on data
+// channel 2)
+const (
+	controlTabOffset1 = 0x21
+	controlTabOffset3 = 0x23
+)
+
+// Attributes of preamble address codes, above the italics attribute are the indents of 0 to 28 columns
+const (
+	preambleAddressItalicsAttribute  = 7
+	preambleAddressFirstIndentOffset = 8
+)
+
+// preambleAddressRows maps the first byte of a preamble address code, without the channel bit, to its rows. The
+// second byte selects the first row if it is below 0x60
+var preambleAddressRows = map[byte][2]int{
+	0x10: {11, 11}, 0x11: {1, 2}, 0x12: {3, 4}, 0x13: {12, 13}, 0x14: {14, 15}, 0x15: {5, 6}, 0x16: {7, 8}, 0x17: {9, 10},
+}
+
+// Caption is a caption as it was shown on screen, with its start and end in milliseconds
+type Caption struct {
+	End float64
+	//Row is the top row of the caption, from 1 to 15
+	Row   int
+	Start float64
+	Text  string
+}
+
+func (c *Caption) String() string {
+	return fmt.Sprintf("Start: %v , End: %v , Row: %v , Text: %v", c.Start, c.End, c.Row, c.Text)
+}
+
+// Cell is a character on screen with its formatting, cells without a character are empty
+type Cell struct {
+	Character rune
+	//Color is an index in Colors
+	Color     int
+	Italic    bool
+	Underline bool
+}
+
+type memory [Rows][Columns]Cell
+
+func (m *memory) clear() {
+	*m = memory{}
+}
+
+// render returns the text of the non-empty rows with formatting tags, and the top row
+func (m *memory) render() (string, int) {
+	lines := []string{}
+	topRow := 0
+
+	for row := range m {
+		if line := RenderRow(m[row][:]); line != "" {
+			if len(lines) == 0 {
+				topRow = row + 1
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n"), topRow
+}
+
+// Decoder decodes the byte pairs of one data channel of a CEA-608 field into captions. Pop-on captions start when
+// they are flipped on screen, roll-up captions whenever a row is completed and paint-on captions as they are
+// erased or completed
+type Decoder struct {
+	//Channel is the data channel of the field, 1 (CC1 or CC3) or 2 (CC2 or CC4)
+	Channel int
+
+	captions       []Caption
+	changedSince   float64
+	color          int
+	column         int
+	currentChannel int
+	displayed      memory
+	hasChanges     bool
+	italic         bool
+	lastControl    [2]byte
+	mode           captionMode
+	nonDisplayed   memory
+	now            float64
+	rollUpRows     int
+	row            int
+	shownRow       int
+	shownSince     float64
+	shownText      string
+	underline      bool
+}
+
+func NewDecoder(channel int) *Decoder {
+	return &Decoder{Channel: channel, row: Rows - 1}
+}
+
+// Captions returns the captions completed so far
+func (d *Decoder) Captions() []Caption {
+	return d.captions
+}
+
+func (d *Decoder) carriageReturn() {
+	if d.mode != captionModeRollUp {
+		return
+	}
+
+	//The rows of the roll-up window move up, the rows above the window are erased
+	top := max(0, d.row-d.rollUpRows+1)
+	for row := 0; row < d.row; row++ {
+		if row >= top {
+			d.displayed[row] = d.displayed[row+1]
+		} else {
+			d.displayed[row] = [Columns]Cell{}
+		}
+	}
+	d.displayed[d.row] = [Columns]Cell{}
+	d.column = 0
+}
+
+// Decode decodes a byte pair received at a time in milliseconds. The parity bits are ignored
+func (d *Decoder) Decode(first byte, second byte, milliseconds float64) {
+	first, second = first&0x7F, second&0x7F
+	d.now = milliseconds
+
+	//Padding
+	if first == 0 && second == 0 {
+		return
+	}
+
+	if first >= 0x10 && first <= 0x1F {
+		//Control codes are sent twice, the repetition is ignored
+		if d.lastControl == [2]byte{first, second} {
+			d.lastControl = [2]byte{}
+
+			return
+		}
+		d.lastControl = [2]byte{first, second}
+
+		d.currentChannel = 1
+		if first&0x08 != 0 {
+			d.currentChannel = 2
+		}
+		if d.currentChannel == d.Channel {
+			d.decodeControl(first&0xF7, second, milliseconds)
+		}
+
+		return
+	}
+	d.lastControl = [2]byte{}
+
+	//Extended data services on field 2 are not captions
+	if first < 0x10 {
+		d.currentChannel = 0
+
+		return
+	}
+
+	if d.currentChannel != d.Channel || d.mode == captionModeText {
+		return
+	}
+
+	d.write(basicCharacter(first))
+	if second >= 0x20 {
+		d.write(basicCharacter(second))
+	}
+}
+
+func (d *Decoder) decodeControl(first byte, second byte, milliseconds float64) {
+	switch {
+	case second >= 0x40:
+		d.decodePreambleAddress(first, second)
+	case first == 0x11 && second >= 0x20 && second < 0x30:
+		//Mid-row codes take a space and change the attributes of what follows
+		d.write(' ')
+		d.setAttributes((second&0x0E)>>1, second&0x01 != 0, false)
+	case first == 0x11 && second >= 0x30 && second < 0x40:
+		d.write(specialCharacters[second-0x30])
+	case (first == 0x12 || first == 0x13) && second >= 0x20 && second < 0x40:
+		d.backspace()
+		d.write(extendedCharacters[first-0x12][second-0x20])
+	case first == 0x17 && second >= controlTabOffset1 && second <= controlTabOffset3:
+		d.column = min(Columns-1, d.column+int(second-0x20))
+	case first == 0x14 || first == 0x15:
+		d.decodeMiscellaneousControl(second, milliseconds)
+	}
+}
+
+func (d *Decoder) decodeMiscellaneousControl(second byte, milliseconds float64) {
+	switch second {
+	case controlResumeCaptionLoading:
+		d.update(milliseconds)
+		d.mode = captionModePopOn
+	case controlBackspace:
+		d.backspace()
+	case controlDeleteToEndOfRow:
+		d.touch()
+		memory := d.memory()
+		for column := d.column; column < Columns; column++ {
+			memory[d.row][column] = Cell{}
+		}
+	case controlRollUp2, controlRollUp3, controlRollUp4:
+		if d.mode != captionModeRollUp {
+			d.update(milliseconds)
+			d.displayed.clear()
+			d.nonDisplayed.clear()
+			d.update(milliseconds)
+		}
+
+		d.mode = captionModeRollUp
+		d.rollUpRows = int(second-controlRollUp2) + 2
+		d.column = 0
+	case controlResumeDirectCaptioning:
+		d.update(milliseconds)
+		d.mode = captionModePaintOn
+	case controlTextRestart, controlResumeTextDisplay:
+		d.mode = captionModeText
+	case controlEraseDisplayedMemory:
+		//What was painted or rolled up since the last update was on screen until now
+		d.update(milliseconds)
+		d.displayed.clear()
+		d.update(milliseconds)
+	case controlCarriageReturn:
+		d.update(milliseconds)
+		d.carriageReturn()
+	case controlEraseNonDisplayedMemory:
+		d.nonDisplayed.clear()
+	case controlEndOfCaption:
+		d.update(milliseconds)
+		d.displayed, d.nonDisplayed = d.nonDisplayed, d.displayed
+		d.mode = captionModePopOn
+		d.update(milliseconds)
+	}
+}
+
+func (d *Decoder) decodePreambleAddress(first byte, second byte) {
+	rows, exists := preambleAddressRows[first]
+	if !exists {
+		return
+	}
+
+	row := rows[0]
+	if second&0x20 != 0 {
+		row = rows[1]
+	}
+
+	//In roll-up mode the address moves the base row, taking the rows of the window with it
+	if d.mode == captionModeRollUp && row-1 != d.row {
+		d.touch()
+
+		window := memory{}
+		for offset := range d.rollUpRows {
+			if from, to := d.row-offset, row-1-offset; from >= 0 && to >= 0 {
+				window[to] = d.displayed[from]
+			}
+		}
+		d.displayed = window
+	}
+	d.row = row - 1
+	d.column = 0
+
+	attribute := (second & 0x1E) >> 1
+	if attribute >= preambleAddressFirstIndentOffset {
+		d.column = int(attribute-preambleAddressFirstIndentOffset) * 4
+		attribute = 0
+	}
+	d.setAttributes(attribute, second&0x01 != 0, true)
+}
+
+// Flush ends the caption on screen at a time in milliseconds, and returns all captions
+func (d *Decoder) Flush(milliseconds float64) []Caption {
+	d.update(milliseconds)
+	if d.shownText != "" {
+		d.captions = append(d.captions, Caption{End: milliseconds, Row: d.shownRow, Start: d.shownSince, Text: d.shownText})
+		d.shownText = ""
+	}
+
+	return d.captions
+}
+
+func (d *Decoder) backspace() {
+	d.touch()
+	if d.column > 0 {
+		d.column--
+	}
+	d.memory()[d.row][d.column] = Cell{}
+}
+
+// memory returns the memory characters are written to, which is on screen except for pop-on captions
+func (d *Decoder) memory() *memory {
+	if d.mode == captionModePopOn {
+		return &d.nonDisplayed
+	}
+
+	return &d.displayed
+}
+
+func (d *Decoder) setAttributes(attribute byte, underline bool, isPreamble bool) {
+	d.underline = underline
+
+	if attribute == preambleAddressItalicsAttribute {
+		//Italics keep the colour of mid-row codes but are white after a preamble address code
+		d.italic = true
+		if isPreamble {
+			d.color = 0
+		}
+
+		return
+	}
+
+	d.color = int(attribute)
+	d.italic = false
+}
+
+// touch records when what is on screen started to change, characters of pop-on captions are not on screen until
+// the end of caption code
+func (d *Decoder) touch() {
+	if d.mode != captionModePopOn && !d.hasChanges {
+		d.changedSince, d.hasChanges = d.now, true
+	}
+}
+
+// update ends the caption on screen and starts the next one when what is on screen has changed
+func (d *Decoder) update(milliseconds float64) {
+	start := milliseconds
+	if d.hasChanges {
+		start = d.changedSince
+	}
+	d.hasChanges = false
+
+	text, row := d.displayed.render()
+	if text == d.shownText {
+		return
+	}
+
+	if d.shownText != "" {
+		d.captions = append(d.captions, Caption{End: start, Row: d.shownRow, Start: d.shownSince, Text: d.shownText})
+	}
+
+	d.shownRow, d.shownSince, d.shownText = row, start, text
+}
+
+func (d *Decoder) write(character rune) {
+	d.touch()
+	d.memory()[d.row][d.column] = Cell{Character: character, Color: d.color, Italic: d.italic, Underline: d.underline}
+	d.column = min(Columns-1, d.column+1)
+}
+
+// RenderRow returns the text of a row of cells with formatting tags, or an empty string if the row shows nothing.
+// Empty cells are spaces, which are moved out of the tags and trimmed at the start and end of the row
+func RenderRow(row []Cell) string {
+	builder := strings.Builder{}
+	current := Cell{}
+
+	closeTags := func() {
+		if current.Color != 0 {
+			builder.WriteString("</font>")
+		}
+		if current.Underline {
+			builder.WriteString("</u>")
+		}
+		if current.Italic {
+			builder.WriteString("</i>")
+		}
+		current = Cell{}
+	}
+
+	for _, c := range row {
+		if c.Character == 0 {
+			closeTags()
+			builder.WriteString(" ")
+
+			continue
+		}
+
+		if c.Color != current.Color || c.Italic != current.Italic || c.Underline != current.Underline {
+			closeTags()
+
+			if c.Italic {
+				builder.WriteString("<i>")
+			}
+			if c.Underline {
+				builder.WriteString("<u>")
+			}
+			if c.Color != 0 {
+				builder.WriteString(`<font color="` + Colors[c.Color] + `">`)
+			}
+			current = c
+		}
+
+		builder.WriteRune(c.Character)
+	}
+	closeTags()
+
+	line := strings.TrimSpace(moveSpacesOutOfTags(builder.String()))
+	if isEmptyTags(line) {
+		return ""
+	}
+
+	return line
+}
+
+func isEmptyTags(text string) bool {
+	for _, tag := range []string{"<i>", "</i>", "<u>", "</u>", "</font>"} {
+		text = strings.ReplaceAll(text, tag, "")
+	}
+	for _, color := range Colors {
+		text = strings.ReplaceAll(text, `<font color="`+color+`">`, "")
+	}
+
+	return strings.TrimSpace(text) == ""
+}
+
+var (
+	closingTagSpacesRegex = regexp.MustCompile(`(\s+)((?:</i>|</u>|</font>)+)`)
+	openingTagSpacesRegex = regexp.MustCompile(`((?:<i>|<u>|<font color="[a-z]+">)+)(\s+)`)
+)
+
+// moveSpacesOutOfTags moves the spaces at the start and end of formatted text out of the tags
+func moveSpacesOutOfTags(text string) string {
+	text = closingTagSpacesRegex.ReplaceAllString(text, "$2$1")
+
+	return openingTagSpacesRegex.ReplaceAllString(text, "$2$1")
+}
@@ -0,0 +1,136 @@
+package cea608
+
+import (
+	"slices"
+	"testing"
+)
+
+const frameMilliseconds = 1000.0 / 30
+
+// decodePairs decodes byte pairs one frame apart from a frame number, and returns the frame after the last pair
+func decodePairs(decoder *Decoder, pairs [][2]byte, frame int) int {
+	for _, pair := range pairs {
+		decoder.Decode(pair[0], pair[1], float64(frame)*frameMilliseconds)
+		frame++
+	}
+
+	return frame
+}
+
+func checkCaptions(t *testing.T, name string, captions []Caption, expected []Caption) {
+	t.Helper()
+
+	if !slices.EqualFunc(captions, expected, func(a, b Caption) bool {
+		return a.Text == b.Text && a.Row == b.Row && int(a.Start+0.5) == int(b.Start+0.5) && int(a.End+0.5) == int(b.End+0.5)
+	}) {
+		t.Errorf("%s: got %v, expected %v", name, captions, expected)
+	}
+}
+
+func TestDecoderPopOn(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	pairs := EncodePopOn("<i>Hello</i>\nworld", 14, 1)
+	frame := decodePairs(decoder, pairs, 0)
+	//The first of the two end of caption codes shows the caption
+	shown := float64(len(pairs)-2) * frameMilliseconds
+
+	frame = decodePairs(decoder, EncodeEraseDisplayedMemory(1), frame+10)
+	erased := float64(frame-2) * frameMilliseconds
+
+	checkCaptions(t, "pop-on", decoder.Flush(float64(frame)*frameMilliseconds), []Caption{
+		{End: erased, Row: 14, Start: shown, Text: "<i>Hello</i>\nworld"},
+	})
+}
+
+func TestDecoderPopOnReplacedByNextCaption(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	first := EncodePopOn("First", 15, 1)
+	frame := decodePairs(decoder, first, 0)
+	second := EncodePopOn("Second", 1, 1)
+	frame = decodePairs(decoder, second, frame)
+
+	checkCaptions(t, "replaced", decoder.Flush(float64(frame)*frameMilliseconds), []Caption{
+		{End: float64(len(first)+len(second)-2) * frameMilliseconds, Row: 15, Start: float64(len(first)-2) * frameMilliseconds, Text: "First"},
+		{End: float64(frame) * frameMilliseconds, Row: 1, Start: float64(len(first)+len(second)-2) * frameMilliseconds, Text: "Second"},
+	})
+}
+
+func TestDecoderRollUp(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	//Roll-up 2 rows on row 15, a carriage return moves the rows up and the top row out of the window, captions change
+	//when characters are written
+	frame := decodePairs(decoder, [][2]byte{
+		{0x14, 0x25}, {0x14, 0x25}, {0x14, 0x60}, {0x14, 0x60}, {'H', 'i'},
+		{0x14, 0x2D}, {0x14, 0x2D}, {'Y', 'o'},
+		{0x14, 0x2D}, {0x14, 0x2D}, {'B', 'y'}, {'e', 0},
+	}, 0)
+
+	checkCaptions(t, "roll-up", decoder.Flush(float64(frame)*frameMilliseconds), []Caption{
+		{End: 7 * frameMilliseconds, Row: 15, Start: 4 * frameMilliseconds, Text: "Hi"},
+		{End: 10 * frameMilliseconds, Row: 14, Start: 7 * frameMilliseconds, Text: "Hi\nYo"},
+		{End: float64(frame) * frameMilliseconds, Row: 14, Start: 10 * frameMilliseconds, Text: "Yo\nBye"},
+	})
+}
+
+func TestDecoderCharactersAndAttributes(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	frame := decodePairs(decoder, [][2]byte{
+		{0x14, 0x29}, {0x14, 0x29}, {0x14, 0x60}, {0x14, 0x60},
+		//é, a note, then A followed by the extended Á replacing it
+		{0x5C, 0x20}, {0x11, 0x37}, {0x11, 0x37}, {0x20, 'A'}, {0x12, 0x20}, {0x12, 0x20},
+		//A mid-row code to red underlined text takes a space
+		{0x11, 0x29}, {0x11, 0x29}, {'R', 'e'}, {'d', 0},
+	}, 0)
+
+	captions := decoder.Flush(float64(frame) * frameMilliseconds)
+	if len(captions) == 0 || captions[len(captions)-1].Text != `é ♪ Á <u><font color="red">Red</font></u>` {
+		t.Errorf("got %v", captions)
+	}
+}
+
+func TestDecoderIgnoresOtherChannel(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	pairs := EncodePopOn("Channel 2", 15, 2)
+	frame := decodePairs(decoder, pairs, 0)
+
+	if captions := decoder.Flush(float64(frame) * frameMilliseconds); len(captions) != 0 {
+		t.Errorf("got %v, expected no captions", captions)
+	}
+
+	decoder = NewDecoder(2)
+	frame = decodePairs(decoder, pairs, 0)
+	checkCaptions(t, "channel 2", decoder.Flush(float64(frame)*frameMilliseconds), []Caption{
+		{End: float64(frame) * frameMilliseconds, Row: 15, Start: float64(len(pairs)-2) * frameMilliseconds, Text: "Channel 2"},
+	})
+}
+
+func TestMoveSpacesOutOfTags(t *testing.T) {
+	tests := map[string]string{
+		"<i> text </i>":                          " <i>text</i> ",
+		`<font color="red"> red</font>`:          ` <font color="red">red</font>`,
+		"plain text":                             "plain text",
+		`<i><font color="blue">  a  </font></i>`: `  <i><font color="blue">a</font></i>  `,
+	}
+
+	for text, expected := range tests {
+		if moved := moveSpacesOutOfTags(text); moved != expected {
+			t.Errorf("%q: got %q, expected %q", text, moved, expected)
+		}
+	}
+}
+
+func TestRenderRow(t *testing.T) {
+	row := []Cell{{}, {Character: 'A'}, {}, {Character: 'b', Italic: true}, {Character: 'c', Italic: true}, {Character: ' ', Italic: true}, {Character: 'd', Color: 4, Underline: true}, {}}
+	if line := RenderRow(row); line != `A <i>bc</i> <u><font color="red">d</font></u>` {
+		t.Errorf("got %q", line)
+	}
+
+	if line := RenderRow([]Cell{{}, {Character: ' ', Italic: true}, {}}); line != "" {
+		t.Errorf("got %q for a row of formatted spaces, expected an empty row", line)
+	}
+}
@@ -0,0 +1,225 @@
+package cea608
+
+import (
+	"math/bits"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const midRowItalics = 0x2E
+
+// preambleAddressCodes are the first byte and row bit of the preamble address code of each row
+var preambleAddressCodes = [Rows][2]byte{
+	{0x11, 0x00}, {0x11, 0x20}, {0x12, 0x00}, {0x12, 0x20}, {0x15, 0x00}, {0x15, 0x20}, {0x16, 0x00}, {0x16, 0x20},
+	{0x17, 0x00}, {0x17, 0x20}, {0x10, 0x00}, {0x13, 0x00}, {0x13, 0x20}, {0x14, 0x00}, {0x14, 0x20},
+}
+
+var (
+	fontColorRegex = regexp.MustCompile(`(?i)^<font\s[^>]*color\s*=\s*["']?([^"' >]+)`)
+	tagRegex       = regexp.MustCompile(`^<[^<>]*>`)
+)
+
+type attributes struct {
+	color     int
+	italic    bool
+	underline bool
+}
+
+type styledCharacter struct {
+	attributes
+	character rune
+}
+
+// pairEncoder collects byte pairs, packing characters of the basic set two to a pair
+type pairEncoder struct {
+	channel byte
+	pairs   [][2]byte
+	pending []byte
+}
+
+// control adds a control code twice, as control codes are sent
+func (p *pairEncoder) control(first byte, second byte) {
+	p.flush()
+
+	pair := [2]byte{OddParity(first | p.channel), OddParity(second)}
+	p.pairs = append(p.pairs, pair, pair)
+}
+
+func (p *pairEncoder) flush() {
+	if len(p.pending)%2 != 0 {
+		p.pending = append(p.pending, 0)
+	}
+
+	for i := 0; i < len(p.pending); i += 2 {
+		p.pairs = append(p.pairs, [2]byte{OddParity(p.pending[i]), OddParity(p.pending[i+1])})
+	}
+	p.pending = p.pending[:0]
+}
+
+// midRow adds the mid-row codes changing to the attributes, italics in colour take a colour code and an italics code
+func (p *pairEncoder) midRow(attributes attributes) {
+	underline := byte(0)
+	if attributes.underline {
+		underline = 1
+	}
+
+	if !attributes.italic || attributes.color != 0 {
+		p.control(0x11, 0x20|byte(attributes.color)<<1|underline)
+	}
+	if attributes.italic {
+		p.control(0x11, midRowItalics|underline)
+	}
+}
+
+func (p *pairEncoder) write(character rune) {
+	basic, pair, ok := encodeCharacter(character)
+	if !ok {
+		return
+	}
+
+	if basic != 0 {
+		p.pending = append(p.pending, basic)
+	}
+	if pair != [2]byte{} {
+		p.control(pair[0], pair[1])
+	}
+}
+
+// EncodeEraseDisplayedMemory returns the byte pairs, with parity, that remove the caption on screen
+func EncodeEraseDisplayedMemory(channel int) [][2]byte {
+	encoder := pairEncoder{channel: channelBit(channel)}
+	encoder.control(0x14, controlEraseDisplayedMemory)
+
+	return encoder.pairs
+}
+
+// EncodePopOn returns the byte pairs, with parity, that load a pop-on caption with its first line on a row from 1
+// to 15 and show it with an end of caption code. Lines are centred and cut off at 32 columns, formatting tags
+// become mid-row codes
+func EncodePopOn(text string, row int, channel int) [][2]byte {
+	encoder := pairEncoder{channel: channelBit(channel)}
+	encoder.control(0x14, controlEraseNonDisplayedMemory)
+	encoder.control(0x14, controlResumeCaptionLoading)
+
+	lines := styledLines(text)
+	row = max(1, min(row, Rows-len(lines)+1))
+
+	for i, line := range lines {
+		if i >= Rows {
+			break
+		}
+
+		//Mid-row codes take a column, so they replace a space next to them where there is one
+		type token struct {
+			attributes *attributes
+			character  rune
+		}
+		tokens := []token{}
+		current := attributes{}
+		for j, character := range line {
+			if character.attributes != current {
+				if len(tokens) > 0 && tokens[len(tokens)-1].character == ' ' {
+					tokens = tokens[:len(tokens)-1]
+				}
+				newAttributes := character.attributes
+				tokens = append(tokens, token{attributes: &newAttributes})
+				if character.italic && character.color != 0 {
+					tokens = append(tokens, token{})
+				}
+				current = character.attributes
+
+				if character.character == ' ' && j+1 < len(line) {
+					continue
+				}
+			}
+
+			tokens = append(tokens, token{character: character.character})
+		}
+		tokens = tokens[:min(len(tokens), Columns)]
+
+		column := (Columns - len(tokens)) / 2
+		code := preambleAddressCodes[row+i-1]
+		encoder.control(code[0], 0x40|code[1]|byte(preambleAddressFirstIndentOffset+column/4)<<1)
+		if column%4 != 0 {
+			encoder.control(0x17, 0x20+byte(column%4))
+		}
+
+		for _, token := range tokens {
+			switch {
+			case token.attributes != nil:
+				encoder.midRow(*token.attributes)
+			case token.character != 0:
+				encoder.write(token.character)
+			}
+		}
+	}
+
+	encoder.control(0x14, controlEndOfCaption)
+
+	return encoder.pairs
+}
+
+// OddParity sets the parity bit of a byte so that it has an odd number of bits set, as CEA-608 bytes are sent
+func OddParity(b byte) byte {
+	b &= 0x7F
+	if bits.OnesCount8(b)%2 == 0 {
+		return b | 0x80
+	}
+
+	return b
+}
+
+func channelBit(channel int) byte {
+	if channel == 2 {
+		return 0x08
+	}
+
+	return 0
+}
+
+// styledLines splits text with formatting tags into lines of characters with their attributes, formatting may
+// continue on the next line
+func styledLines(text string) [][]styledCharacter {
+	lines := [][]styledCharacter{{}}
+	current := attributes{}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for len(text) > 0 {
+		if tag := tagRegex.FindString(text); tag != "" {
+			text = text[len(tag):]
+
+			switch lowerTag := strings.ToLower(tag); {
+			case lowerTag == "<i>":
+				current.italic = true
+			case lowerTag == "</i>":
+				current.italic = false
+			case lowerTag == "<u>":
+				current.underline = true
+			case lowerTag == "</u>":
+				current.underline = false
+			case lowerTag == "</font>":
+				current.color = 0
+			default:
+				if match := fontColorRegex.FindStringSubmatch(tag); match != nil {
+					current.color = ColorIndex(match[1])
+				}
+			}
+
+			continue
+		}
+
+		character, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+
+		if character == '\n' {
+			lines = append(lines, []styledCharacter{})
+
+			continue
+		}
+
+		lines[len(lines)-1] = append(lines[len(lines)-1], styledCharacter{attributes: current, character: character})
+	}
+
+	return lines
+}
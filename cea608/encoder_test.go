@@ -0,0 +1,72 @@
+package cea608
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOddParity(t *testing.T) {
+	for b, expected := range map[byte]byte{0x00: 0x80, 0x01: 0x01, 0x14: 0x94, 0x2F: 0x2F, 0x80: 0x80, 0xFF: 0x7F} {
+		if parity := OddParity(b); parity != expected {
+			t.Errorf("0x%02X: got 0x%02X, expected 0x%02X", b, parity, expected)
+		}
+	}
+}
+
+func TestEncodePopOn(t *testing.T) {
+	//Erase non-displayed memory, resume caption loading, the preamble address of row 15 indented by 12 columns, a
+	//tab offset of 3 columns to centre the text, the characters and end of caption, control codes twice
+	expected := [][2]byte{
+		{0x94, 0xAE}, {0x94, 0xAE}, {0x94, 0x20}, {0x94, 0x20}, {0x94, 0x76}, {0x94, 0x76}, {0x97, 0x23}, {0x97, 0x23},
+		{0xC8, 0xE9}, {0x94, 0x2F}, {0x94, 0x2F},
+	}
+
+	if pairs := EncodePopOn("Hi", 15, 1); !slices.Equal(pairs, expected) {
+		t.Errorf("got % X, expected % X", pairs, expected)
+	}
+
+	//Channel 2 sets the channel bit of the first byte of control codes
+	if pairs := EncodeEraseDisplayedMemory(2); !slices.Equal(pairs, [][2]byte{{0x1C, 0x2C}, {0x1C, 0x2C}}) {
+		t.Errorf("got % X for channel 2", pairs)
+	}
+}
+
+func TestEncodePopOnRoundTrip(t *testing.T) {
+	tests := []struct {
+		text     string
+		row      int
+		expected string
+	}{
+		{text: "<i>Hello</i>\nworld", row: 14, expected: "<i>Hello</i>\nworld"},
+		{text: "Café ♪ ¡Olé! Ü", row: 15, expected: "Café ♪ ¡Olé! Ü"},
+		{text: `<font color="#FF0000">Red</font> and <u>under</u>`, row: 15, expected: `<font color="red">Red</font> and <u>under</u>`},
+		{text: "Ŵelsh", row: 15, expected: "Welsh"},
+		{text: "This line is far too long to fit in 32 columns", row: 15, expected: "This line is far too long to fit"},
+		{text: "One\nTwo\nThree", row: 15, expected: "One\nTwo\nThree"},
+	}
+
+	for _, test := range tests {
+		decoder := NewDecoder(1)
+		frame := decodePairs(decoder, EncodePopOn(test.text, test.row, 1), 0)
+
+		captions := decoder.Flush(float64(frame) * frameMilliseconds)
+		if len(captions) != 1 || captions[0].Text != test.expected {
+			t.Errorf("%q: got %v, expected %q", test.text, captions, test.expected)
+		}
+	}
+
+	//Rows are moved up for the lines to fit on screen
+	decoder := NewDecoder(1)
+	frame := decodePairs(decoder, EncodePopOn("One\nTwo\nThree", 15, 1), 0)
+	if captions := decoder.Flush(float64(frame) * frameMilliseconds); len(captions) != 1 || captions[0].Row != 13 {
+		t.Errorf("got %v, expected the caption on row 13", captions)
+	}
+}
+
+func TestColorIndex(t *testing.T) {
+	for color, expected := range map[string]int{"red": 4, "Lime": 1, "#00FFFF": 3, "ff00ff": 6, "black": 0, "#FFFF7F": 5, "bogus": 0} {
+		if index := ColorIndex(color); index != expected {
+			t.Errorf("%s: got %d, expected %d", color, index, expected)
+		}
+	}
+}
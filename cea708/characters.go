@@ -0,0 +1,18 @@
+package cea708
+
+// g2Characters are the characters of the G2 set, sent after the EXT1 code, that decoders show. 0x20 and 0x21 are
+// transparent spaces
+var g2Characters = map[byte]rune{
+	0x20: ' ', 0x21: ' ', 0x25: '…', 0x2A: 'Š', 0x2C: 'Œ', 0x30: '█', 0x31: '‘', 0x32: '’', 0x33: '“', 0x34: '”',
+	0x35: '•', 0x39: '™', 0x3A: 'š', 0x3C: 'œ', 0x3D: '℠', 0x3F: 'Ÿ', 0x76: '⅛', 0x77: '⅜', 0x78: '⅝', 0x79: '⅞',
+	0x7A: '│', 0x7B: '┐', 0x7C: '└', 0x7D: '─', 0x7E: '┘', 0x7F: '┌',
+}
+
+// g0Character returns the character of a byte of the G0 set, which is ASCII with a music note for 0x7F
+func g0Character(b byte) rune {
+	if b == 0x7F {
+		return '♪'
+	}
+
+	return rune(b)
+}
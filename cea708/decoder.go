@@ -0,0 +1,361 @@
+package cea708
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ristryder/gse/cea608"
+)
+
+// Types of the cc_data triplets that carry DTVCC packets
+const (
+	CcTypePacketData  = 2
+	CcTypePacketStart = 3
+)
+
+// Codes of the C0 set
+const (
+	codeEndOfText      = 0x03
+	codeBackspace      = 0x08
+	codeFormFeed       = 0x0C
+	codeCarriageReturn = 0x0D
+	codeHorizontalCR   = 0x0E
+	codeExtended       = 0x10
+)
+
+// Codes of the C1 set
+const (
+	codeSetCurrentWindow0 = 0x80
+	codeClearWindows      = 0x88
+	codeDisplayWindows    = 0x89
+	codeHideWindows       = 0x8A
+	codeToggleWindows     = 0x8B
+	codeDeleteWindows     = 0x8C
+	codeReset             = 0x8F
+	codeSetPenAttributes  = 0x90
+	codeSetPenColor       = 0x91
+	codeSetPenLocation    = 0x92
+	codeDefineWindow0     = 0x98
+)
+
+// c1ParameterCounts are the number of parameter bytes following each code of the C1 set
+var c1ParameterCounts = [32]int{
+	0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 0, 0, 2, 3, 2, 0, 0, 0, 0, 4, 6, 6, 6, 6, 6, 6, 6, 6,
+}
+
+// Caption is a caption as it was shown on screen, with its start and end in milliseconds
+type Caption = cea608.Caption
+
+// Decoder assembles the DTVCC packets of cc_data triplets and decodes the service blocks of one caption service
+// into captions. A caption starts when what the visible windows show changes and ends at the next change
+type Decoder struct {
+	//Service is the caption service, 1 is the primary caption service
+	Service int
+
+	captions     []Caption
+	changedSince float64
+	current      int
+	hasChanges   bool
+	now          float64
+	packet       []byte
+	packetSize   int
+	shownRow     int
+	shownSince   float64
+	shownText    string
+	windows      [8]window
+}
+
+func NewDecoder(service int) *Decoder {
+	return &Decoder{Service: service}
+}
+
+// Captions returns the captions completed so far
+func (d *Decoder) Captions() []Caption {
+	return d.captions
+}
+
+// Decode decodes the two bytes of a valid cc_data triplet of type 2 or 3 received at a time in milliseconds
+func (d *Decoder) Decode(ccType byte, first byte, second byte, milliseconds float64) {
+	d.now = milliseconds
+
+	switch ccType {
+	case CcTypePacketStart:
+		//The packet size code counts pairs of bytes, including the header, and 0 means 128 bytes
+		d.packetSize = int(first&0x3F) * 2
+		if d.packetSize == 0 {
+			d.packetSize = 128
+		}
+		d.packet = []byte{first, second}
+	case CcTypePacketData:
+		if d.packet == nil {
+			return
+		}
+		d.packet = append(d.packet, first, second)
+	default:
+		return
+	}
+
+	if len(d.packet) >= d.packetSize {
+		d.decodePacket(d.packet[1:d.packetSize])
+		d.packet = nil
+	}
+}
+
+func (d *Decoder) decodeC0(code byte) {
+	w := d.currentWindow()
+
+	switch code {
+	case codeEndOfText:
+		d.update(d.now)
+	case codeBackspace:
+		if w != nil {
+			d.touch(w)
+			w.backspace()
+		}
+	case codeFormFeed:
+		if w != nil {
+			d.touch(w)
+			w.clear()
+		}
+	case codeCarriageReturn:
+		if w != nil {
+			d.update(d.now)
+			w.carriageReturn()
+		}
+	case codeHorizontalCR:
+		if w != nil {
+			d.touch(w)
+			w.rows[w.row] = make([]cea608.Cell, MaxColumns)
+			w.column = 0
+		}
+	}
+}
+
+func (d *Decoder) decodeC1(code byte, parameters []byte) {
+	w := d.currentWindow()
+
+	switch {
+	case code < codeClearWindows:
+		d.current = int(code - codeSetCurrentWindow0)
+	case code >= codeClearWindows && code <= codeDeleteWindows:
+		//What is on screen changes at once, so the caption shown until now ends here
+		d.update(d.now)
+		for i := range d.windows {
+			if parameters[0]&(1<<i) == 0 || !d.windows[i].isDefined {
+				continue
+			}
+
+			switch code {
+			case codeClearWindows:
+				d.windows[i].clear()
+			case codeDisplayWindows:
+				d.windows[i].isVisible = true
+			case codeHideWindows:
+				d.windows[i].isVisible = false
+			case codeToggleWindows:
+				d.windows[i].isVisible = !d.windows[i].isVisible
+			case codeDeleteWindows:
+				d.windows[i] = window{}
+			}
+		}
+		d.update(d.now)
+	case code == codeReset:
+		d.update(d.now)
+		d.windows = [8]window{}
+		d.update(d.now)
+	case code == codeSetPenAttributes && w != nil:
+		w.pen.italic = parameters[1]&0x80 != 0
+		w.pen.underline = parameters[1]&0x40 != 0
+	case code == codeSetPenColor && w != nil:
+		//Each of the red, green and blue components has four levels
+		red, green, blue := parameters[0]>>4&0x03, parameters[0]>>2&0x03, parameters[0]&0x03
+		w.pen.color = cea608.ColorIndex(fmt.Sprintf("%02x%02x%02x", red*0x55, green*0x55, blue*0x55))
+	case code == codeSetPenLocation && w != nil:
+		w.row = min(int(parameters[0]&0x0F), len(w.rows)-1)
+		w.column = min(int(parameters[1]&0x3F), MaxColumns-1)
+	case code >= codeDefineWindow0:
+		d.current = int(code - codeDefineWindow0)
+
+		d.update(d.now)
+		d.windows[d.current].define(parameters)
+		d.update(d.now)
+	}
+}
+
+// decodeExtended decodes the code following the EXT1 code and returns the number of bytes it takes with its
+// parameters. Only characters of the G2 set are shown, the C2 and C3 sets hold no codes decoders act on yet
+func (d *Decoder) decodeExtended(block []byte) int {
+	code := block[0]
+
+	switch {
+	case code < 0x20:
+		return 1 + int(code>>3)
+	case code < 0x80:
+		if character, exists := g2Characters[code]; exists {
+			d.write(character)
+		}
+
+		return 1
+	case code < 0x90:
+		return 5 + int(code-0x80)>>3
+	case code < 0xA0:
+		//Variable length codes give their length after the code
+		if len(block) < 2 {
+			return len(block)
+		}
+
+		return 2 + int(block[1]&0x3F)
+	}
+
+	//The G3 set only holds the closed captions logo
+	return 1
+}
+
+// decodePacket decodes the service blocks of the service in a DTVCC packet, without its header
+func (d *Decoder) decodePacket(packet []byte) {
+	for i := 0; i < len(packet); {
+		service, blockSize := int(packet[i]>>5), int(packet[i]&0x1F)
+		i++
+
+		//A null block header ends the service blocks, service numbers above 6 take an extended header
+		if service == 0 {
+			return
+		}
+		if service == 7 && blockSize != 0 {
+			if i >= len(packet) {
+				return
+			}
+			service = int(packet[i] & 0x3F)
+			i++
+		}
+
+		end := min(i+blockSize, len(packet))
+		if service == d.Service {
+			d.decodeServiceBlock(packet[i:end])
+		}
+		i = end
+	}
+}
+
+func (d *Decoder) decodeServiceBlock(block []byte) {
+	for i := 0; i < len(block); {
+		code := block[i]
+		i++
+
+		switch {
+		case code == codeExtended:
+			if i < len(block) {
+				i += d.decodeExtended(block[i:])
+			}
+		case code < 0x20:
+			//Codes from 0x11 to 0x17 take one parameter byte and codes from 0x18 two
+			parameterCount := 0
+			if code > codeExtended {
+				parameterCount = 1 + int(code>>3&0x01)
+			}
+			if i+parameterCount > len(block) {
+				return
+			}
+			d.decodeC0(code)
+			i += parameterCount
+		case code < 0x80:
+			d.write(g0Character(code))
+		case code < 0xA0:
+			parameterCount := c1ParameterCounts[code-0x80]
+			if i+parameterCount > len(block) {
+				return
+			}
+			d.decodeC1(code, block[i:i+parameterCount])
+			i += parameterCount
+		default:
+			//The G1 set is Latin-1
+			d.write(rune(code))
+		}
+	}
+}
+
+// Flush ends the caption on screen at a time in milliseconds, and returns all captions
+func (d *Decoder) Flush(milliseconds float64) []Caption {
+	d.update(milliseconds)
+	if d.shownText != "" {
+		d.captions = append(d.captions, Caption{End: milliseconds, Row: d.shownRow, Start: d.shownSince, Text: d.shownText})
+		d.shownText = ""
+	}
+
+	return d.captions
+}
+
+// currentWindow returns the window text and pen commands apply to, or nil if it is not defined
+func (d *Decoder) currentWindow() *window {
+	if !d.windows[d.current].isDefined {
+		return nil
+	}
+
+	return &d.windows[d.current]
+}
+
+// render returns the text of the visible windows from the top of the screen down, and the top row
+func (d *Decoder) render() (string, int) {
+	visible := []*window{}
+	for i := range d.windows {
+		if d.windows[i].isDefined && d.windows[i].isVisible {
+			visible = append(visible, &d.windows[i])
+		}
+	}
+	slices.SortStableFunc(visible, func(a *window, b *window) int {
+		return a.topRow() - b.topRow()
+	})
+
+	texts := []string{}
+	topRow := 0
+	for _, w := range visible {
+		if text := w.render(); text != "" {
+			if len(texts) == 0 {
+				topRow = w.topRow()
+			}
+			texts = append(texts, text)
+		}
+	}
+
+	return strings.Join(texts, "\n"), topRow
+}
+
+// touch records when what is on screen started to change, text written to hidden windows is not on screen until
+// they are displayed
+func (d *Decoder) touch(w *window) {
+	if w.isVisible && !d.hasChanges {
+		d.changedSince, d.hasChanges = d.now, true
+	}
+}
+
+// update ends the caption on screen and starts the next one when what is on screen has changed
+func (d *Decoder) update(milliseconds float64) {
+	start := milliseconds
+	if d.hasChanges {
+		start = d.changedSince
+	}
+	d.hasChanges = false
+
+	text, row := d.render()
+	if text == d.shownText {
+		return
+	}
+
+	//Text that changes again in the same packet, such as a row before a carriage return, was never on screen
+	if d.shownText != "" && start > d.shownSince {
+		d.captions = append(d.captions, Caption{End: start, Row: d.shownRow, Start: d.shownSince, Text: d.shownText})
+	}
+
+	d.shownRow, d.shownSince, d.shownText = row, start, text
+}
+
+func (d *Decoder) write(character rune) {
+	w := d.currentWindow()
+	if w == nil {
+		return
+	}
+
+	d.touch(w)
+	w.write(character)
+}
@@ -0,0 +1,124 @@
+package cea708
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ristryder/gse/cea608"
+)
+
+const frameMilliseconds = 1000.0 / 30
+
+// defineWindow returns the DefineWindow code of a visible window of two rows at the bottom centre of the screen
+var defineWindow = []byte{codeDefineWindow0, 0x20, 70, 0, 0x71, 31, 0}
+
+// decodeBlocks sends each service block in a DTVCC packet of its own, one cc_data triplet per frame from a frame
+// number, and returns the frame after the last triplet
+func decodeBlocks(decoder *Decoder, service int, frame int, blocks ...[]byte) int {
+	for sequence, block := range blocks {
+		packet := append([]byte{byte(service<<5 | len(block))}, block...)
+		if len(packet)%2 == 0 {
+			packet = append(packet, 0)
+		}
+
+		//The header gives the sequence number and the packet size in pairs of bytes
+		packet = append([]byte{byte(sequence%4)<<6 | byte((len(packet)+1)/2)}, packet...)
+		for i := 0; i < len(packet); i += 2 {
+			ccType := byte(CcTypePacketData)
+			if i == 0 {
+				ccType = CcTypePacketStart
+			}
+
+			decoder.Decode(ccType, packet[i], packet[i+1], float64(frame)*frameMilliseconds)
+			frame++
+		}
+	}
+
+	return frame
+}
+
+func checkCaptions(t *testing.T, name string, captions []Caption, expected []Caption) {
+	t.Helper()
+
+	if !slices.EqualFunc(captions, expected, func(a, b Caption) bool {
+		return a.Text == b.Text && a.Row == b.Row && int(a.Start+0.5) == int(b.Start+0.5) && int(a.End+0.5) == int(b.End+0.5)
+	}) {
+		t.Errorf("%s: got %v, expected %v", name, captions, expected)
+	}
+}
+
+func TestDecoderWindowText(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	//A row replaced by a carriage return within the packet was never on screen on its own
+	shown := decodeBlocks(decoder, 1, 0, slices.Concat(defineWindow, []byte("Hello"), []byte{codeCarriageReturn}, []byte("world")))
+	deleted := decodeBlocks(decoder, 1, shown+30, []byte{codeDeleteWindows, 0x01})
+
+	checkCaptions(t, "window text", decoder.Flush(float64(deleted)*frameMilliseconds), []Caption{
+		//The packet is decoded when its last byte arrives
+		{End: float64(deleted-1) * frameMilliseconds, Row: 14, Start: float64(shown-1) * frameMilliseconds, Text: "Hello\nworld"},
+	})
+}
+
+func TestDecoderPenAndCharacters(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	frame := decodeBlocks(decoder, 1, 0, slices.Concat(
+		defineWindow,
+		//Italics in red, then the G2 ellipsis, the G0 music note and the G1 é in white
+		[]byte{codeSetPenAttributes, 0x00, 0x80, codeSetPenColor, 0x30, 0x00, 0x00}, []byte("Red"),
+		[]byte{codeSetPenAttributes, 0x00, 0x00, codeSetPenColor, 0x3F, 0x00, 0x00, ' ', codeExtended, 0x25, 0x7F, 0xE9},
+	))
+
+	captions := decoder.Flush(float64(frame) * frameMilliseconds)
+	if len(captions) != 1 || captions[0].Text != `<i><font color="red">Red</font></i> …♪é` {
+		t.Errorf("got %v", captions)
+	}
+}
+
+func TestDecoderHiddenWindow(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	hidden := slices.Clone(defineWindow)
+	hidden[1] = 0x00
+	written := decodeBlocks(decoder, 1, 0, slices.Concat(hidden, []byte("Later")))
+	displayed := decodeBlocks(decoder, 1, written+10, []byte{codeDisplayWindows, 0x01})
+	cleared := decodeBlocks(decoder, 1, displayed+10, []byte{codeClearWindows, 0x01})
+
+	checkCaptions(t, "hidden window", decoder.Flush(float64(cleared)*frameMilliseconds), []Caption{
+		{End: float64(cleared-1) * frameMilliseconds, Row: 14, Start: float64(displayed-1) * frameMilliseconds, Text: "Later"},
+	})
+}
+
+func TestDecoderIgnoresOtherServices(t *testing.T) {
+	decoder := NewDecoder(1)
+
+	frame := decodeBlocks(decoder, 2, 0, slices.Concat(defineWindow, []byte("Service 2")))
+	if captions := decoder.Flush(float64(frame) * frameMilliseconds); len(captions) != 0 {
+		t.Errorf("got %v, expected no captions", captions)
+	}
+
+	//Data without a packet start is ignored
+	decoder = NewDecoder(1)
+	decoder.Decode(CcTypePacketData, 0x21, 'A', 0)
+	if captions := decoder.Flush(frameMilliseconds); len(captions) != 0 {
+		t.Errorf("got %v, expected no captions", captions)
+	}
+}
+
+func TestWindowTopRow(t *testing.T) {
+	tests := []struct {
+		window   window
+		expected int
+	}{
+		{window: window{anchorPoint: 0, anchorVertical: 0, rows: make([][]cea608.Cell, 2)}, expected: 1},
+		{window: window{anchorPoint: 7, anchorVertical: 74, rows: make([][]cea608.Cell, 3)}, expected: 13},
+		{window: window{anchorPoint: 4, anchorVertical: 50, isRelative: true, rows: make([][]cea608.Cell, 4)}, expected: 6},
+	}
+
+	for _, test := range tests {
+		if row := test.window.topRow(); row != test.expected {
+			t.Errorf("anchor point %d at %d: got row %d, expected %d", test.window.anchorPoint, test.window.anchorVertical, row, test.expected)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package cea708
+
+import (
+	"strings"
+
+	"github.com/ristryder/gse/cea608"
+)
+
+const (
+	//MaxColumns is the widest window a service may define, on 16:9 screens
+	MaxColumns = 42
+	//Rows is the number of caption rows of the screen
+	Rows = 15
+)
+
+type pen struct {
+	color     int
+	italic    bool
+	underline bool
+}
+
+// window is one of the eight caption windows of a service, its text is kept as rows of cells
+type window struct {
+	anchorPoint    int
+	anchorVertical int
+	column         int
+	isDefined      bool
+	isRelative     bool
+	isVisible      bool
+	pen            pen
+	priority       int
+	row            int
+	rows           [][]cea608.Cell
+}
+
+func (w *window) backspace() {
+	if w.column > 0 {
+		w.column--
+		w.rows[w.row][w.column] = cea608.Cell{}
+	}
+}
+
+// carriageReturn moves the pen to the start of the next row, scrolling the rows up from the last row
+func (w *window) carriageReturn() {
+	w.column = 0
+	if w.row+1 < len(w.rows) {
+		w.row++
+
+		return
+	}
+
+	copy(w.rows, w.rows[1:])
+	w.rows[len(w.rows)-1] = make([]cea608.Cell, MaxColumns)
+}
+
+func (w *window) clear() {
+	for row := range w.rows {
+		w.rows[row] = make([]cea608.Cell, MaxColumns)
+	}
+	w.row, w.column = 0, 0
+}
+
+// define sets the position and size of the window, keeping the text of a window that was already defined
+func (w *window) define(parameters []byte) {
+	rowCount := min(int(parameters[3]&0x0F)+1, Rows)
+
+	if !w.isDefined {
+		*w = window{}
+	}
+	w.anchorPoint = int(parameters[3] >> 4)
+	w.anchorVertical = int(parameters[1] & 0x7F)
+	w.isDefined = true
+	w.isRelative = parameters[1]&0x80 != 0
+	w.isVisible = parameters[0]&0x20 != 0
+	w.priority = int(parameters[0] & 0x07)
+
+	for len(w.rows) < rowCount {
+		w.rows = append(w.rows, make([]cea608.Cell, MaxColumns))
+	}
+	w.rows = w.rows[:rowCount]
+	w.row = min(w.row, rowCount-1)
+}
+
+// render returns the text of the non-empty rows with formatting tags
+func (w *window) render() string {
+	lines := []string{}
+	for _, row := range w.rows {
+		if line := cea608.RenderRow(row); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// topRow returns the screen row, from 1 to 15, of the first row of the window according to its anchor
+func (w *window) topRow() int {
+	row := w.anchorVertical * Rows / 75
+	if w.isRelative {
+		row = w.anchorVertical * Rows / 100
+	}
+
+	//Anchor points 0 to 2 are at the top of the window, 3 to 5 in the middle and 6 to 8 at the bottom
+	switch w.anchorPoint / 3 {
+	case 1:
+		row -= len(w.rows) / 2
+	case 2:
+		row -= len(w.rows) - 1
+	}
+
+	return max(1, min(row+1, Rows))
+}
+
+func (w *window) write(character rune) {
+	if w.column >= MaxColumns {
+		return
+	}
+
+	w.rows[w.row][w.column] = cea608.Cell{Character: character, Color: w.pen.color, Italic: w.pen.italic, Underline: w.pen.underline}
+	w.column++
+}
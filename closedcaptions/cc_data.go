@@ -0,0 +1,58 @@
+package closedcaptions
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Types of cc_data triplets, types 2 and 3 carry the DTVCC packets of CEA-708 services
+const (
+	CcTypeField1      = 0
+	CcTypeField2      = 1
+	CcTypePacketData  = 2
+	CcTypePacketStart = 3
+)
+
+// atscIdentifier is the user identifier of ATSC A/53 user data, followed by the user data type code of cc_data
+var atscIdentifier = []byte{'G', 'A', '9', '4', 0x03}
+
+// Triplet is a valid cc_data construct: the type and the two bytes of a CEA-608 byte pair or of a DTVCC packet
+type Triplet struct {
+	Data [2]byte
+	Type byte
+}
+
+func (t *Triplet) String() string {
+	return fmt.Sprintf("Type: %v , Data: %02x%02x", t.Type, t.Data[0], t.Data[1])
+}
+
+// ParseCcData returns the valid triplets of the cc_data structure of ATSC A/53, which follows the user data type
+// code 0x03. Triplets marked as not valid are padding
+func ParseCcData(data []byte) []Triplet {
+	if len(data) < 2 || data[0]&0x40 == 0 {
+		return nil
+	}
+
+	//The flags are followed by a reserved byte
+	count := int(data[0] & 0x1F)
+	triplets := make([]Triplet, 0, count)
+	for i := 0; i < count && 2+i*3+3 <= len(data); i++ {
+		triplet := data[2+i*3 : 2+i*3+3]
+		if triplet[0]&0x04 == 0 {
+			continue
+		}
+
+		triplets = append(triplets, Triplet{Data: [2]byte{triplet[1], triplet[2]}, Type: triplet[0] & 0x03})
+	}
+
+	return triplets
+}
+
+// parseAtscUserData returns the triplets of user data starting with the ATSC identifier, or nil for other user data
+func parseAtscUserData(userData []byte) []Triplet {
+	if !bytes.HasPrefix(userData, atscIdentifier) {
+		return nil
+	}
+
+	return ParseCcData(userData[len(atscIdentifier):])
+}
@@ -0,0 +1,54 @@
+package closedcaptions
+
+import (
+	"slices"
+	"testing"
+)
+
+// ccData returns a cc_data structure holding triplets, each with the marker bits and the valid flag set
+func ccData(triplets ...Triplet) []byte {
+	data := []byte{0x40 | byte(len(triplets)), 0xFF}
+	for _, triplet := range triplets {
+		data = append(data, 0xFC|triplet.Type, triplet.Data[0], triplet.Data[1])
+	}
+
+	return data
+}
+
+func TestParseCcData(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected []Triplet
+	}{
+		{
+			name:     "valid triplets of each type",
+			data:     ccData(Triplet{Data: [2]byte{0x94, 0x20}, Type: CcTypeField1}, Triplet{Data: [2]byte{0x15, 0x2C}, Type: CcTypeField2}, Triplet{Data: [2]byte{0x02, 0x21}, Type: CcTypePacketStart}, Triplet{Data: [2]byte{'A', 'B'}, Type: CcTypePacketData}),
+			expected: []Triplet{{Data: [2]byte{0x94, 0x20}, Type: CcTypeField1}, {Data: [2]byte{0x15, 0x2C}, Type: CcTypeField2}, {Data: [2]byte{0x02, 0x21}, Type: CcTypePacketStart}, {Data: [2]byte{'A', 'B'}, Type: CcTypePacketData}},
+		},
+		{
+			name:     "padding triplets are not valid",
+			data:     []byte{0x42, 0xFF, 0xF8, 0x80, 0x80, 0xFC, 0xC8, 0xE9},
+			expected: []Triplet{{Data: [2]byte{0xC8, 0xE9}, Type: CcTypeField1}},
+		},
+		{
+			name:     "count beyond the data",
+			data:     []byte{0x43, 0xFF, 0xFC, 0xC8, 0xE9, 0xFC, 0x80},
+			expected: []Triplet{{Data: [2]byte{0xC8, 0xE9}, Type: CcTypeField1}},
+		},
+		{
+			name: "process_cc_data_flag not set",
+			data: []byte{0x01, 0xFF, 0xFC, 0xC8, 0xE9},
+		},
+		{
+			name: "too short",
+			data: []byte{0x41},
+		},
+	}
+
+	for _, test := range tests {
+		if triplets := ParseCcData(test.data); !slices.Equal(triplets, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.name, triplets, test.expected)
+		}
+	}
+}
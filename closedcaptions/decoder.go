@@ -0,0 +1,83 @@
+package closedcaptions
+
+import (
+	"fmt"
+
+	"github.com/ristryder/gse/cea608"
+	"github.com/ristryder/gse/cea708"
+	"github.com/ristryder/gse/common"
+)
+
+// ClosedCaptions are the captions of the CEA-608 data channels of both fields and of the primary CEA-708 service
+type ClosedCaptions struct {
+	Cc1      *common.Subtitle
+	Cc2      *common.Subtitle
+	Cc3      *common.Subtitle
+	Cc4      *common.Subtitle
+	Service1 *common.Subtitle
+}
+
+func (c *ClosedCaptions) String() string {
+	return fmt.Sprintf("Cc1: %v , Cc2: %v , Cc3: %v , Cc4: %v , Service1: %v", len(c.Cc1.Paragraphs), len(c.Cc2.Paragraphs), len(c.Cc3.Paragraphs), len(c.Cc4.Paragraphs), len(c.Service1.Paragraphs))
+}
+
+// Decoder decodes the cc_data triplets of the frames of a video track, in presentation order
+type Decoder struct {
+	field1   [2]*cea608.Decoder
+	field2   [2]*cea608.Decoder
+	service1 *cea708.Decoder
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{
+		field1:   [2]*cea608.Decoder{cea608.NewDecoder(1), cea608.NewDecoder(2)},
+		field2:   [2]*cea608.Decoder{cea608.NewDecoder(1), cea608.NewDecoder(2)},
+		service1: cea708.NewDecoder(1),
+	}
+}
+
+// Decode decodes the triplets of a frame presented at a time in milliseconds
+func (d *Decoder) Decode(triplets []Triplet, milliseconds float64) {
+	for _, triplet := range triplets {
+		switch triplet.Type {
+		case CcTypeField1:
+			for _, decoder := range d.field1 {
+				decoder.Decode(triplet.Data[0], triplet.Data[1], milliseconds)
+			}
+		case CcTypeField2:
+			for _, decoder := range d.field2 {
+				decoder.Decode(triplet.Data[0], triplet.Data[1], milliseconds)
+			}
+		default:
+			d.service1.Decode(triplet.Type, triplet.Data[0], triplet.Data[1], milliseconds)
+		}
+	}
+}
+
+// Flush ends the captions on screen at a time in milliseconds, and returns the captions of each channel
+func (d *Decoder) Flush(milliseconds float64) *ClosedCaptions {
+	return &ClosedCaptions{
+		Cc1:      captionsToSubtitle(d.field1[0].Flush(milliseconds)),
+		Cc2:      captionsToSubtitle(d.field1[1].Flush(milliseconds)),
+		Cc3:      captionsToSubtitle(d.field2[0].Flush(milliseconds)),
+		Cc4:      captionsToSubtitle(d.field2[1].Flush(milliseconds)),
+		Service1: captionsToSubtitle(d.service1.Flush(milliseconds)),
+	}
+}
+
+func captionsToSubtitle(captions []cea608.Caption) *common.Subtitle {
+	subtitle := &common.Subtitle{}
+
+	for _, caption := range captions {
+		paragraph := common.NewParagraph(caption.Text, caption.Start, caption.End)
+		if caption.Row <= cea608.Rows/2 {
+			paragraph.Region = "top"
+		}
+
+		subtitle.Paragraphs = append(subtitle.Paragraphs, *paragraph)
+	}
+
+	subtitle.Renumber(1)
+
+	return subtitle
+}
@@ -0,0 +1,64 @@
+package closedcaptions
+
+import (
+	"testing"
+
+	"github.com/ristryder/gse/cea608"
+	"github.com/ristryder/gse/common"
+)
+
+// decodePopOn decodes a pop-on caption of a data channel as triplets of a type, one byte pair a frame from a
+// frame number, followed by the codes erasing it ten frames later. It returns the frame after the last pair
+func decodePopOn(decoder *Decoder, ccType byte, channel int, text string, row int, frame int) int {
+	pairs := cea608.EncodePopOn(text, row, channel)
+	pairs = append(pairs, [][2]byte{{0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}, {0x80, 0x80}}...)
+	pairs = append(pairs, cea608.EncodeEraseDisplayedMemory(channel)...)
+
+	for _, pair := range pairs {
+		decoder.Decode([]Triplet{{Data: pair, Type: ccType}}, float64(frame*100))
+		frame++
+	}
+
+	return frame
+}
+
+func TestDecoderChannels(t *testing.T) {
+	decoder := NewDecoder()
+
+	frame := decodePopOn(decoder, CcTypeField1, 1, "One", 15, 0)
+	frame = decodePopOn(decoder, CcTypeField1, 2, "Two", 1, frame)
+	frame = decodePopOn(decoder, CcTypeField2, 1, "Three", 15, frame)
+	frame = decodePopOn(decoder, CcTypeField2, 2, "Four", 15, frame)
+
+	closedCaptions := decoder.Flush(float64(frame * 100))
+	for _, test := range []struct {
+		name     string
+		texts    []string
+		expected string
+	}{
+		{name: "CC1", texts: paragraphTexts(closedCaptions.Cc1.Paragraphs), expected: "One"},
+		{name: "CC2", texts: paragraphTexts(closedCaptions.Cc2.Paragraphs), expected: "Two"},
+		{name: "CC3", texts: paragraphTexts(closedCaptions.Cc3.Paragraphs), expected: "Three"},
+		{name: "CC4", texts: paragraphTexts(closedCaptions.Cc4.Paragraphs), expected: "Four"},
+	} {
+		if len(test.texts) != 1 || test.texts[0] != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.name, test.texts, test.expected)
+		}
+	}
+
+	if len(closedCaptions.Cc2.Paragraphs) == 1 && closedCaptions.Cc2.Paragraphs[0].Region != "top" {
+		t.Errorf("got region %q for a caption on row 1, expected top", closedCaptions.Cc2.Paragraphs[0].Region)
+	}
+	if len(closedCaptions.Service1.Paragraphs) != 0 {
+		t.Errorf("got %v for service 1, expected no captions", closedCaptions.Service1.Paragraphs)
+	}
+}
+
+func paragraphTexts(paragraphs []common.Paragraph) []string {
+	texts := []string{}
+	for _, paragraph := range paragraphs {
+		texts = append(texts, paragraph.Text)
+	}
+
+	return texts
+}
@@ -0,0 +1,99 @@
+package closedcaptions
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ristryder/gse/containers/matroska"
+)
+
+const (
+	codecIdAvc   = "V_MPEG4/ISO/AVC"
+	codecIdHevc  = "V_MPEGH/ISO/HEVC"
+	codecIdMpeg1 = "V_MPEG1"
+	codecIdMpeg2 = "V_MPEG2"
+)
+
+type frameTriplets struct {
+	start    float64
+	triplets []Triplet
+}
+
+// IsSupportedTrack reports whether the closed captions of a video track can be read, which depends on its codec
+func IsSupportedTrack(track matroska.MatroskaTrackInfo) bool {
+	return track.IsVideo && slices.Contains([]string{codecIdAvc, codecIdHevc, codecIdMpeg1, codecIdMpeg2}, track.CodecId)
+}
+
+// ReadMatroska reads the closed captions embedded in the frames of a video track of a Matroska file, as H.264 or
+// HEVC SEI messages or MPEG-2 user data. A track number of zero selects the first video track that can hold them
+func ReadMatroska(matroskaFile *matroska.MatroskaFile, trackNumber uint64, progressCallback func(int64, int64)) (*ClosedCaptions, error) {
+	tracks, tracksErr := matroskaFile.Tracks(false)
+	if tracksErr != nil {
+		return nil, errors.Wrap(tracksErr, "failed to read tracks before reading closed captions")
+	}
+
+	trackIndex := slices.IndexFunc(tracks, func(track matroska.MatroskaTrackInfo) bool {
+		return (trackNumber == 0 || uint64(track.TrackNumber) == trackNumber) && IsSupportedTrack(track)
+	})
+	if trackIndex < 0 {
+		if trackNumber == 0 {
+			return nil, errors.New("no video track can hold closed captions")
+		}
+
+		return nil, errors.Newf("track %d is not a video track that can hold closed captions", trackNumber)
+	}
+	track := tracks[trackIndex]
+
+	//The decoder configuration gives the size of the NAL unit lengths
+	nalLengthSize := 4
+	switch {
+	case track.CodecId == codecIdAvc && len(track.CodecPrivate) > 4:
+		nalLengthSize = int(track.CodecPrivate[4]&0x03) + 1
+	case track.CodecId == codecIdHevc && len(track.CodecPrivate) > 21:
+		nalLengthSize = int(track.CodecPrivate[21]&0x03) + 1
+	}
+
+	frames := []frameTriplets{}
+	var frameErr error
+	framesErr := matroskaFile.Frames([]uint64{uint64(track.TrackNumber)}, func(_ uint64, frame matroska.MatroskaSubtitle) {
+		data, dataErr := frame.UncompressedData(track)
+		if dataErr != nil {
+			frameErr = dataErr
+
+			return
+		}
+
+		var triplets []Triplet
+		switch track.CodecId {
+		case codecIdAvc:
+			triplets = TripletsFromAvc(data, nalLengthSize)
+		case codecIdHevc:
+			triplets = TripletsFromHevc(data, nalLengthSize)
+		default:
+			triplets = TripletsFromMpeg2(data)
+		}
+
+		frames = append(frames, frameTriplets{start: float64(frame.Start), triplets: triplets})
+	}, progressCallback)
+	if framesErr != nil {
+		return nil, errors.Wrap(framesErr, "failed to read video frames")
+	}
+	if frameErr != nil {
+		return nil, errors.Wrap(frameErr, "failed to decode video frame")
+	}
+
+	//Frames are stored in decoding order, captions follow the presentation order
+	slices.SortStableFunc(frames, func(a frameTriplets, b frameTriplets) int {
+		return cmp.Compare(a.start, b.start)
+	})
+
+	decoder := NewDecoder()
+	end := 0.0
+	for _, frame := range frames {
+		decoder.Decode(frame.triplets, frame.start)
+		end = frame.start
+	}
+
+	return decoder.Flush(end + float64(track.DefaultDuration)/1000000.0), nil
+}
@@ -0,0 +1,69 @@
+package closedcaptions
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/ristryder/gse/cea608"
+	"github.com/ristryder/gse/containers/matroska"
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+// avcFile returns a file with an H.264 track of 40 ms frames stored in decoding order, where each pair of frames
+// after the first is swapped as with B-frames. Each frame holds one CEA-608 byte pair of a pop-on caption
+func avcFile(pairs [][2]byte) []byte {
+	blocks := [][]byte{mkvtest.UInt(uint32(matroska.ElementTimecode), 0)}
+	for i := range pairs {
+		presented := i
+		if i > 0 && i%2 == 1 && i+1 < len(pairs) {
+			presented = i + 1
+		} else if i > 0 && i%2 == 0 {
+			presented = i - 1
+		}
+
+		sei := slices.Concat([]byte{avcNalUnitTypeSei}, addEmulationPrevention(slices.Concat(atscSeiPayload(Triplet{Data: pairs[presented], Type: CcTypeField1}), []byte{0x80})))
+		blocks = append(blocks, mkvtest.Element(uint32(matroska.ElementSimpleBlock), mkvtest.Block(1, int16(presented*40), 0x80, lengthPrefixed(sei))))
+	}
+
+	return mkvtest.File(
+		mkvtest.Element(uint32(matroska.ElementInfo), mkvtest.UInt(uint32(matroska.ElementTimecodeScale), 1000000)),
+		mkvtest.Element(uint32(matroska.ElementTracks), mkvtest.Element(uint32(matroska.ElementTrackEntry),
+			mkvtest.UInt(uint32(matroska.ElementTrackNumber), 1),
+			mkvtest.UInt(uint32(matroska.ElementTrackType), mkvtest.TrackTypeVideo),
+			mkvtest.String(uint32(matroska.ElementCodecId), codecIdAvc),
+			//The AVC decoder configuration gives NAL unit lengths of four bytes
+			mkvtest.Element(uint32(matroska.ElementCodecPrivate), []byte{0x01, 0x64, 0x00, 0x1F, 0xFF, 0xE0}),
+			mkvtest.UInt(uint32(matroska.ElementDefaultDuration), 40000000),
+		)),
+		mkvtest.Element(uint32(matroska.ElementCluster), blocks...),
+	)
+}
+
+func TestReadMatroska(t *testing.T) {
+	pairs := cea608.EncodePopOn("Hi", 15, 1)
+	shown := len(pairs) - 2
+	pairs = append(pairs, [2]byte{0x80, 0x80}, [2]byte{0x80, 0x80})
+	erased := len(pairs)
+	pairs = append(pairs, cea608.EncodeEraseDisplayedMemory(1)...)
+
+	data := avcFile(pairs)
+	matroskaFile, matroskaFileErr := matroska.NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if matroskaFileErr != nil {
+		t.Fatal(matroskaFileErr)
+	}
+
+	closedCaptions, closedCaptionsErr := ReadMatroska(matroskaFile, 0, nil)
+	if closedCaptionsErr != nil {
+		t.Fatal(closedCaptionsErr)
+	}
+
+	paragraphs := closedCaptions.Cc1.Paragraphs
+	if len(paragraphs) != 1 || paragraphs[0].Text != "Hi" || paragraphs[0].StartTime.TotalMilliseconds != float64(shown*40) || paragraphs[0].EndTime.TotalMilliseconds != float64(erased*40) {
+		t.Errorf("got %v, expected Hi from %d to %d", paragraphs, shown*40, erased*40)
+	}
+
+	if _, closedCaptionsErr = ReadMatroska(matroskaFile, 2, nil); closedCaptionsErr == nil {
+		t.Errorf("expected an error for a track that does not exist")
+	}
+}
@@ -0,0 +1,141 @@
+package closedcaptions
+
+import "bytes"
+
+const (
+	avcNalUnitTypeSei        = 6
+	hevcNalUnitTypePrefixSei = 39
+	mpeg2UserDataStartCode   = 0xB2
+	seiPayloadTypeT35        = 4
+	t35CountryCodeUsa        = 0xB5
+	t35ProviderCodeAtsc      = 0x0031
+)
+
+// TripletsFromAvc returns the triplets of the SEI messages of an H.264 access unit with length prefixed NAL units,
+// as stored in Matroska and MP4. The length size is given by the decoder configuration
+func TripletsFromAvc(accessUnit []byte, nalLengthSize int) []Triplet {
+	triplets := []Triplet{}
+	for _, nalUnit := range splitNalUnits(accessUnit, nalLengthSize) {
+		if len(nalUnit) > 1 && nalUnit[0]&0x1F == avcNalUnitTypeSei {
+			triplets = append(triplets, parseSei(removeEmulationPrevention(nalUnit[1:]))...)
+		}
+	}
+
+	return triplets
+}
+
+// TripletsFromHevc returns the triplets of the prefix SEI messages of an HEVC access unit with length prefixed NAL
+// units, which have a two byte header
+func TripletsFromHevc(accessUnit []byte, nalLengthSize int) []Triplet {
+	triplets := []Triplet{}
+	for _, nalUnit := range splitNalUnits(accessUnit, nalLengthSize) {
+		if len(nalUnit) > 2 && nalUnit[0]>>1&0x3F == hevcNalUnitTypePrefixSei {
+			triplets = append(triplets, parseSei(removeEmulationPrevention(nalUnit[2:]))...)
+		}
+	}
+
+	return triplets
+}
+
+// TripletsFromMpeg2 returns the triplets of the user data of an MPEG-2 picture
+func TripletsFromMpeg2(picture []byte) []Triplet {
+	triplets := []Triplet{}
+	startCode := []byte{0x00, 0x00, 0x01, mpeg2UserDataStartCode}
+
+	for {
+		index := bytes.Index(picture, startCode)
+		if index < 0 {
+			return triplets
+		}
+		picture = picture[index+len(startCode):]
+
+		//User data runs until the next start code
+		userData := picture
+		if end := bytes.Index(picture, []byte{0x00, 0x00, 0x01}); end >= 0 {
+			userData = picture[:end]
+		}
+		triplets = append(triplets, parseAtscUserData(userData)...)
+	}
+}
+
+// parseSei returns the triplets of the ITU-T T.35 messages of ATSC in the payload of a SEI NAL unit
+func parseSei(payload []byte) []Triplet {
+	triplets := []Triplet{}
+
+	//Payload type and size are coded as a run of 0xFF bytes added to the byte that ends it
+	readValue := func() (int, bool) {
+		value := 0
+		for len(payload) > 0 {
+			b := payload[0]
+			payload = payload[1:]
+			value += int(b)
+			if b != 0xFF {
+				return value, true
+			}
+		}
+
+		return 0, false
+	}
+
+	//The RBSP trailing bits end the messages
+	for len(payload) > 1 || (len(payload) == 1 && payload[0] != 0x80) {
+		payloadType, isTypeRead := readValue()
+		payloadSize, isSizeRead := readValue()
+		if !isTypeRead || !isSizeRead || payloadSize > len(payload) {
+			break
+		}
+
+		message := payload[:payloadSize]
+		payload = payload[payloadSize:]
+
+		if payloadType == seiPayloadTypeT35 && len(message) > 3 && message[0] == t35CountryCodeUsa &&
+			int(message[1])<<8|int(message[2]) == t35ProviderCodeAtsc {
+			triplets = append(triplets, parseAtscUserData(message[3:])...)
+		}
+	}
+
+	return triplets
+}
+
+// removeEmulationPrevention removes the bytes inserted after two zero bytes to keep start codes out of NAL units
+func removeEmulationPrevention(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	zeros := 0
+
+	for _, b := range data {
+		if zeros >= 2 && b == 0x03 {
+			zeros = 0
+
+			continue
+		}
+
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		result = append(result, b)
+	}
+
+	return result
+}
+
+func splitNalUnits(accessUnit []byte, nalLengthSize int) [][]byte {
+	nalUnits := [][]byte{}
+
+	for len(accessUnit) >= nalLengthSize {
+		length := 0
+		for _, b := range accessUnit[:nalLengthSize] {
+			length = length<<8 | int(b)
+		}
+		accessUnit = accessUnit[nalLengthSize:]
+
+		if length > len(accessUnit) {
+			break
+		}
+		nalUnits = append(nalUnits, accessUnit[:length])
+		accessUnit = accessUnit[length:]
+	}
+
+	return nalUnits
+}
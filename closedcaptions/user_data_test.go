@@ -0,0 +1,105 @@
+package closedcaptions
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+// atscSeiPayload returns a registered ITU-T T.35 SEI message holding the ATSC user data of triplets
+func atscSeiPayload(triplets ...Triplet) []byte {
+	message := slices.Concat([]byte{t35CountryCodeUsa, 0x00, 0x31}, atscIdentifier, ccData(triplets...), []byte{0xFF})
+
+	return slices.Concat([]byte{seiPayloadTypeT35, byte(len(message))}, message)
+}
+
+// addEmulationPrevention inserts the bytes that keep start codes out of NAL units
+func addEmulationPrevention(data []byte) []byte {
+	result := []byte{}
+	zeros := 0
+	for _, b := range data {
+		if zeros >= 2 && b <= 0x03 {
+			result = append(result, 0x03)
+			zeros = 0
+		}
+
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		result = append(result, b)
+	}
+
+	return result
+}
+
+// lengthPrefixed returns NAL units with a four byte length before each
+func lengthPrefixed(nalUnits ...[]byte) []byte {
+	accessUnit := []byte{}
+	for _, nalUnit := range nalUnits {
+		accessUnit = append(accessUnit, byte(len(nalUnit)>>24), byte(len(nalUnit)>>16), byte(len(nalUnit)>>8), byte(len(nalUnit)))
+		accessUnit = append(accessUnit, nalUnit...)
+	}
+
+	return accessUnit
+}
+
+func TestTripletsFromAvc(t *testing.T) {
+	//Zero bytes in the triplets need emulation prevention, and a long unregistered message before the captions
+	//has its size coded with a 0xFF byte
+	triplets := []Triplet{{Data: [2]byte{0x00, 0x00}, Type: CcTypeField2}, {Data: [2]byte{0x94, 0x2F}, Type: CcTypeField1}}
+	unregistered := slices.Concat([]byte{5, 0xFF, 45}, bytes.Repeat([]byte{0x42}, 300))
+	sei := slices.Concat([]byte{avcNalUnitTypeSei}, addEmulationPrevention(slices.Concat(unregistered, atscSeiPayload(triplets...), []byte{0x80})))
+
+	slice := []byte{0x65, 0x88, 0x84, 0x00}
+	if result := TripletsFromAvc(lengthPrefixed(sei, slice), 4); !slices.Equal(result, triplets) {
+		t.Errorf("got %v, expected %v", result, triplets)
+	}
+
+	//Two byte lengths, and a truncated NAL unit
+	accessUnit := []byte{byte(len(sei) >> 8), byte(len(sei))}
+	accessUnit = append(accessUnit, sei...)
+	accessUnit = append(accessUnit, 0x00, 0x10, 0x65)
+	if result := TripletsFromAvc(accessUnit, 2); !slices.Equal(result, triplets) {
+		t.Errorf("two byte lengths: got %v, expected %v", result, triplets)
+	}
+}
+
+func TestTripletsFromAvcIgnoresOtherMessages(t *testing.T) {
+	//A T.35 message of another provider
+	message := slices.Concat([]byte{t35CountryCodeUsa, 0x00, 0x2F}, atscIdentifier, ccData(Triplet{Data: [2]byte{0x94, 0x2F}}))
+	sei := slices.Concat([]byte{avcNalUnitTypeSei, seiPayloadTypeT35, byte(len(message))}, message, []byte{0x80})
+
+	if result := TripletsFromAvc(lengthPrefixed(sei), 4); len(result) != 0 {
+		t.Errorf("got %v, expected no triplets", result)
+	}
+}
+
+func TestTripletsFromHevc(t *testing.T) {
+	triplets := []Triplet{{Data: [2]byte{0x02, 0x21}, Type: CcTypePacketStart}}
+	sei := slices.Concat([]byte{hevcNalUnitTypePrefixSei << 1, 0x01}, atscSeiPayload(triplets...), []byte{0x80})
+
+	if result := TripletsFromHevc(lengthPrefixed(sei), 4); !slices.Equal(result, triplets) {
+		t.Errorf("got %v, expected %v", result, triplets)
+	}
+
+	//An AVC SEI NAL unit type means something else in HEVC
+	if result := TripletsFromHevc(lengthPrefixed(slices.Concat([]byte{avcNalUnitTypeSei, 0x01}, atscSeiPayload(triplets...))), 4); len(result) != 0 {
+		t.Errorf("got %v, expected no triplets", result)
+	}
+}
+
+func TestTripletsFromMpeg2(t *testing.T) {
+	triplets := []Triplet{{Data: [2]byte{0x94, 0x20}, Type: CcTypeField1}, {Data: [2]byte{0x94, 0x20}, Type: CcTypeField1}}
+	picture := slices.Concat(
+		[]byte{0x00, 0x00, 0x01, 0x00, 0x12, 0x34},
+		[]byte{0x00, 0x00, 0x01, mpeg2UserDataStartCode}, []byte("other user data"),
+		[]byte{0x00, 0x00, 0x01, mpeg2UserDataStartCode}, atscIdentifier, ccData(triplets...), []byte{0xFF},
+		[]byte{0x00, 0x00, 0x01, 0x01, 0x22},
+	)
+
+	if result := TripletsFromMpeg2(picture); !slices.Equal(result, triplets) {
+		t.Errorf("got %v, expected %v", result, triplets)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/edsrzf/mmap-go"
 )
 
+// FileStream is a Stream over a file, which is memory mapped when possible
 type FileStream struct {
 	file           *os.File
 	filePosition   int64
@@ -34,7 +35,7 @@ func (f *FileStream) Close() error {
 		return f.mmapFile.Unmap()
 	}
 
-	return f.Close()
+	return f.file.Close()
 }
 
 func NewFileStream(path string) (*FileStream, error) {
@@ -78,7 +79,7 @@ func (f *FileStream) Position() int64 {
 
 func (f *FileStream) Read(b []byte) (int, error) {
 	if f.isMemoryMapped {
-		if f.filePosition == f.fileSize-1 {
+		if f.filePosition >= f.fileSize {
 			return 0, io.EOF
 		}
 
@@ -1,4 +1,30 @@
 package common
 
+import "fmt"
+
 type Paragraph struct {
+	Actor     string
+	EndTime   TimeCode
+	Extra     string
+	Forced    bool
+	Language  string
+	Number    int
+	Region    string
+	StartTime TimeCode
+	Style     string
+	//Text has its lines separated by \n and its formatting as the tags <b>, <i>, <u> and <font color face>, see the
+	//formatting package for the formatting of other formats
+	Text string
+}
+
+func NewParagraph(text string, startMilliseconds float64, endMilliseconds float64) *Paragraph {
+	return &Paragraph{EndTime: TimeCode{TotalMilliseconds: endMilliseconds}, StartTime: TimeCode{TotalMilliseconds: startMilliseconds}, Text: text}
+}
+
+func (p *Paragraph) Duration() TimeCode {
+	return TimeCode{TotalMilliseconds: p.EndTime.TotalMilliseconds - p.StartTime.TotalMilliseconds}
+}
+
+func (p *Paragraph) String() string {
+	return fmt.Sprintf("%v --> %v %v", p.StartTime.String(), p.EndTime.String(), p.Text)
 }
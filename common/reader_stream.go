@@ -0,0 +1,143 @@
+package common
+
+import (
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// readerStreamBufferSize is large enough to hold the headers that containers read a few bytes at a time
+const readerStreamBufferSize = 64 * 1024
+
+// ReaderStream is a Stream over an io.ReaderAt, such as an in-memory buffer or a reader issuing range requests.
+// Small reads are served from a read-ahead buffer so that the underlying reader sees few, larger reads
+type ReaderStream struct {
+	buffer         []byte
+	bufferPosition int64
+	isOpen         bool
+	position       int64
+	reader         io.ReaderAt
+	size           int64
+}
+
+// readSeekerAt reads at an offset by seeking, it is not safe for concurrent use
+type readSeekerAt struct {
+	reader io.ReadSeeker
+}
+
+func (r *readSeekerAt) ReadAt(p []byte, offset int64) (int, error) {
+	_, seekErr := r.reader.Seek(offset, io.SeekStart)
+	if seekErr != nil {
+		return 0, seekErr
+	}
+
+	return io.ReadFull(r.reader, p)
+}
+
+// Close releases the buffer, the underlying reader is owned by the caller and is left open
+func (r *ReaderStream) Close() error {
+	if !r.isOpen {
+		return nil
+	}
+
+	r.buffer = nil
+	r.isOpen = false
+	r.position = -1
+	r.size = -1
+
+	return nil
+}
+
+func NewReaderAtStream(reader io.ReaderAt, size int64) *ReaderStream {
+	return &ReaderStream{isOpen: true, reader: reader, size: size}
+}
+
+func NewReadSeekerStream(reader io.ReadSeeker) (*ReaderStream, error) {
+	size, seekErr := reader.Seek(0, io.SeekEnd)
+	if seekErr != nil {
+		return nil, errors.Wrap(seekErr, "failed to determine size of reader")
+	}
+
+	return NewReaderAtStream(&readSeekerAt{reader: reader}, size), nil
+}
+
+func (r *ReaderStream) Position() int64 {
+	return r.position
+}
+
+func (r *ReaderStream) Read(b []byte) (int, error) {
+	if r.position >= r.size {
+		return 0, io.EOF
+	}
+
+	if int64(len(b)) > r.size-r.position {
+		b = b[:r.size-r.position]
+	}
+
+	//Large reads bypass the buffer
+	if len(b) >= readerStreamBufferSize {
+		bytesRead, readErr := r.reader.ReadAt(b, r.position)
+		r.position += int64(bytesRead)
+		if readErr == io.EOF && bytesRead == len(b) {
+			readErr = nil
+		}
+
+		return bytesRead, readErr
+	}
+
+	bufferEnd := r.bufferPosition + int64(len(r.buffer))
+	if r.position < r.bufferPosition || r.position+int64(len(b)) > bufferEnd {
+		fillErr := r.fillBuffer()
+		if fillErr != nil {
+			return 0, fillErr
+		}
+	}
+
+	bytesCopied := copy(b, r.buffer[r.position-r.bufferPosition:])
+	r.position += int64(bytesCopied)
+
+	return bytesCopied, nil
+}
+
+func (r *ReaderStream) fillBuffer() error {
+	length := min(int64(readerStreamBufferSize), r.size-r.position)
+	if cap(r.buffer) < readerStreamBufferSize {
+		r.buffer = make([]byte, readerStreamBufferSize)
+	}
+	r.buffer = r.buffer[:length]
+
+	bytesRead, readErr := r.reader.ReadAt(r.buffer, r.position)
+	if readErr != nil && readErr != io.EOF {
+		r.buffer = r.buffer[:0]
+
+		return errors.Wrapf(readErr, "failed to read at position %d", r.position)
+	}
+
+	r.buffer = r.buffer[:bytesRead]
+	r.bufferPosition = r.position
+
+	if bytesRead == 0 {
+		return io.ErrUnexpectedEOF
+	}
+
+	return nil
+}
+
+func (r *ReaderStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		r.position += offset
+	case io.SeekEnd:
+		r.position = r.size + offset
+	case io.SeekStart:
+		r.position = offset
+	default:
+		return r.position, errors.Newf("invalid whence %d", whence)
+	}
+
+	return r.position, nil
+}
+
+func (r *ReaderStream) Size() int64 {
+	return r.size
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// countingReaderAt counts the reads reaching the underlying reader
+type countingReaderAt struct {
+	reader io.ReaderAt
+	reads  int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	c.reads++
+
+	return c.reader.ReadAt(p, offset)
+}
+
+// readerStreamData returns data of two and a half buffers where every byte depends on its position
+func readerStreamData() []byte {
+	data := make([]byte, readerStreamBufferSize*5/2)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+
+	return data
+}
+
+func TestReaderStreamReadsAcrossBuffers(t *testing.T) {
+	data := readerStreamData()
+	reader := &countingReaderAt{reader: bytes.NewReader(data)}
+	stream := NewReaderAtStream(reader, int64(len(data)))
+
+	//Small reads are served from the buffer, which is filled again when a read crosses its end
+	read, readErr := io.ReadAll(io.LimitReader(stream, readerStreamBufferSize+10))
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if !bytes.Equal(read, data[:readerStreamBufferSize+10]) {
+		t.Errorf("got different bytes reading across the end of the buffer")
+	}
+	if reader.reads != 2 {
+		t.Errorf("got %d reads of the underlying reader, expected 2", reader.reads)
+	}
+
+	buffer := make([]byte, 8)
+	if _, readErr = io.ReadFull(stream, buffer); readErr != nil || !bytes.Equal(buffer, data[readerStreamBufferSize+10:readerStreamBufferSize+18]) {
+		t.Errorf("got %v (%v), expected the bytes following the previous read", buffer, readErr)
+	}
+	if reader.reads != 2 {
+		t.Errorf("got %d reads of the underlying reader, expected the buffer to serve the read", reader.reads)
+	}
+
+	//A large read bypasses the buffer
+	large := make([]byte, readerStreamBufferSize)
+	if _, readErr = io.ReadFull(stream, large); readErr != nil || !bytes.Equal(large, data[readerStreamBufferSize+18:2*readerStreamBufferSize+18]) {
+		t.Errorf("got different bytes for a read larger than the buffer (%v)", readErr)
+	}
+	if stream.Position() != 2*readerStreamBufferSize+18 {
+		t.Errorf("got position %d, expected %d", stream.Position(), 2*readerStreamBufferSize+18)
+	}
+}
+
+func TestReaderStreamSeeks(t *testing.T) {
+	data := readerStreamData()
+	stream := NewReaderAtStream(bytes.NewReader(data), int64(len(data)))
+
+	for _, test := range []struct {
+		offset   int64
+		whence   int
+		expected int64
+	}{
+		{offset: readerStreamBufferSize - 4, whence: io.SeekStart, expected: readerStreamBufferSize - 4},
+		{offset: 100, whence: io.SeekCurrent, expected: readerStreamBufferSize + 104},
+		{offset: -300, whence: io.SeekCurrent, expected: readerStreamBufferSize - 188},
+		{offset: 10, whence: io.SeekStart, expected: 10},
+		{offset: -10, whence: io.SeekEnd, expected: int64(len(data)) - 10},
+	} {
+		position, seekErr := stream.Seek(test.offset, test.whence)
+		if seekErr != nil || position != test.expected {
+			t.Errorf("seek %d from %d: got %d (%v), expected %d", test.offset, test.whence, position, seekErr, test.expected)
+			continue
+		}
+
+		buffer := make([]byte, 8)
+		bytesRead, readErr := io.ReadFull(stream, buffer)
+		if readErr != nil || !bytes.Equal(buffer, data[position:position+8]) {
+			t.Errorf("seek %d from %d: got %v (%v), expected %v", test.offset, test.whence, buffer[:bytesRead], readErr, data[position:position+8])
+		}
+	}
+
+	if _, seekErr := stream.Seek(0, 42); seekErr == nil {
+		t.Errorf("expected an error for an invalid whence")
+	}
+}
+
+func TestReaderStreamEof(t *testing.T) {
+	data := readerStreamData()
+	stream, streamErr := NewReadSeekerStream(bytes.NewReader(data))
+	if streamErr != nil {
+		t.Fatal(streamErr)
+	}
+	if stream.Size() != int64(len(data)) {
+		t.Errorf("got size %d, expected %d", stream.Size(), len(data))
+	}
+
+	//A read past the end returns the bytes that are left
+	stream.Seek(-5, io.SeekEnd)
+	buffer := make([]byte, 10)
+	bytesRead, readErr := stream.Read(buffer)
+	if readErr != nil || bytesRead != 5 || !bytes.Equal(buffer[:bytesRead], data[len(data)-5:]) {
+		t.Errorf("got %d bytes (%v), expected the last 5 bytes", bytesRead, readErr)
+	}
+
+	bytesRead, readErr = stream.Read(buffer)
+	if bytesRead != 0 || readErr != io.EOF {
+		t.Errorf("got %d bytes (%v), expected io.EOF", bytesRead, readErr)
+	}
+
+	stream.Seek(10, io.SeekEnd)
+	if bytesRead, readErr = stream.Read(buffer); bytesRead != 0 || readErr != io.EOF {
+		t.Errorf("got %d bytes (%v) past the end, expected io.EOF", bytesRead, readErr)
+	}
+}
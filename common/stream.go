@@ -0,0 +1,12 @@
+package common
+
+import "io"
+
+// Stream is a seekable source of known size that containers are read from
+type Stream interface {
+	io.ReadSeeker
+	io.Closer
+
+	Position() int64
+	Size() int64
+}
@@ -1,5 +1,36 @@
 package common
 
+import (
+	"cmp"
+	"slices"
+)
+
 type Subtitle struct {
+	Footer     string
+	Header     string
 	Paragraphs []Paragraph
 }
+
+// ChangeFrameRate retimes the paragraphs of a subtitle made for video at one frame rate to video at another, so
+// that every paragraph stays on the same frames. See FrameRateOrDefault for unknown frame rates
+func (s *Subtitle) ChangeFrameRate(oldFrameRate float64, newFrameRate float64) {
+	factor := FrameRateOrDefault(oldFrameRate) / FrameRateOrDefault(newFrameRate)
+	for i := range s.Paragraphs {
+		s.Paragraphs[i].StartTime.TotalMilliseconds *= factor
+		s.Paragraphs[i].EndTime.TotalMilliseconds *= factor
+	}
+}
+
+// Renumber numbers the paragraphs in their current order, starting at startNumber
+func (s *Subtitle) Renumber(startNumber int) {
+	for i := range s.Paragraphs {
+		s.Paragraphs[i].Number = startNumber + i
+	}
+}
+
+// SortByStartTime orders the paragraphs by start time, keeping the order of paragraphs starting together
+func (s *Subtitle) SortByStartTime() {
+	slices.SortStableFunc(s.Paragraphs, func(a, b Paragraph) int {
+		return cmp.Compare(a.StartTime.TotalMilliseconds, b.StartTime.TotalMilliseconds)
+	})
+}
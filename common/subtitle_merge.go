@@ -0,0 +1,126 @@
+package common
+
+import (
+	"slices"
+	"strings"
+)
+
+// Append adds the paragraphs of another subtitle shifted by an offset in milliseconds, such as the duration of
+// the first part of an episode, and renumbers the paragraphs
+func (s *Subtitle) Append(other *Subtitle, offset float64) {
+	startNumber := 1
+	if len(s.Paragraphs) > 0 {
+		startNumber = s.Paragraphs[0].Number
+	}
+
+	for _, paragraph := range other.Paragraphs {
+		paragraph.StartTime.TotalMilliseconds += offset
+		paragraph.EndTime.TotalMilliseconds += offset
+		s.Paragraphs = append(s.Paragraphs, paragraph)
+	}
+
+	s.Renumber(max(startNumber, 1))
+}
+
+// MergeBilingual returns a subtitle with the text of each paragraph of the primary subtitle stacked above the text
+// of the paragraphs of the secondary subtitle that overlap it the most. Secondary paragraphs overlapping no primary
+// paragraph are kept on their own
+func MergeBilingual(primary *Subtitle, secondary *Subtitle) *Subtitle {
+	merged := &Subtitle{Footer: primary.Footer, Header: primary.Header, Paragraphs: slices.Clone(primary.Paragraphs)}
+	secondaryTexts := make([][]string, len(merged.Paragraphs))
+
+	for _, paragraph := range secondary.Paragraphs {
+		best, bestOverlap := -1, 0.0
+		for i, primaryParagraph := range primary.Paragraphs {
+			overlap := min(paragraph.EndTime.TotalMilliseconds, primaryParagraph.EndTime.TotalMilliseconds) -
+				max(paragraph.StartTime.TotalMilliseconds, primaryParagraph.StartTime.TotalMilliseconds)
+			if overlap > bestOverlap {
+				best, bestOverlap = i, overlap
+			}
+		}
+
+		if best < 0 {
+			merged.Paragraphs = append(merged.Paragraphs, paragraph)
+
+			continue
+		}
+		secondaryTexts[best] = append(secondaryTexts[best], paragraph.Text)
+	}
+
+	for i, texts := range secondaryTexts {
+		if len(texts) > 0 {
+			merged.Paragraphs[i].Text = strings.Join(append([]string{merged.Paragraphs[i].Text}, texts...), "\n")
+		}
+	}
+
+	merged.SortByStartTime()
+	merged.Renumber(1)
+
+	return merged
+}
+
+// MergeSameText merges paragraphs with the same text that follow each other with at most a gap in milliseconds
+// between them, such as a line split across a shot change, and returns the number of paragraphs merged away
+func (s *Subtitle) MergeSameText(maxGap float64) int {
+	if len(s.Paragraphs) == 0 {
+		return 0
+	}
+
+	paragraphs := []Paragraph{s.Paragraphs[0]}
+	for _, paragraph := range s.Paragraphs[1:] {
+		previous := &paragraphs[len(paragraphs)-1]
+		gap := paragraph.StartTime.TotalMilliseconds - previous.EndTime.TotalMilliseconds
+		if strings.TrimSpace(paragraph.Text) == strings.TrimSpace(previous.Text) && gap <= maxGap {
+			previous.EndTime.TotalMilliseconds = max(previous.EndTime.TotalMilliseconds, paragraph.EndTime.TotalMilliseconds)
+
+			continue
+		}
+
+		paragraphs = append(paragraphs, paragraph)
+	}
+
+	merged := len(s.Paragraphs) - len(paragraphs)
+	if merged > 0 {
+		startNumber := s.Paragraphs[0].Number
+		s.Paragraphs = paragraphs
+		s.Renumber(max(startNumber, 1))
+	}
+
+	return merged
+}
+
+// SplitAtIndex returns the paragraphs before an index and the paragraphs from the index on as two subtitles,
+// keeping their times
+func (s *Subtitle) SplitAtIndex(index int) (*Subtitle, *Subtitle) {
+	index = max(0, min(index, len(s.Paragraphs)))
+
+	first := &Subtitle{Footer: s.Footer, Header: s.Header, Paragraphs: slices.Clone(s.Paragraphs[:index])}
+	second := &Subtitle{Footer: s.Footer, Header: s.Header, Paragraphs: slices.Clone(s.Paragraphs[index:])}
+	first.Renumber(1)
+	second.Renumber(1)
+
+	return first, second
+}
+
+// SplitAtTime returns the paragraphs starting before a time in milliseconds and the paragraphs starting from it as
+// two subtitles, the paragraphs are expected to be sorted by start time. The times of the second subtitle are made
+// relative to the split, as for the second part of an episode, and paragraphs crossing the split end at it
+func (s *Subtitle) SplitAtTime(milliseconds float64) (*Subtitle, *Subtitle) {
+	index := slices.IndexFunc(s.Paragraphs, func(paragraph Paragraph) bool {
+		return paragraph.StartTime.TotalMilliseconds >= milliseconds
+	})
+	if index < 0 {
+		index = len(s.Paragraphs)
+	}
+
+	first, second := s.SplitAtIndex(index)
+	for i := range first.Paragraphs {
+		first.Paragraphs[i].EndTime.TotalMilliseconds = min(first.Paragraphs[i].EndTime.TotalMilliseconds, milliseconds)
+	}
+	for i := range second.Paragraphs {
+		second.Paragraphs[i].StartTime.TotalMilliseconds -= milliseconds
+		second.Paragraphs[i].EndTime.TotalMilliseconds -= milliseconds
+	}
+
+	return first, second
+}
@@ -0,0 +1,175 @@
+package common
+
+import (
+	"testing"
+)
+
+type expectedParagraph struct {
+	end    float64
+	number int
+	start  float64
+	text   string
+}
+
+func checkParagraphs(t *testing.T, name string, paragraphs []Paragraph, expected []expectedParagraph) {
+	t.Helper()
+
+	if len(paragraphs) != len(expected) {
+		t.Fatalf("%s: got %d paragraphs, expected %d", name, len(paragraphs), len(expected))
+	}
+	for i, paragraph := range paragraphs {
+		if paragraph.Number != expected[i].number || paragraph.StartTime.TotalMilliseconds != expected[i].start ||
+			paragraph.EndTime.TotalMilliseconds != expected[i].end || paragraph.Text != expected[i].text {
+			t.Errorf("%s: paragraph %d is %d %s, expected %d %v --> %v %q", name, i, paragraph.Number, paragraph.String(), expected[i].number, expected[i].start, expected[i].end, expected[i].text)
+		}
+	}
+}
+
+func newSubtitle(paragraphs ...*Paragraph) *Subtitle {
+	subtitle := &Subtitle{Footer: "Footer", Header: "Header"}
+	for _, paragraph := range paragraphs {
+		subtitle.Paragraphs = append(subtitle.Paragraphs, *paragraph)
+	}
+	subtitle.Renumber(1)
+
+	return subtitle
+}
+
+func TestAppend(t *testing.T) {
+	subtitle := newSubtitle(NewParagraph("First", 1000, 2000))
+	subtitle.Renumber(3)
+	other := newSubtitle(NewParagraph("Second", 500, 1500), NewParagraph("Third", 2000, 3000))
+
+	subtitle.Append(other, 60000)
+	checkParagraphs(t, "append", subtitle.Paragraphs, []expectedParagraph{
+		{end: 2000, number: 3, start: 1000, text: "First"},
+		{end: 61500, number: 4, start: 60500, text: "Second"},
+		{end: 63000, number: 5, start: 62000, text: "Third"},
+	})
+	checkParagraphs(t, "appended", other.Paragraphs, []expectedParagraph{
+		{end: 1500, number: 1, start: 500, text: "Second"},
+		{end: 3000, number: 2, start: 2000, text: "Third"},
+	})
+
+	empty := &Subtitle{}
+	empty.Append(other, 0)
+	checkParagraphs(t, "append to empty", empty.Paragraphs, []expectedParagraph{
+		{end: 1500, number: 1, start: 500, text: "Second"},
+		{end: 3000, number: 2, start: 2000, text: "Third"},
+	})
+}
+
+func TestMergeBilingual(t *testing.T) {
+	primary := newSubtitle(NewParagraph("Hello", 1000, 3000), NewParagraph("Goodbye", 5000, 7000))
+	secondary := newSubtitle(
+		NewParagraph("Bonjour", 1100, 2000),
+		NewParagraph("le monde", 2000, 3100),
+		//Overlaps the first primary paragraph by 500 ms and the second by 1000 ms
+		NewParagraph("Au revoir", 2500, 6000),
+		NewParagraph("Seul", 8000, 9000),
+		NewParagraph("Avant", 0, 1000),
+	)
+
+	merged := MergeBilingual(primary, secondary)
+	if merged.Header != "Header" || merged.Footer != "Footer" {
+		t.Errorf("got header %q and footer %q, expected those of the primary subtitle", merged.Header, merged.Footer)
+	}
+	checkParagraphs(t, "merge", merged.Paragraphs, []expectedParagraph{
+		{end: 1000, number: 1, start: 0, text: "Avant"},
+		{end: 3000, number: 2, start: 1000, text: "Hello\nBonjour\nle monde"},
+		{end: 7000, number: 3, start: 5000, text: "Goodbye\nAu revoir"},
+		{end: 9000, number: 4, start: 8000, text: "Seul"},
+	})
+	checkParagraphs(t, "primary", primary.Paragraphs, []expectedParagraph{
+		{end: 3000, number: 1, start: 1000, text: "Hello"},
+		{end: 7000, number: 2, start: 5000, text: "Goodbye"},
+	})
+}
+
+func TestMergeSameText(t *testing.T) {
+	subtitle := newSubtitle(
+		NewParagraph("Same", 1000, 2000),
+		NewParagraph(" Same ", 2040, 3000),
+		NewParagraph("Same", 3000, 2500),
+		NewParagraph("Same", 4000, 5000),
+		NewParagraph("Other", 5000, 6000),
+	)
+	subtitle.Renumber(10)
+
+	if merged := subtitle.MergeSameText(100); merged != 2 {
+		t.Errorf("got %d paragraphs merged, expected 2", merged)
+	}
+	checkParagraphs(t, "merge same text", subtitle.Paragraphs, []expectedParagraph{
+		{end: 3000, number: 10, start: 1000, text: "Same"},
+		{end: 5000, number: 11, start: 4000, text: "Same"},
+		{end: 6000, number: 12, start: 5000, text: "Other"},
+	})
+
+	empty := &Subtitle{}
+	if merged := empty.MergeSameText(100); merged != 0 {
+		t.Errorf("got %d paragraphs merged in an empty subtitle", merged)
+	}
+}
+
+func TestSplitAtIndex(t *testing.T) {
+	tests := []struct {
+		index       int
+		firstTexts  []string
+		secondTexts []string
+	}{
+		{index: 1, firstTexts: []string{"First"}, secondTexts: []string{"Second", "Third"}},
+		{index: -1, firstTexts: []string{}, secondTexts: []string{"First", "Second", "Third"}},
+		{index: 5, firstTexts: []string{"First", "Second", "Third"}, secondTexts: []string{}},
+	}
+
+	for _, test := range tests {
+		subtitle := newSubtitle(NewParagraph("First", 1000, 2000), NewParagraph("Second", 3000, 4000), NewParagraph("Third", 5000, 6000))
+		first, second := subtitle.SplitAtIndex(test.index)
+
+		for name, part := range map[string]struct {
+			subtitle *Subtitle
+			texts    []string
+		}{"first": {first, test.firstTexts}, "second": {second, test.secondTexts}} {
+			if part.subtitle.Header != "Header" || part.subtitle.Footer != "Footer" {
+				t.Errorf("index %d: %s part lost its header or footer", test.index, name)
+			}
+			if len(part.subtitle.Paragraphs) != len(part.texts) {
+				t.Errorf("index %d: %s part has %d paragraphs, expected %d", test.index, name, len(part.subtitle.Paragraphs), len(part.texts))
+
+				continue
+			}
+			for i, paragraph := range part.subtitle.Paragraphs {
+				if paragraph.Text != part.texts[i] || paragraph.Number != i+1 {
+					t.Errorf("index %d: %s part paragraph %d is %d %q, expected %d %q", test.index, name, i, paragraph.Number, paragraph.Text, i+1, part.texts[i])
+				}
+			}
+		}
+
+		if subtitle.Paragraphs[1].Number != 2 {
+			t.Errorf("index %d: splitting renumbered the original subtitle", test.index)
+		}
+	}
+}
+
+func TestSplitAtTime(t *testing.T) {
+	subtitle := newSubtitle(NewParagraph("First", 1000, 2000), NewParagraph("Crossing", 3000, 6000), NewParagraph("Second", 5000, 7000))
+
+	first, second := subtitle.SplitAtTime(5000)
+	checkParagraphs(t, "first", first.Paragraphs, []expectedParagraph{
+		{end: 2000, number: 1, start: 1000, text: "First"},
+		{end: 5000, number: 2, start: 3000, text: "Crossing"},
+	})
+	checkParagraphs(t, "second", second.Paragraphs, []expectedParagraph{
+		{end: 2000, number: 1, start: 0, text: "Second"},
+	})
+	checkParagraphs(t, "original", subtitle.Paragraphs, []expectedParagraph{
+		{end: 2000, number: 1, start: 1000, text: "First"},
+		{end: 6000, number: 2, start: 3000, text: "Crossing"},
+		{end: 7000, number: 3, start: 5000, text: "Second"},
+	})
+
+	first, second = subtitle.SplitAtTime(10000)
+	if len(first.Paragraphs) != 3 || len(second.Paragraphs) != 0 {
+		t.Errorf("got %d and %d paragraphs after the end, expected 3 and 0", len(first.Paragraphs), len(second.Paragraphs))
+	}
+}
@@ -1,5 +1,80 @@
 package common
 
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// DefaultFrameRate is the frame rate of frame based formats when the frame rate of the video is unknown
+	DefaultFrameRate = 23.976
+	// MaxTimeTotalMilliseconds is the largest time that fits in the hh:mm:ss,zzz format
+	MaxTimeTotalMilliseconds = 359999999
+)
+
 type TimeCode struct {
 	TotalMilliseconds float64
 }
+
+func NewTimeCode(hours int, minutes int, seconds int, milliseconds int) *TimeCode {
+	return &TimeCode{TotalMilliseconds: float64(hours*3600000 + minutes*60000 + seconds*1000 + milliseconds)}
+}
+
+// NewTimeCodeFromFrames returns the time of a frame number at a frame rate, see FrameRateOrDefault
+func NewTimeCodeFromFrames(frames float64, frameRate float64) *TimeCode {
+	return &TimeCode{TotalMilliseconds: frames * 1000.0 / FrameRateOrDefault(frameRate)}
+}
+
+func NewTimeCodeFromSeconds(seconds float64) *TimeCode {
+	return &TimeCode{TotalMilliseconds: seconds * 1000.0}
+}
+
+// FrameRateOrDefault returns the frame rate, or DefaultFrameRate if it is zero or less, so that unknown frame rates
+// such as that of a Matroska file without a video track can be passed as they are
+func FrameRateOrDefault(frameRate float64) float64 {
+	if frameRate <= 0 {
+		return DefaultFrameRate
+	}
+
+	return frameRate
+}
+
+func (t *TimeCode) Hours() int {
+	return int(t.roundedMilliseconds() / 3600000)
+}
+
+func (t *TimeCode) Milliseconds() int {
+	return int(t.roundedMilliseconds() % 1000)
+}
+
+func (t *TimeCode) Minutes() int {
+	return int(t.roundedMilliseconds() / 60000 % 60)
+}
+
+// roundedMilliseconds returns the absolute time rounded to whole milliseconds, which the components are based on
+func (t *TimeCode) roundedMilliseconds() int64 {
+	return int64(math.Round(math.Abs(t.TotalMilliseconds)))
+}
+
+func (t *TimeCode) Seconds() int {
+	return int(t.roundedMilliseconds() / 1000 % 60)
+}
+
+// String returns the time in the hh:mm:ss,zzz format, with a leading minus for negative times
+func (t *TimeCode) String() string {
+	sign := ""
+	if t.TotalMilliseconds < 0 {
+		sign = "-"
+	}
+
+	return fmt.Sprintf("%s%02d:%02d:%02d,%03d", sign, t.Hours(), t.Minutes(), t.Seconds(), t.Milliseconds())
+}
+
+// TotalFrames returns the number of the frame shown at the time at a frame rate, see FrameRateOrDefault
+func (t *TimeCode) TotalFrames(frameRate float64) int {
+	return int(math.Round(t.TotalMilliseconds * FrameRateOrDefault(frameRate) / 1000.0))
+}
+
+func (t *TimeCode) TotalSeconds() float64 {
+	return t.TotalMilliseconds / 1000.0
+}
@@ -0,0 +1,119 @@
+package commonerrors
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ristryder/gse/common"
+)
+
+// Fix is a change a fixer found for a paragraph, which is removed instead of changed if IsRemoval is set
+type Fix struct {
+	After  common.Paragraph
+	Before common.Paragraph
+	Fixer  string
+	//Index is the position of the paragraph in the subtitle the fix was found in
+	Index     int
+	IsRemoval bool
+}
+
+func (f *Fix) String() string {
+	if f.IsRemoval {
+		return fmt.Sprintf("Fixer: %v , Number: %v , Removed: %q", f.Fixer, f.Before.Number, f.Before.Text)
+	}
+
+	return fmt.Sprintf("Fixer: %v , Number: %v , Before: %v --> %v %q , After: %v --> %v %q", f.Fixer, f.Before.Number, f.Before.StartTime.String(), f.Before.EndTime.String(), f.Before.Text, f.After.StartTime.String(), f.After.EndTime.String(), f.After.Text)
+}
+
+// Fixer finds the fixes of one kind of error without changing the subtitle, so that they can be reviewed before
+// they are applied
+type Fixer interface {
+	Find(subtitle *common.Subtitle) []Fix
+	Name() string
+}
+
+// Apply applies fixes found in the subtitle. Fixes of paragraphs that have changed since they were found are
+// skipped, as are removals of a paragraph already removed, and the number of fixes applied is returned. Paragraphs are renumbered when some are removed
+func Apply(subtitle *common.Subtitle, fixes []Fix) int {
+	applied := 0
+	removals := []int{}
+
+	for _, fix := range fixes {
+		if fix.Index < 0 || fix.Index >= len(subtitle.Paragraphs) || subtitle.Paragraphs[fix.Index] != fix.Before {
+			continue
+		}
+
+		if fix.IsRemoval {
+			//The same paragraph may be removed by more than one fix
+			if slices.Contains(removals, fix.Index) {
+				continue
+			}
+
+			removals = append(removals, fix.Index)
+		} else {
+			subtitle.Paragraphs[fix.Index] = fix.After
+		}
+		applied++
+	}
+
+	if len(removals) > 0 {
+		firstNumber := subtitle.Paragraphs[0].Number
+
+		slices.Sort(removals)
+		for i := len(removals) - 1; i >= 0; i-- {
+			subtitle.Paragraphs = slices.Delete(subtitle.Paragraphs, removals[i], removals[i]+1)
+		}
+		subtitle.Renumber(firstNumber)
+	}
+
+	return applied
+}
+
+// DefaultFixers returns every fixer with the limits of the Netflix style guide, in the order they are best run
+func DefaultFixers() []Fixer {
+	return []Fixer{
+		&FixEmptyLines{},
+		&FixInvalidItalicTags{},
+		&FixUnneededSpaces{},
+		&FixMissingSpaces{},
+		&FixUnneededPeriods{},
+		&FixAloneLowercaseI{},
+		&FixDialogHyphens{},
+		&FixShortLines{MaxLineLength: 42},
+		&FixOverlappingDisplayTimes{MinGap: 83},
+		&FixLongDisplayTimes{MaxDuration: 7000},
+		&FixShortDisplayTimes{MaxCharactersPerSecond: 20, MinDuration: 833, MinGap: 83},
+	}
+}
+
+// FindTextFixes returns the fixes of the paragraphs whose text a function changes, for fixers of other packages
+// that only change text
+func FindTextFixes(subtitle *common.Subtitle, name string, fixText func(text string) string) []Fix {
+	fixes := []Fix{}
+
+	for i, paragraph := range subtitle.Paragraphs {
+		text := fixText(paragraph.Text)
+		if text == paragraph.Text {
+			continue
+		}
+
+		after := paragraph
+		after.Text = text
+		fixes = append(fixes, Fix{After: after, Before: paragraph, Fixer: name, Index: i})
+	}
+
+	return fixes
+}
+
+// FixAll runs the fixers in order, applying the fixes of each before the next one runs, and returns all fixes
+func FixAll(subtitle *common.Subtitle, fixers ...Fixer) []Fix {
+	allFixes := []Fix{}
+
+	for _, fixer := range fixers {
+		fixes := fixer.Find(subtitle)
+		Apply(subtitle, fixes)
+		allFixes = append(allFixes, fixes...)
+	}
+
+	return allFixes
+}
@@ -0,0 +1,106 @@
+package commonerrors
+
+import (
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+func newFixSubtitle(texts ...string) *common.Subtitle {
+	subtitle := &common.Subtitle{}
+	for i, text := range texts {
+		subtitle.Paragraphs = append(subtitle.Paragraphs, *common.NewParagraph(text, float64(i*2000), float64(i*2000+1500)))
+	}
+	subtitle.Renumber(1)
+
+	return subtitle
+}
+
+func TestApply(t *testing.T) {
+	subtitle := newFixSubtitle("Keep", "", "Two  spaces", "Stale  spaces", "\n")
+	fixes := append((&FixEmptyLines{}).Find(subtitle), (&FixUnneededSpaces{}).Find(subtitle)...)
+
+	//The paragraph changes after its fix was found
+	subtitle.Paragraphs[3].Text = "Changed"
+
+	if applied := Apply(subtitle, fixes); applied != 3 {
+		t.Errorf("got %d fixes applied, expected 3", applied)
+	}
+
+	expected := []string{"Keep", "Two spaces", "Changed"}
+	if len(subtitle.Paragraphs) != len(expected) {
+		t.Fatalf("got %d paragraphs, expected %d", len(subtitle.Paragraphs), len(expected))
+	}
+	for i, paragraph := range subtitle.Paragraphs {
+		if paragraph.Text != expected[i] || paragraph.Number != i+1 {
+			t.Errorf("paragraph %d: got %d %q, expected %d %q", i, paragraph.Number, paragraph.Text, i+1, expected[i])
+		}
+	}
+}
+
+func TestApplyRemovesParagraphOnce(t *testing.T) {
+	subtitle := newFixSubtitle("a", "", "c")
+	fixes := (&FixEmptyLines{}).Find(subtitle)
+
+	//The same removal passed twice must not remove the next paragraph as well
+	if applied := Apply(subtitle, append(fixes, fixes...)); applied != 1 {
+		t.Errorf("got %d fixes applied, expected 1", applied)
+	}
+
+	if len(subtitle.Paragraphs) != 2 || subtitle.Paragraphs[0].Text != "a" || subtitle.Paragraphs[1].Text != "c" {
+		t.Errorf("got %v, expected a and c", subtitle.Paragraphs)
+	}
+}
+
+func TestApplyOutOfRange(t *testing.T) {
+	subtitle := newFixSubtitle("Text")
+	fix := Fix{After: subtitle.Paragraphs[0], Before: subtitle.Paragraphs[0], Index: 1, IsRemoval: true}
+
+	if applied := Apply(subtitle, []Fix{fix}); applied != 0 || len(subtitle.Paragraphs) != 1 {
+		t.Errorf("got %d fixes applied and %d paragraphs, expected none applied", applied, len(subtitle.Paragraphs))
+	}
+}
+
+func TestFixAll(t *testing.T) {
+	subtitle := newFixSubtitle("Hello,world!. i think i'm fine  , ok..", "  \n", "<I>Short line</I>\n<i>another short</i>")
+	subtitle.Renumber(5)
+
+	fixes := FixAll(subtitle, DefaultFixers()...)
+	if len(fixes) == 0 {
+		t.Fatal("expected fixes")
+	}
+
+	expected := []string{"Hello, world! I think I'm fine, ok.", "<i>Short line another short</i>"}
+	if len(subtitle.Paragraphs) != len(expected) {
+		t.Fatalf("got %d paragraphs, expected %d", len(subtitle.Paragraphs), len(expected))
+	}
+	for i, paragraph := range subtitle.Paragraphs {
+		if paragraph.Text != expected[i] || paragraph.Number != i+5 {
+			t.Errorf("paragraph %d: got %d %q, expected %d %q", i, paragraph.Number, paragraph.Text, i+5, expected[i])
+		}
+	}
+}
+
+func TestFixString(t *testing.T) {
+	before := *common.NewParagraph("Text", 1000, 2000)
+	before.Number = 3
+	after := before
+	after.Text = "Fixed"
+
+	tests := []struct {
+		fix      Fix
+		expected string
+	}{
+		{
+			fix:      Fix{After: after, Before: before, Fixer: "Fixer"},
+			expected: `Fixer: Fixer , Number: 3 , Before: ` + before.StartTime.String() + ` --> ` + before.EndTime.String() + ` "Text" , After: ` + after.StartTime.String() + ` --> ` + after.EndTime.String() + ` "Fixed"`,
+		},
+		{fix: Fix{After: before, Before: before, Fixer: "Fixer", IsRemoval: true}, expected: `Fixer: Fixer , Number: 3 , Removed: "Text"`},
+	}
+
+	for _, test := range tests {
+		if text := test.fix.String(); text != test.expected {
+			t.Errorf("got %q, expected %q", text, test.expected)
+		}
+	}
+}
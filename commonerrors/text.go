@@ -0,0 +1,297 @@
+package commonerrors
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ristryder/gse/common"
+)
+
+// DialogDashes are the dashes starting the line of a speaker in a dialog
+const DialogDashes = "-‐–—"
+
+var (
+	doublePeriodRegex       = regexp.MustCompile(`([^.]|^)\.\.([^.]|$)`)
+	emptyItalicRegex        = regexp.MustCompile(`<i>\s*</i>`)
+	italicGapRegex          = regexp.MustCompile(`</i>([ \t]*)<i>`)
+	leadingTagsRegex        = regexp.MustCompile(`^(?:<[^<>]*>)*`)
+	loneLowercaseIRegex     = regexp.MustCompile(`\bi\b`)
+	missingSpaceAfterPeriod = regexp.MustCompile(`(\p{L}\p{Ll})\.(\p{Lu}\p{Ll})`)
+	missingSpaceRegex       = regexp.MustCompile(`(\p{L})([,!?;])(\p{L})`)
+	multipleSpacesRegex     = regexp.MustCompile(`[ \t]{2,}`)
+	periodAfterMarkRegex    = regexp.MustCompile(`([!?])\.([^.]|$)`)
+	spaceBeforeCommaRegex   = regexp.MustCompile(`(\p{L}) +,`)
+	uppercaseItalicRegex    = regexp.MustCompile(`<(/?)I>`)
+)
+
+// FixAloneLowercaseI changes the English pronoun i to I, also in contractions such as i'm
+type FixAloneLowercaseI struct{}
+
+func (f *FixAloneLowercaseI) Find(subtitle *common.Subtitle) []Fix {
+	return FindTextFixes(subtitle, f.Name(), func(text string) string {
+		return mapOutsideTags(text, func(part string) string {
+			matches := loneLowercaseIRegex.FindAllStringIndex(part, -1)
+			builder := []byte(part)
+			for _, match := range matches {
+				previous, _ := utf8.DecodeLastRuneInString(part[:match[0]])
+				next := part[match[1]:]
+				if strings.ContainsRune("'’-.", previous) || strings.HasPrefix(next, ".e") || strings.HasPrefix(next, "-") {
+					continue
+				}
+
+				builder[match[0]] = 'I'
+			}
+
+			return string(builder)
+		})
+	})
+}
+
+func (f *FixAloneLowercaseI) Name() string {
+	return "Fix alone lowercase i to uppercase I"
+}
+
+// FixDialogHyphens gives every line of a dialog the same dash, adds the missing dash to the first line of a two
+// line dialog and removes the dash of paragraphs with a single line
+type FixDialogHyphens struct {
+	//DashWithoutSpace writes dashes directly before the text instead of followed by a space
+	DashWithoutSpace bool
+}
+
+func (f *FixDialogHyphens) Find(subtitle *common.Subtitle) []Fix {
+	dash := "- "
+	if f.DashWithoutSpace {
+		dash = "-"
+	}
+
+	return FindTextFixes(subtitle, f.Name(), func(text string) string {
+		lines := strings.Split(text, "\n")
+		hasDash := make([]bool, len(lines))
+		dashCount := 0
+		for i, line := range lines {
+			_, hasDash[i], _ = SplitDialogDash(line)
+			if hasDash[i] {
+				dashCount++
+			}
+		}
+
+		switch {
+		case len(lines) == 1 && dashCount == 1:
+			tags, _, rest := SplitDialogDash(lines[0])
+
+			return tags + rest
+		case len(lines) == 2 && !hasDash[0] && hasDash[1]:
+			//Only a first line ending a sentence is the first speaker of a dialog
+			last, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(TagRegex.ReplaceAllString(lines[0], "")))
+			if !strings.ContainsRune(".!?\"♪", last) {
+				return text
+			}
+			hasDash[0], dashCount = true, 2
+		}
+		if dashCount < 2 || dashCount != len(lines) {
+			return text
+		}
+
+		for i, line := range lines {
+			tags, _, rest := SplitDialogDash(line)
+			lines[i] = tags + dash + rest
+		}
+
+		return strings.Join(lines, "\n")
+	})
+}
+
+func (f *FixDialogHyphens) Name() string {
+	return "Fix dialog hyphens"
+}
+
+// FixEmptyLines removes empty lines and the spaces around the text, and removes paragraphs without text
+type FixEmptyLines struct{}
+
+func (f *FixEmptyLines) Find(subtitle *common.Subtitle) []Fix {
+	fixes := FindTextFixes(subtitle, f.Name(), func(text string) string {
+		lines := []string{}
+		for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+			if strings.TrimSpace(TagRegex.ReplaceAllString(line, "")) != "" {
+				lines = append(lines, line)
+			}
+		}
+
+		return strings.TrimSpace(strings.Join(lines, "\n"))
+	})
+
+	for i := range fixes {
+		fixes[i].IsRemoval = fixes[i].After.Text == ""
+	}
+	for i, paragraph := range subtitle.Paragraphs {
+		if paragraph.Text == "" {
+			fixes = append(fixes, Fix{After: paragraph, Before: paragraph, Fixer: f.Name(), Index: i, IsRemoval: true})
+		}
+	}
+
+	return fixes
+}
+
+func (f *FixEmptyLines) Name() string {
+	return "Fix empty lines"
+}
+
+// FixInvalidItalicTags balances italic tags, removes empty italics and joins italics separated by spaces
+type FixInvalidItalicTags struct{}
+
+func (f *FixInvalidItalicTags) Find(subtitle *common.Subtitle) []Fix {
+	return FindTextFixes(subtitle, f.Name(), func(text string) string {
+		text = uppercaseItalicRegex.ReplaceAllString(text, "<${1}i>")
+		text = emptyItalicRegex.ReplaceAllString(text, "")
+		text = italicGapRegex.ReplaceAllString(text, "$1")
+
+		opening, closing := strings.Count(text, "<i>"), strings.Count(text, "</i>")
+		for ; opening > closing; opening-- {
+			if strings.HasSuffix(text, "<i>") {
+				text = strings.TrimSuffix(text, "<i>")
+			} else {
+				text += "</i>"
+			}
+		}
+		for ; closing > opening; closing-- {
+			if strings.HasPrefix(text, "</i>") {
+				text = strings.TrimPrefix(text, "</i>")
+			} else {
+				text = "<i>" + text
+			}
+		}
+
+		return text
+	})
+}
+
+func (f *FixInvalidItalicTags) Name() string {
+	return "Fix invalid italic tags"
+}
+
+// FixMissingSpaces adds the space missing after punctuation between words
+type FixMissingSpaces struct{}
+
+func (f *FixMissingSpaces) Find(subtitle *common.Subtitle) []Fix {
+	return FindTextFixes(subtitle, f.Name(), func(text string) string {
+		return mapOutsideTags(text, func(part string) string {
+			part = missingSpaceRegex.ReplaceAllString(part, "$1$2 $3")
+
+			return missingSpaceAfterPeriod.ReplaceAllString(part, "$1. $2")
+		})
+	})
+}
+
+func (f *FixMissingSpaces) Name() string {
+	return "Fix missing spaces"
+}
+
+// FixShortLines merges the lines of paragraphs that are not dialogs when they fit on one line
+type FixShortLines struct {
+	MaxLineLength int
+}
+
+func (f *FixShortLines) Find(subtitle *common.Subtitle) []Fix {
+	return FindTextFixes(subtitle, f.Name(), func(text string) string {
+		lines := strings.Split(text, "\n")
+		if len(lines) < 2 {
+			return text
+		}
+		for _, line := range lines {
+			if _, hasDash, _ := SplitDialogDash(line); hasDash {
+				return text
+			}
+		}
+
+		merged := strings.ReplaceAll(text, "</i>\n<i>", " ")
+		merged = strings.Join(strings.Fields(strings.ReplaceAll(merged, "\n", " ")), " ")
+		if utf8.RuneCountInString(TagRegex.ReplaceAllString(merged, "")) > f.MaxLineLength {
+			return text
+		}
+
+		return merged
+	})
+}
+
+func (f *FixShortLines) Name() string {
+	return "Merge short lines"
+}
+
+// FixUnneededPeriods removes periods after exclamation and question marks, and the second of two periods
+type FixUnneededPeriods struct{}
+
+func (f *FixUnneededPeriods) Find(subtitle *common.Subtitle) []Fix {
+	return FindTextFixes(subtitle, f.Name(), func(text string) string {
+		text = periodAfterMarkRegex.ReplaceAllString(text, "$1$2")
+
+		return doublePeriodRegex.ReplaceAllString(text, "$1.$2")
+	})
+}
+
+func (f *FixUnneededPeriods) Name() string {
+	return "Remove unneeded periods"
+}
+
+// FixUnneededSpaces removes double spaces, spaces at the start and end of lines and spaces before commas
+type FixUnneededSpaces struct{}
+
+func (f *FixUnneededSpaces) Find(subtitle *common.Subtitle) []Fix {
+	return FindTextFixes(subtitle, f.Name(), func(text string) string {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			line = multipleSpacesRegex.ReplaceAllString(line, " ")
+			line = spaceBeforeCommaRegex.ReplaceAllString(line, "$1,")
+			line = strings.TrimSpace(line)
+
+			//Spaces just inside italics at the start and end of a line
+			if strings.HasPrefix(line, "<i> ") {
+				line = "<i>" + strings.TrimLeft(line[len("<i>"):], " ")
+			}
+			if strings.HasSuffix(line, " </i>") {
+				line = strings.TrimSuffix(line, " </i>") + "</i>"
+			}
+			lines[i] = line
+		}
+
+		return strings.Join(lines, "\n")
+	})
+}
+
+func (f *FixUnneededSpaces) Name() string {
+	return "Remove unneeded spaces"
+}
+
+// SplitDialogDash splits a line into its leading formatting tags, whether a dialog dash follows them, and the text
+// after the dash and its spaces
+func SplitDialogDash(line string) (string, bool, string) {
+	tags := leadingTagsRegex.FindString(line)
+	rest := line[len(tags):]
+
+	first, size := utf8.DecodeRuneInString(rest)
+	if size == 0 || !strings.ContainsRune(DialogDashes, first) {
+		return tags, false, rest
+	}
+
+	return tags, true, strings.TrimLeft(rest[size:], " ")
+}
+
+// mapOutsideTags changes the text between formatting tags, leaving the tags as they are
+func mapOutsideTags(text string, mapText func(part string) string) string {
+	builder := strings.Builder{}
+
+	for len(text) > 0 {
+		index := TagRegex.FindStringIndex(text)
+		if index == nil {
+			builder.WriteString(mapText(text))
+
+			break
+		}
+
+		builder.WriteString(mapText(text[:index[0]]))
+		builder.WriteString(text[index[0]:index[1]])
+		text = text[index[1]:]
+	}
+
+	return builder.String()
+}
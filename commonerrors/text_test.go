@@ -0,0 +1,160 @@
+package commonerrors
+
+import (
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+type textFixTest struct {
+	input     string
+	expected  string
+	isRemoval bool
+}
+
+// checkTextFixes runs a fixer on a paragraph of each input and checks the text after the fix, an expected text
+// equal to the input means no fix is expected
+func checkTextFixes(t *testing.T, fixer Fixer, tests []textFixTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		subtitle := &common.Subtitle{Paragraphs: []common.Paragraph{*common.NewParagraph(test.input, 1000, 3000)}}
+		subtitle.Renumber(1)
+
+		fixes := fixer.Find(subtitle)
+		if test.expected == test.input && !test.isRemoval {
+			if len(fixes) != 0 {
+				t.Errorf("%s: %q got fix %s, expected none", fixer.Name(), test.input, fixes[0].String())
+			}
+
+			continue
+		}
+		if len(fixes) != 1 {
+			t.Errorf("%s: %q got %d fixes, expected 1", fixer.Name(), test.input, len(fixes))
+
+			continue
+		}
+
+		fix := fixes[0]
+		if fix.After.Text != test.expected || fix.IsRemoval != test.isRemoval {
+			t.Errorf("%s: %q got %q removed %v, expected %q removed %v", fixer.Name(), test.input, fix.After.Text, fix.IsRemoval, test.expected, test.isRemoval)
+		}
+		if fix.Before != subtitle.Paragraphs[0] || fix.Fixer != fixer.Name() || fix.Index != 0 {
+			t.Errorf("%s: %q got fix %s at %d", fixer.Name(), test.input, fix.String(), fix.Index)
+		}
+	}
+}
+
+func TestFixAloneLowercaseI(t *testing.T) {
+	checkTextFixes(t, &FixAloneLowercaseI{}, []textFixTest{
+		{input: "i think i'm fine", expected: "I think I'm fine"},
+		{input: "<i>i know</i>", expected: "<i>I know</i>"},
+		{input: "Yes, i.e. sure", expected: "Yes, i.e. sure"},
+		{input: "The letter 'i", expected: "The letter 'i"},
+		{input: "Wi-Fi and i-pod", expected: "Wi-Fi and i-pod"},
+		{input: "I am", expected: "I am"},
+	})
+}
+
+func TestFixDialogHyphens(t *testing.T) {
+	checkTextFixes(t, &FixDialogHyphens{}, []textFixTest{
+		{input: "–First\n—Second", expected: "- First\n- Second"},
+		{input: "Are you sure?\n-Yes.", expected: "- Are you sure?\n- Yes."},
+		{input: "I think that\n-maybe not", expected: "I think that\n-maybe not"},
+		{input: "- Single line", expected: "Single line"},
+		{input: "<i>- Single line</i>", expected: "<i>Single line</i>"},
+		{input: "<i>-First</i>\n<i>-Second</i>", expected: "<i>- First</i>\n<i>- Second</i>"},
+		{input: "- First\n- Second", expected: "- First\n- Second"},
+		{input: "No dialog\nat all", expected: "No dialog\nat all"},
+	})
+	checkTextFixes(t, &FixDialogHyphens{DashWithoutSpace: true}, []textFixTest{
+		{input: "- First\n- Second", expected: "-First\n-Second"},
+	})
+}
+
+func TestFixEmptyLines(t *testing.T) {
+	checkTextFixes(t, &FixEmptyLines{}, []textFixTest{
+		{input: "First\n\nSecond", expected: "First\nSecond"},
+		{input: "  Text  ", expected: "Text"},
+		{input: "Text\n<i></i>", expected: "Text"},
+		{input: "  \n", expected: "", isRemoval: true},
+		{input: "<i> </i>", expected: "", isRemoval: true},
+		{input: "", expected: "", isRemoval: true},
+		{input: "First\nSecond", expected: "First\nSecond"},
+	})
+}
+
+func TestFixInvalidItalicTags(t *testing.T) {
+	checkTextFixes(t, &FixInvalidItalicTags{}, []textFixTest{
+		{input: "<I>Upper</I>", expected: "<i>Upper</i>"},
+		{input: "<i>unbalanced", expected: "<i>unbalanced</i>"},
+		{input: "unbalanced</i>", expected: "<i>unbalanced</i>"},
+		{input: "Text<i>", expected: "Text"},
+		{input: "</i>Text", expected: "Text"},
+		{input: "<i>I know</i> <i>it</i>", expected: "<i>I know it</i>"},
+		{input: "Text <i> </i>here", expected: "Text here"},
+		{input: "<i>Fine</i>", expected: "<i>Fine</i>"},
+	})
+}
+
+func TestFixMissingSpaces(t *testing.T) {
+	checkTextFixes(t, &FixMissingSpaces{}, []textFixTest{
+		{input: "Hello,world!", expected: "Hello, world!"},
+		{input: "What?Really", expected: "What? Really"},
+		{input: "Mr.Smith is here", expected: "Mr. Smith is here"},
+		{input: "<i>Yes,sir</i>", expected: "<i>Yes, sir</i>"},
+		{input: "Visit www.example.com", expected: "Visit www.example.com"},
+		{input: "U.S.A.", expected: "U.S.A."},
+	})
+}
+
+func TestFixShortLines(t *testing.T) {
+	checkTextFixes(t, &FixShortLines{MaxLineLength: 42}, []textFixTest{
+		{input: "Short line\nanother short", expected: "Short line another short"},
+		{input: "<i>Short line</i>\n<i>in italics</i>", expected: "<i>Short line in italics</i>"},
+		{input: "- Dialog\n- Lines", expected: "- Dialog\n- Lines"},
+		{input: "This first line is rather long\nand so is the second", expected: "This first line is rather long\nand so is the second"},
+		{input: "One line", expected: "One line"},
+	})
+}
+
+func TestFixUnneededPeriods(t *testing.T) {
+	checkTextFixes(t, &FixUnneededPeriods{}, []textFixTest{
+		{input: "Really?.", expected: "Really?"},
+		{input: "Stop!. Now", expected: "Stop! Now"},
+		{input: "Done..", expected: "Done."},
+		{input: "Wait...", expected: "Wait..."},
+		{input: "Fine.", expected: "Fine."},
+	})
+}
+
+func TestFixUnneededSpaces(t *testing.T) {
+	checkTextFixes(t, &FixUnneededSpaces{}, []textFixTest{
+		{input: "Two  spaces", expected: "Two spaces"},
+		{input: "Fine  , ok", expected: "Fine, ok"},
+		{input: " Padded \n lines ", expected: "Padded\nlines"},
+		{input: "<i> Italic </i>", expected: "<i>Italic</i>"},
+		{input: "Fine", expected: "Fine"},
+	})
+}
+
+func TestSplitDialogDash(t *testing.T) {
+	tests := []struct {
+		line    string
+		tags    string
+		hasDash bool
+		rest    string
+	}{
+		{line: "- Text", tags: "", hasDash: true, rest: "Text"},
+		{line: "<i><b>—Text", tags: "<i><b>", hasDash: true, rest: "Text"},
+		{line: "<i>Text", tags: "<i>", hasDash: false, rest: "Text"},
+		{line: "", tags: "", hasDash: false, rest: ""},
+	}
+
+	for _, test := range tests {
+		tags, hasDash, rest := SplitDialogDash(test.line)
+		if tags != test.tags || hasDash != test.hasDash || rest != test.rest {
+			t.Errorf("%q: got %q %v %q, expected %q %v %q", test.line, tags, hasDash, rest, test.tags, test.hasDash, test.rest)
+		}
+	}
+}
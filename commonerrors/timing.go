@@ -0,0 +1,113 @@
+package commonerrors
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/ristryder/gse/common"
+)
+
+// TagRegex matches a formatting tag of paragraph text
+var TagRegex = regexp.MustCompile(`<[^<>]*>`)
+
+// FixLongDisplayTimes shortens paragraphs shown longer than the maximum duration in milliseconds
+type FixLongDisplayTimes struct {
+	MaxDuration float64
+}
+
+func (f *FixLongDisplayTimes) Find(subtitle *common.Subtitle) []Fix {
+	fixes := []Fix{}
+
+	for i, paragraph := range subtitle.Paragraphs {
+		if f.MaxDuration <= 0 || paragraph.Duration().TotalMilliseconds <= f.MaxDuration {
+			continue
+		}
+
+		after := paragraph
+		after.EndTime.TotalMilliseconds = paragraph.StartTime.TotalMilliseconds + f.MaxDuration
+		fixes = append(fixes, Fix{After: after, Before: paragraph, Fixer: f.Name(), Index: i})
+	}
+
+	return fixes
+}
+
+func (f *FixLongDisplayTimes) Name() string {
+	return "Fix long display times"
+}
+
+// FixOverlappingDisplayTimes ends paragraphs the minimum gap in milliseconds before the next one starts, the
+// paragraphs are expected to be sorted by start time
+type FixOverlappingDisplayTimes struct {
+	MinGap float64
+}
+
+func (f *FixOverlappingDisplayTimes) Find(subtitle *common.Subtitle) []Fix {
+	fixes := []Fix{}
+
+	for i := 0; i+1 < len(subtitle.Paragraphs); i++ {
+		paragraph, next := subtitle.Paragraphs[i], subtitle.Paragraphs[i+1]
+		if paragraph.EndTime.TotalMilliseconds <= next.StartTime.TotalMilliseconds {
+			continue
+		}
+
+		//Paragraphs starting together cannot be fixed by moving the end
+		end := next.StartTime.TotalMilliseconds - f.MinGap
+		if end <= paragraph.StartTime.TotalMilliseconds {
+			end = next.StartTime.TotalMilliseconds
+		}
+		if end <= paragraph.StartTime.TotalMilliseconds {
+			continue
+		}
+
+		after := paragraph
+		after.EndTime.TotalMilliseconds = end
+		fixes = append(fixes, Fix{After: after, Before: paragraph, Fixer: f.Name(), Index: i})
+	}
+
+	return fixes
+}
+
+func (f *FixOverlappingDisplayTimes) Name() string {
+	return "Fix overlapping display times"
+}
+
+// FixShortDisplayTimes lengthens paragraphs shown shorter than the minimum duration in milliseconds or too short
+// to read at the maximum reading speed, without ending less than the minimum gap before the next paragraph
+type FixShortDisplayTimes struct {
+	MaxCharactersPerSecond float64
+	MinDuration            float64
+	MinGap                 float64
+}
+
+func (f *FixShortDisplayTimes) Find(subtitle *common.Subtitle) []Fix {
+	fixes := []Fix{}
+
+	for i, paragraph := range subtitle.Paragraphs {
+		duration := f.MinDuration
+		if f.MaxCharactersPerSecond > 0 {
+			characters := utf8.RuneCountInString(TagRegex.ReplaceAllString(paragraph.Text, ""))
+			duration = max(duration, float64(characters)*1000/f.MaxCharactersPerSecond)
+		}
+		if paragraph.Duration().TotalMilliseconds >= duration {
+			continue
+		}
+
+		end := paragraph.StartTime.TotalMilliseconds + duration
+		if i+1 < len(subtitle.Paragraphs) {
+			end = min(end, subtitle.Paragraphs[i+1].StartTime.TotalMilliseconds-f.MinGap)
+		}
+		if end <= paragraph.EndTime.TotalMilliseconds {
+			continue
+		}
+
+		after := paragraph
+		after.EndTime.TotalMilliseconds = end
+		fixes = append(fixes, Fix{After: after, Before: paragraph, Fixer: f.Name(), Index: i})
+	}
+
+	return fixes
+}
+
+func (f *FixShortDisplayTimes) Name() string {
+	return "Fix short display times"
+}
@@ -0,0 +1,76 @@
+package commonerrors
+
+import (
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+type timingFixTest struct {
+	name string
+	//paragraphs are the start and end of each paragraph, texts their text where it matters
+	paragraphs [][2]float64
+	texts      []string
+	//expected are the index and end of each fix
+	expected [][2]float64
+}
+
+func checkTimingFixes(t *testing.T, fixer Fixer, tests []timingFixTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		subtitle := &common.Subtitle{}
+		for i, times := range test.paragraphs {
+			text := "Text"
+			if i < len(test.texts) {
+				text = test.texts[i]
+			}
+			subtitle.Paragraphs = append(subtitle.Paragraphs, *common.NewParagraph(text, times[0], times[1]))
+		}
+
+		fixes := fixer.Find(subtitle)
+		if len(fixes) != len(test.expected) {
+			t.Errorf("%s: got %d fixes, expected %d", test.name, len(fixes), len(test.expected))
+
+			continue
+		}
+		for i, fix := range fixes {
+			if float64(fix.Index) != test.expected[i][0] || fix.After.EndTime.TotalMilliseconds != test.expected[i][1] ||
+				fix.After.StartTime != fix.Before.StartTime {
+				t.Errorf("%s: got fix %s at %d, expected end %v at %v", test.name, fix.String(), fix.Index, test.expected[i][1], test.expected[i][0])
+			}
+		}
+	}
+}
+
+func TestFixLongDisplayTimes(t *testing.T) {
+	checkTimingFixes(t, &FixLongDisplayTimes{MaxDuration: 7000}, []timingFixTest{
+		{name: "too long", paragraphs: [][2]float64{{1000, 9000}, {10000, 17000}}, expected: [][2]float64{{0, 8000}}},
+	})
+	checkTimingFixes(t, &FixLongDisplayTimes{}, []timingFixTest{
+		{name: "no maximum", paragraphs: [][2]float64{{1000, 90000}}, expected: [][2]float64{}},
+	})
+}
+
+func TestFixOverlappingDisplayTimes(t *testing.T) {
+	checkTimingFixes(t, &FixOverlappingDisplayTimes{MinGap: 83}, []timingFixTest{
+		{name: "overlap", paragraphs: [][2]float64{{1000, 3000}, {2000, 4000}, {4000, 5000}}, expected: [][2]float64{{0, 1917}}},
+		{name: "gap does not fit", paragraphs: [][2]float64{{1000, 3000}, {1050, 4000}}, expected: [][2]float64{{0, 1050}}},
+		{name: "same start", paragraphs: [][2]float64{{1000, 3000}, {1000, 4000}}, expected: [][2]float64{}},
+	})
+}
+
+func TestFixShortDisplayTimes(t *testing.T) {
+	checkTimingFixes(t, &FixShortDisplayTimes{MaxCharactersPerSecond: 20, MinDuration: 833, MinGap: 83}, []timingFixTest{
+		{name: "minimum duration", paragraphs: [][2]float64{{1000, 1500}}, expected: [][2]float64{{0, 1833}}},
+		{
+			name:       "reading speed",
+			paragraphs: [][2]float64{{1000, 2000}},
+			texts:      []string{"<i>Thirty-six characters to be read now</i>"},
+			expected:   [][2]float64{{0, 2800}},
+		},
+		{name: "next paragraph", paragraphs: [][2]float64{{1000, 1500}, {1700, 3000}}, expected: [][2]float64{{0, 1617}}},
+		{name: "no room", paragraphs: [][2]float64{{1000, 1500}, {1550, 3000}}, expected: [][2]float64{}},
+		{name: "long enough", paragraphs: [][2]float64{{1000, 2000}}, expected: [][2]float64{}},
+	})
+}
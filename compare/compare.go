@@ -0,0 +1,157 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/ristryder/gse/common"
+)
+
+// Options are how paragraphs of two subtitles are aligned and when they count as changed
+type Options struct {
+	//MinSimilarity is how alike, from 0 to 1, the words of overlapping paragraphs with different times must be for
+	//them to be aligned as one changed paragraph instead of a removed and an added one
+	MinSimilarity float64
+	//TimeTolerance is the timing jitter in milliseconds, starts and ends moved less than it are not retimed
+	TimeTolerance float64
+}
+
+// NewOptions returns options tolerating a frame of jitter at 25 frames per second and aligning paragraphs sharing
+// half of their words
+func NewOptions() *Options {
+	return &Options{
+		MinSimilarity: 0.5,
+		TimeTolerance: 40,
+	}
+}
+
+func (o *Options) String() string {
+	return fmt.Sprintf("MinSimilarity: %v , TimeTolerance: %v", o.MinSimilarity, o.TimeTolerance)
+}
+
+// Compare aligns the paragraphs of an old and a new subtitle by time and text similarity, keeping them in order of
+// start time, and returns the paragraphs that were added, removed, retimed or whose text changed. Paragraphs with
+// the same text are aligned however far they moved
+func (o *Options) Compare(oldSubtitle *common.Subtitle, newSubtitle *common.Subtitle) *Result {
+	oldParagraphs, newParagraphs := sortedParagraphs(oldSubtitle), sortedParagraphs(newSubtitle)
+	scores := o.alignmentScores(oldParagraphs, newParagraphs)
+	result := &Result{Changes: []Change{}}
+
+	i, j := 0, 0
+	for i < len(oldParagraphs) || j < len(newParagraphs) {
+		if i < len(oldParagraphs) && j < len(newParagraphs) {
+			score := o.matchScore(&oldParagraphs[i], &newParagraphs[j])
+			if score > 0 && scores[i][j] == scores[i+1][j+1]+score {
+				if change := o.changeOf(&oldParagraphs[i], &newParagraphs[j]); change != nil {
+					result.Changes = append(result.Changes, *change)
+				}
+				i++
+				j++
+
+				continue
+			}
+		}
+
+		//Unaligned paragraphs are reported in order of start time when either can be skipped
+		canRemove := i < len(oldParagraphs) && scores[i][j] == scores[i+1][j]
+		canAdd := j < len(newParagraphs) && scores[i][j] == scores[i][j+1]
+		if canRemove && (!canAdd || oldParagraphs[i].StartTime.TotalMilliseconds <= newParagraphs[j].StartTime.TotalMilliseconds) {
+			result.Changes = append(result.Changes, Change{Before: &oldParagraphs[i], Type: ChangeRemoved})
+			i++
+		} else {
+			result.Changes = append(result.Changes, Change{After: &newParagraphs[j], Type: ChangeAdded})
+			j++
+		}
+	}
+
+	return result
+}
+
+// alignmentScores returns the best total match score of every pair of suffixes of the paragraphs
+func (o *Options) alignmentScores(oldParagraphs []common.Paragraph, newParagraphs []common.Paragraph) [][]float64 {
+	scores := make([][]float64, len(oldParagraphs)+1)
+	for i := range scores {
+		scores[i] = make([]float64, len(newParagraphs)+1)
+	}
+
+	for i := len(oldParagraphs) - 1; i >= 0; i-- {
+		for j := len(newParagraphs) - 1; j >= 0; j-- {
+			scores[i][j] = max(scores[i+1][j], scores[i][j+1])
+			if score := o.matchScore(&oldParagraphs[i], &newParagraphs[j]); score > 0 {
+				scores[i][j] = max(scores[i][j], scores[i+1][j+1]+score)
+			}
+		}
+	}
+
+	return scores
+}
+
+// changeOf returns the change between two aligned paragraphs, nil if they are the same within the tolerance
+func (o *Options) changeOf(before *common.Paragraph, after *common.Paragraph) *Change {
+	change := &Change{
+		After:       after,
+		Before:      before,
+		EndOffset:   after.EndTime.TotalMilliseconds - before.EndTime.TotalMilliseconds,
+		StartOffset: after.StartTime.TotalMilliseconds - before.StartTime.TotalMilliseconds,
+		Type:        ChangeChanged,
+	}
+	change.IsRetimed = !o.isSameTime(before, after)
+	change.IsTextChanged = normalizeText(before.Text) != normalizeText(after.Text)
+
+	if !change.IsRetimed && !change.IsTextChanged {
+		return nil
+	}
+	if !change.IsRetimed {
+		change.EndOffset, change.StartOffset = 0, 0
+	}
+	if change.IsTextChanged {
+		change.Words = DiffWords(before.Text, after.Text)
+	}
+
+	return change
+}
+
+func (o *Options) isSameTime(before *common.Paragraph, after *common.Paragraph) bool {
+	return math.Abs(after.StartTime.TotalMilliseconds-before.StartTime.TotalMilliseconds) <= o.TimeTolerance &&
+		math.Abs(after.EndTime.TotalMilliseconds-before.EndTime.TotalMilliseconds) <= o.TimeTolerance
+}
+
+// matchScore returns how well two paragraphs align, 0 if they cannot be aligned. Overlapping paragraphs align when
+// their words are similar enough or they have the same times, other paragraphs only when they have the same text
+func (o *Options) matchScore(before *common.Paragraph, after *common.Paragraph) float64 {
+	overlaps := min(before.EndTime.TotalMilliseconds, after.EndTime.TotalMilliseconds)+o.TimeTolerance >
+		max(before.StartTime.TotalMilliseconds, after.StartTime.TotalMilliseconds)
+	if !overlaps {
+		if normalizeText(before.Text) == normalizeText(after.Text) {
+			return 1
+		}
+
+		return 0
+	}
+
+	score := similarity(before.Text, after.Text)
+	sameTime := o.isSameTime(before, after)
+	if score < o.MinSimilarity && !sameTime {
+		return 0
+	}
+
+	score += 2
+	if sameTime {
+		score++
+	}
+
+	return score
+}
+
+func normalizeText(text string) string {
+	return strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+}
+
+func sortedParagraphs(subtitle *common.Subtitle) []common.Paragraph {
+	sorted := &common.Subtitle{Paragraphs: slices.Clone(subtitle.Paragraphs)}
+	sorted.SortByStartTime()
+
+	return sorted.Paragraphs
+}
@@ -0,0 +1,135 @@
+package compare
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+type expectedChange struct {
+	after         int
+	before        int
+	changeType    ChangeType
+	endOffset     float64
+	isRetimed     bool
+	isTextChanged bool
+	startOffset   float64
+	words         string
+}
+
+func subtitleOf(paragraphs ...*common.Paragraph) *common.Subtitle {
+	subtitle := &common.Subtitle{}
+	for _, paragraph := range paragraphs {
+		subtitle.Paragraphs = append(subtitle.Paragraphs, *paragraph)
+	}
+	subtitle.Renumber(1)
+
+	return subtitle
+}
+
+func checkChanges(t *testing.T, name string, result *Result, expected []expectedChange) {
+	t.Helper()
+
+	if len(result.Changes) != len(expected) {
+		for _, change := range result.Changes {
+			t.Log(change.String())
+		}
+		t.Fatalf("%s: got %d changes, expected %d", name, len(result.Changes), len(expected))
+	}
+
+	for i, change := range result.Changes {
+		before, after := 0, 0
+		if change.Before != nil {
+			before = change.Before.Number
+		}
+		if change.After != nil {
+			after = change.After.Number
+		}
+
+		if change.Type != expected[i].changeType || before != expected[i].before || after != expected[i].after ||
+			change.IsRetimed != expected[i].isRetimed || change.IsTextChanged != expected[i].isTextChanged ||
+			change.StartOffset != expected[i].startOffset || change.EndOffset != expected[i].endOffset ||
+			FormatWordChanges(change.Words) != expected[i].words {
+			t.Errorf("%s: change %d is %s, expected %+v", name, i, change.String(), expected[i])
+		}
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	oldSubtitle := subtitleOf(common.NewParagraph("First", 1000, 2000), common.NewParagraph("Second\nline", 3000, 4000))
+	//Jitter within the tolerance and different line breaks are not changes
+	newSubtitle := subtitleOf(common.NewParagraph("First", 1040, 1960), common.NewParagraph("Second\r\nline", 3000, 4000))
+
+	result := NewOptions().Compare(oldSubtitle, newSubtitle)
+	if !result.IsEqual() {
+		checkChanges(t, "equal", result, []expectedChange{})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	oldSubtitle := subtitleOf(
+		common.NewParagraph("Removed line", 1000, 2000),
+		common.NewParagraph("The quick brown fox", 3000, 4000),
+		common.NewParagraph("Moved later", 5000, 6000),
+		common.NewParagraph("Both time and words change", 9000, 10000),
+		common.NewParagraph("Same text far away", 11000, 12000),
+	)
+	newSubtitle := subtitleOf(
+		common.NewParagraph("The quick red fox", 3000, 4000),
+		common.NewParagraph("Moved later", 5500, 6200),
+		common.NewParagraph("Added line", 6500, 6900),
+		common.NewParagraph("Both time and words changed", 9200, 10000),
+		common.NewParagraph("Same text far away", 20000, 21000),
+	)
+
+	result := NewOptions().Compare(oldSubtitle, newSubtitle)
+	checkChanges(t, "compare", result, []expectedChange{
+		{before: 1, changeType: ChangeRemoved},
+		{after: 1, before: 2, changeType: ChangeChanged, isTextChanged: true, words: "The quick [-brown-] {+red+} fox"},
+		{after: 2, before: 3, changeType: ChangeChanged, endOffset: 200, isRetimed: true, startOffset: 500},
+		{after: 3, changeType: ChangeAdded},
+		{after: 4, before: 4, changeType: ChangeChanged, isRetimed: true, isTextChanged: true, startOffset: 200, words: "Both time and words [-change-] {+changed+}"},
+		{after: 5, before: 5, changeType: ChangeChanged, endOffset: 9000, isRetimed: true, startOffset: 9000},
+	})
+
+	added, removed, retimed, textChanged := result.Counts()
+	if added != 1 || removed != 1 || retimed != 3 || textChanged != 2 {
+		t.Errorf("got counts %d %d %d %d, expected 1 1 3 2", added, removed, retimed, textChanged)
+	}
+}
+
+func TestCompareDissimilarOverlap(t *testing.T) {
+	oldSubtitle := subtitleOf(common.NewParagraph("Completely different words", 1000, 3000))
+	newSubtitle := subtitleOf(common.NewParagraph("Nothing in common here", 1500, 3500))
+
+	checkChanges(t, "dissimilar", NewOptions().Compare(oldSubtitle, newSubtitle), []expectedChange{
+		{before: 1, changeType: ChangeRemoved},
+		{after: 1, changeType: ChangeAdded},
+	})
+
+	//Paragraphs with the same times are aligned whatever their words
+	newSubtitle = &common.Subtitle{Paragraphs: []common.Paragraph{*common.NewParagraph("Nothing in common here", 1000, 3000)}}
+	newSubtitle.Renumber(1)
+	checkChanges(t, "same times", NewOptions().Compare(oldSubtitle, newSubtitle), []expectedChange{
+		{after: 1, before: 1, changeType: ChangeChanged, isTextChanged: true, words: "[-Completely different words-] {+Nothing in common here+}"},
+	})
+}
+
+func TestResultJson(t *testing.T) {
+	oldSubtitle := subtitleOf(common.NewParagraph("Old", 1000, 2000))
+	newSubtitle := subtitleOf(common.NewParagraph("Old", 1000, 2000), common.NewParagraph("New", 3000, 4000))
+
+	data, jsonErr := NewOptions().Compare(oldSubtitle, newSubtitle).Json()
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	result := Result{}
+	if unmarshalErr := json.Unmarshal(data, &result); unmarshalErr != nil {
+		t.Fatal(unmarshalErr)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Type != ChangeAdded || result.Changes[0].Before != nil || result.Changes[0].After.Text != "New" {
+		t.Errorf("got %s", data)
+	}
+}
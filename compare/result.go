@@ -0,0 +1,84 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ristryder/gse/common"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeChanged ChangeType = "changed"
+	ChangeRemoved ChangeType = "removed"
+)
+
+// Change is a paragraph only in the new subtitle, only in the old subtitle, or aligned between both with a different
+// time or text
+type Change struct {
+	//After is the paragraph of the new subtitle, nil for removed paragraphs
+	After *common.Paragraph `json:"after,omitempty"`
+	//Before is the paragraph of the old subtitle, nil for added paragraphs
+	Before *common.Paragraph `json:"before,omitempty"`
+	//EndOffset is how much later in milliseconds the paragraph ends in the new subtitle
+	EndOffset     float64 `json:"endOffset,omitempty"`
+	IsRetimed     bool    `json:"isRetimed,omitempty"`
+	IsTextChanged bool    `json:"isTextChanged,omitempty"`
+	//StartOffset is how much later in milliseconds the paragraph starts in the new subtitle
+	StartOffset float64    `json:"startOffset,omitempty"`
+	Type        ChangeType `json:"type"`
+	//Words is the word diff of changed texts, which is all equal words when only line breaks changed
+	Words []WordChange `json:"words,omitempty"`
+}
+
+func (c *Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("Type: %v , Number: %v , Time: %v --> %v , Text: %q", c.Type, c.After.Number, c.After.StartTime.String(), c.After.EndTime.String(), c.After.Text)
+	case ChangeRemoved:
+		return fmt.Sprintf("Type: %v , Number: %v , Time: %v --> %v , Text: %q", c.Type, c.Before.Number, c.Before.StartTime.String(), c.Before.EndTime.String(), c.Before.Text)
+	}
+
+	return fmt.Sprintf("Type: %v , Before: %v , After: %v , IsRetimed: %v , StartOffset: %v , EndOffset: %v , IsTextChanged: %v , Words: %q", c.Type, c.Before.Number, c.After.Number, c.IsRetimed, c.StartOffset, c.EndOffset, c.IsTextChanged, FormatWordChanges(c.Words))
+}
+
+// Result is the changes between two subtitles, in the order of the paragraphs
+type Result struct {
+	Changes []Change `json:"changes"`
+}
+
+// Counts returns the number of added, removed, retimed and text changed paragraphs, a paragraph that is retimed and
+// has a changed text is counted as both
+func (r *Result) Counts() (int, int, int, int) {
+	added, removed, retimed, textChanged := 0, 0, 0, 0
+
+	for _, change := range r.Changes {
+		switch {
+		case change.Type == ChangeAdded:
+			added++
+		case change.Type == ChangeRemoved:
+			removed++
+		default:
+			if change.IsRetimed {
+				retimed++
+			}
+			if change.IsTextChanged {
+				textChanged++
+			}
+		}
+	}
+
+	return added, removed, retimed, textChanged
+}
+
+// IsEqual reports whether the subtitles have the same paragraphs within the timing tolerance
+func (r *Result) IsEqual() bool {
+	return len(r.Changes) == 0
+}
+
+// Json returns the result as JSON
+func (r *Result) Json() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
@@ -0,0 +1,109 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Operation string
+
+const (
+	OperationDelete Operation = "delete"
+	OperationEqual  Operation = "equal"
+	OperationInsert Operation = "insert"
+)
+
+// WordChange is a run of words that is in both texts, only in the old text or only in the new text
+type WordChange struct {
+	Operation Operation `json:"operation"`
+	//Text is the words of the run separated by single spaces
+	Text string `json:"text"`
+}
+
+func (w *WordChange) String() string {
+	return fmt.Sprintf("Operation: %v , Text: %q", w.Operation, w.Text)
+}
+
+// DiffWords returns the changes turning the words of one text into the words of another, line breaks and repeated
+// spaces are not compared
+func DiffWords(oldText string, newText string) []WordChange {
+	oldWords, newWords := strings.Fields(oldText), strings.Fields(newText)
+	lengths := commonLengths(oldWords, newWords)
+	changes := []WordChange{}
+
+	i, j := 0, 0
+	for i < len(oldWords) || j < len(newWords) {
+		switch {
+		case i < len(oldWords) && j < len(newWords) && oldWords[i] == newWords[j]:
+			changes = addWord(changes, OperationEqual, oldWords[i])
+			i++
+			j++
+		case i < len(oldWords) && (j == len(newWords) || lengths[i+1][j] >= lengths[i][j+1]):
+			changes = addWord(changes, OperationDelete, oldWords[i])
+			i++
+		default:
+			changes = addWord(changes, OperationInsert, newWords[j])
+			j++
+		}
+	}
+
+	return changes
+}
+
+// FormatWordChanges writes word changes as text, with deleted words in [- -] and inserted words in {+ +}
+func FormatWordChanges(changes []WordChange) string {
+	parts := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		switch change.Operation {
+		case OperationDelete:
+			parts = append(parts, "[-"+change.Text+"-]")
+		case OperationEqual:
+			parts = append(parts, change.Text)
+		case OperationInsert:
+			parts = append(parts, "{+"+change.Text+"+}")
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func addWord(changes []WordChange, operation Operation, word string) []WordChange {
+	if len(changes) > 0 && changes[len(changes)-1].Operation == operation {
+		changes[len(changes)-1].Text += " " + word
+
+		return changes
+	}
+
+	return append(changes, WordChange{Operation: operation, Text: word})
+}
+
+// commonLengths returns the lengths of the longest common subsequences of every pair of suffixes of the words
+func commonLengths(oldWords []string, newWords []string) [][]int {
+	lengths := make([][]int, len(oldWords)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(newWords)+1)
+	}
+
+	for i := len(oldWords) - 1; i >= 0; i-- {
+		for j := len(newWords) - 1; j >= 0; j-- {
+			if oldWords[i] == newWords[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	return lengths
+}
+
+// similarity returns how alike the words of two texts are, from 0 for no common words to 1 for the same words
+func similarity(oldText string, newText string) float64 {
+	oldWords, newWords := strings.Fields(oldText), strings.Fields(newText)
+	if len(oldWords)+len(newWords) == 0 {
+		return 1
+	}
+
+	return 2 * float64(commonLengths(oldWords, newWords)[0][0]) / float64(len(oldWords)+len(newWords))
+}
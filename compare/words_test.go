@@ -0,0 +1,63 @@
+package compare
+
+import (
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{oldText: "The quick brown fox", newText: "The quick red fox", expected: "The quick [-brown-] {+red+} fox"},
+		{oldText: "Line one\nline two", newText: "Line one  line two", expected: "Line one line two"},
+		{oldText: "", newText: "New words", expected: "{+New words+}"},
+		{oldText: "Old words", newText: "", expected: "[-Old words-]"},
+		{oldText: "a b c", newText: "a c d", expected: "a [-b-] c {+d+}"},
+	}
+
+	for _, test := range tests {
+		if formatted := FormatWordChanges(DiffWords(test.oldText, test.newText)); formatted != test.expected {
+			t.Errorf("%q to %q: got %q, expected %q", test.oldText, test.newText, formatted, test.expected)
+		}
+	}
+}
+
+func TestDiffWordsRuns(t *testing.T) {
+	changes := DiffWords("one two three four", "one five six four")
+
+	expected := []WordChange{
+		{Operation: OperationEqual, Text: "one"},
+		{Operation: OperationDelete, Text: "two three"},
+		{Operation: OperationInsert, Text: "five six"},
+		{Operation: OperationEqual, Text: "four"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("got %v, expected %v", changes, expected)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("change %d: got %s, expected %s", i, changes[i].String(), expected[i].String())
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		oldText  string
+		newText  string
+		expected float64
+	}{
+		{oldText: "a b c d", newText: "a b c d", expected: 1},
+		{oldText: "a b c d", newText: "a b x y", expected: 0.5},
+		{oldText: "a b", newText: "c d", expected: 0},
+		{oldText: "", newText: " ", expected: 1},
+	}
+
+	for _, test := range tests {
+		if score := similarity(test.oldText, test.newText); score != test.expected {
+			t.Errorf("%q and %q: got %v, expected %v", test.oldText, test.newText, score, test.expected)
+		}
+	}
+}
@@ -21,23 +21,47 @@ const (
 	ElementNone ElementId = 0
 
 	ElementEbml    ElementId = 0x1A45DFA3
+	ElementDocType ElementId = 0x4282
 	ElementSegment ElementId = 0x18538067
+	ElementVoid    ElementId = 0xEC
+	ElementCrc32   ElementId = 0xBF
+
+	ElementSeekHead     ElementId = 0x114D9B74
+	ElementSeek         ElementId = 0x4DBB
+	ElementSeekId       ElementId = 0x53AB
+	ElementSeekPosition ElementId = 0x53AC
 
 	ElementInfo          ElementId = 0x1549A966
 	ElementTimecodeScale ElementId = 0x2AD7B1
 	ElementDuration      ElementId = 0x4489
+	ElementDateUtc       ElementId = 0x4461
+	ElementTitle         ElementId = 0x7BA9
+	ElementMuxingApp     ElementId = 0x4D80
+	ElementWritingApp    ElementId = 0x5741
+	ElementSegmentUid    ElementId = 0x73A4
 
 	ElementTracks      ElementId = 0x1654AE6B
 	ElementTrackEntry  ElementId = 0xAE
 	ElementTrackNumber ElementId = 0xD7
+	ElementTrackUid    ElementId = 0x73C5
 	ElementTrackType   ElementId = 0x83
 	ElementFlagDefault ElementId = 0x88
 	ElementFlagForced  ElementId = 0x55AA
+	ElementFlagLacing  ElementId = 0x9C
+
+	ElementFlagEnabled          ElementId = 0xB9
+	ElementFlagHearingImpaired  ElementId = 0x55AB
+	ElementFlagVisualImpaired   ElementId = 0x55AC
+	ElementFlagTextDescriptions ElementId = 0x55AD
+	ElementFlagOriginal         ElementId = 0x55AE
+	ElementFlagCommentary       ElementId = 0x55AF
 
 	ElementDefaultDuration      ElementId = 0x23E383
 	ElementName                 ElementId = 0x536E
 	ElementLanguage             ElementId = 0x22B59C
+	ElementLanguageBcp47        ElementId = 0x22B59D
 	ElementCodecId              ElementId = 0x86
+	ElementCodecName            ElementId = 0x258688
 	ElementCodecPrivate         ElementId = 0x63A2
 	ElementVideo                ElementId = 0xE0
 	ElementPixelWidth           ElementId = 0xB0
@@ -51,6 +75,12 @@ const (
 	ElementContentCompression   ElementId = 0x5034
 	ElementContentCompAlgo      ElementId = 0x4254
 	ElementContentCompSettings  ElementId = 0x4255
+	ElementContentEncryption    ElementId = 0x5035
+
+	ElementSamplingFrequency       ElementId = 0xB5
+	ElementOutputSamplingFrequency ElementId = 0x78B5
+	ElementChannels                ElementId = 0x9F
+	ElementBitDepth                ElementId = 0x6264
 
 	ElementCluster       ElementId = 0x1F43B675
 	ElementTimecode      ElementId = 0xE7
@@ -59,6 +89,44 @@ const (
 	ElementBlock         ElementId = 0xA1
 	ElementBlockDuration ElementId = 0x9B
 
+	ElementBlockAdditions  ElementId = 0x75A1
+	ElementBlockMore       ElementId = 0xA6
+	ElementBlockAddId      ElementId = 0xEE
+	ElementBlockAdditional ElementId = 0xA5
+
+	ElementClusterPosition ElementId = 0xA7
+	ElementPrevSize        ElementId = 0xAB
+	ElementReferenceBlock  ElementId = 0xFB
+
+	ElementCues                ElementId = 0x1C53BB6B
+	ElementCuePoint            ElementId = 0xBB
+	ElementCueTime             ElementId = 0xB3
+	ElementCueTrackPositions   ElementId = 0xB7
+	ElementCueTrack            ElementId = 0xF7
+	ElementCueClusterPosition  ElementId = 0xF1
+	ElementCueRelativePosition ElementId = 0xF0
+	ElementCueDuration         ElementId = 0xB2
+	ElementCueBlockNumber      ElementId = 0x5378
+
+	ElementAttachments ElementId = 0x1941A469
+
+	ElementTags             ElementId = 0x1254C367
+	ElementTag              ElementId = 0x7373
+	ElementTargets          ElementId = 0x63C0
+	ElementTargetTypeValue  ElementId = 0x68CA
+	ElementTargetType       ElementId = 0x63CA
+	ElementTagTrackUid      ElementId = 0x63C5
+	ElementTagEditionUid    ElementId = 0x63C9
+	ElementTagChapterUid    ElementId = 0x63C4
+	ElementTagAttachmentUid ElementId = 0x63C6
+	ElementSimpleTag        ElementId = 0x67C8
+	ElementTagName          ElementId = 0x45A3
+	ElementTagLanguage      ElementId = 0x447A
+	ElementTagLanguageBcp47 ElementId = 0x447B
+	ElementTagDefault       ElementId = 0x4484
+	ElementTagString        ElementId = 0x4487
+	ElementTagBinary        ElementId = 0x4485
+
 	ElementChapters         ElementId = 0x1043A770
 	ElementEditionEntry     ElementId = 0x45B9
 	ElementChapterAtom      ElementId = 0xB6
@@ -67,6 +135,9 @@ const (
 	ElementChapString       ElementId = 0x85
 )
 
+// segmentChildIds are the ids of the elements directly below the segment, they end any element of unknown size
+var segmentChildIds = []ElementId{ElementSeekHead, ElementInfo, ElementTracks, ElementCluster, ElementCues, ElementAttachments, ElementChapters, ElementTags}
+
 func (e *Element) EndPosition() int64 {
 	return e.DataPosition + e.DataSize
 }
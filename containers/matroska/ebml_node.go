@@ -0,0 +1,25 @@
+package matroska
+
+import "fmt"
+
+// EbmlNode is an element found while walking an EBML tree with an EbmlReader
+type EbmlNode struct {
+	Element
+
+	Depth          int
+	HeaderPosition int64
+	IsKnown        bool
+	Schema         ElementSchema
+}
+
+func (e *EbmlNode) IsMaster() bool {
+	return e.Schema.Type == ElementTypeMaster
+}
+
+func (e *EbmlNode) Name() string {
+	return e.Schema.Name
+}
+
+func (e *EbmlNode) String() string {
+	return fmt.Sprintf("Name: %v , Id: 0x%X , Type: %v , Position: %v , Size: %v , Depth: %v", e.Schema.Name, uint32(e.Id), e.Schema.Type, e.HeaderPosition, e.DataSize, e.Depth)
+}
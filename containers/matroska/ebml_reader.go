@@ -0,0 +1,220 @@
+package matroska
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"iter"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ristryder/gse/common"
+)
+
+var ErrEbmlElementOverflow = errors.New("EBML element extends past the end of its parent")
+
+// ebmlDateEpoch is the origin of EBML date values, which are stored as nanoseconds
+var ebmlDateEpoch = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// EbmlReader walks the element tree of any EBML file, including Matroska and WebM files. Nothing is validated
+// beyond what is needed to read each element header, so it can be used to inspect malformed files
+type EbmlReader struct {
+	matroskaFile *MatroskaFile
+	ownsFile     bool
+}
+
+func NewEbmlReader(path string) (*EbmlReader, error) {
+	file, openErr := common.NewFileStream(path)
+	if openErr != nil {
+		return nil, errors.Wrapf(openErr, "failed to open EBML file %s", path)
+	}
+
+	return &EbmlReader{matroskaFile: &MatroskaFile{file: file, isOpen: true, Path: path}, ownsFile: true}, nil
+}
+
+// NewEbmlReaderFromReaderAt reads an EBML file of the given size from a reader, closing the EbmlReader does not
+// close the reader
+func NewEbmlReaderFromReaderAt(reader io.ReaderAt, size int64) *EbmlReader {
+	return &EbmlReader{matroskaFile: &MatroskaFile{file: common.NewReaderAtStream(reader, size), isOpen: true}, ownsFile: true}
+}
+
+// NewEbmlReaderFromReadSeeker reads an EBML file from a reader, closing the EbmlReader does not close the reader
+func NewEbmlReaderFromReadSeeker(reader io.ReadSeeker) (*EbmlReader, error) {
+	stream, streamErr := common.NewReadSeekerStream(reader)
+	if streamErr != nil {
+		return nil, errors.Wrap(streamErr, "failed to open EBML file from reader")
+	}
+
+	return &EbmlReader{matroskaFile: &MatroskaFile{file: stream, isOpen: true}, ownsFile: true}, nil
+}
+
+// EbmlReader returns a reader sharing the file of an opened Matroska file, closing it leaves the Matroska file open
+func (m *MatroskaFile) EbmlReader() *EbmlReader {
+	return &EbmlReader{matroskaFile: m, ownsFile: false}
+}
+
+// Children lazily iterates over the elements stored in the payload of a node. An error stops the iteration; when
+// a child overflows its parent, the child is returned together with ErrEbmlElementOverflow
+func (e *EbmlReader) Children(parent EbmlNode) iter.Seq2[EbmlNode, error] {
+	return e.elements(parent.DataPosition, parent.EndPosition(), parent.Depth+1)
+}
+
+func (e *EbmlReader) Close() error {
+	if !e.ownsFile {
+		return nil
+	}
+
+	return e.matroskaFile.file.Close()
+}
+
+// Elements lazily iterates over the top level elements of the file, usually the EBML header and a Segment
+func (e *EbmlReader) Elements() iter.Seq2[EbmlNode, error] {
+	return e.elements(0, e.matroskaFile.file.Size(), 0)
+}
+
+func (e *EbmlReader) elements(startPosition int64, endPosition int64, depth int) iter.Seq2[EbmlNode, error] {
+	return func(yield func(EbmlNode, error) bool) {
+		//Anything past the end of the file was truncated, report the elements that are still there
+		endPosition = min(endPosition, e.matroskaFile.file.Size())
+		position := startPosition
+
+		for position < endPosition {
+			node, nodeErr := e.readNode(position, depth)
+			if nodeErr != nil {
+				yield(EbmlNode{}, nodeErr)
+
+				return
+			}
+
+			if node.EndPosition() > endPosition {
+				yield(node, errors.Wrapf(ErrEbmlElementOverflow, "element 0x%X at position %d", uint32(node.Id), position))
+
+				return
+			}
+
+			if !yield(node, nil) {
+				return
+			}
+
+			position = node.EndPosition()
+		}
+	}
+}
+
+func (e *EbmlReader) readNode(position int64, depth int) (EbmlNode, error) {
+	_, seekErr := e.matroskaFile.file.Seek(position, io.SeekStart)
+	if seekErr != nil {
+		return EbmlNode{}, errors.Wrapf(seekErr, "failed to seek to element at position %d", position)
+	}
+
+	element, elementErr := e.matroskaFile.readElement()
+	if elementErr != nil {
+		return EbmlNode{}, errors.Wrapf(elementErr, "failed to read element header at position %d", position)
+	}
+
+	if element == InvalidElement {
+		return EbmlNode{}, errors.Newf("invalid element id at position %d", position)
+	}
+
+	schema, isKnown := LookupElementSchema(element.Id)
+
+	return EbmlNode{Element: element, Depth: depth, HeaderPosition: position, IsKnown: isKnown, Schema: schema}, nil
+}
+
+func (e *EbmlReader) Size() int64 {
+	return e.matroskaFile.file.Size()
+}
+
+// Value reads the payload of a node and converts it according to its schema type: uint64, int64, float64, string,
+// time.Time or []byte. Master elements have no value and return nil
+func (e *EbmlReader) Value(node EbmlNode) (any, error) {
+	if node.IsMaster() {
+		return nil, nil
+	}
+
+	data, dataErr := e.matroskaFile.readElementData(node.Element)
+	if dataErr != nil {
+		return nil, errors.Wrapf(dataErr, "failed to read value of element %s", node.Name())
+	}
+
+	switch node.Schema.Type {
+	case ElementTypeUnsignedInteger:
+		if len(data) > 8 {
+			return nil, errors.Newf("unsigned integer element %s is %d bytes long", node.Name(), len(data))
+		}
+
+		value := uint64(0)
+		for _, b := range data {
+			value = value<<8 | uint64(b)
+		}
+
+		return value, nil
+	case ElementTypeSignedInteger, ElementTypeDate:
+		if len(data) > 8 {
+			return nil, errors.Newf("integer element %s is %d bytes long", node.Name(), len(data))
+		}
+
+		value := int64(0)
+		if len(data) > 0 && data[0]&0x80 != 0 {
+			value = -1
+		}
+		for _, b := range data {
+			value = value<<8 | int64(b)
+		}
+
+		if node.Schema.Type == ElementTypeDate {
+			return ebmlDateEpoch.Add(time.Duration(value)), nil
+		}
+
+		return value, nil
+	case ElementTypeFloat:
+		switch len(data) {
+		case 0:
+			return float64(0), nil
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+		}
+
+		return nil, errors.Newf("float element %s is %d bytes long", node.Name(), len(data))
+	case ElementTypeString, ElementTypeUtf8:
+		//Strings may be padded with zero bytes
+		if end := bytes.IndexByte(data, 0); end >= 0 {
+			data = data[:end]
+		}
+
+		return string(data), nil
+	}
+
+	return data, nil
+}
+
+// Walk visits every element of the file depth first, visit returns whether the children of a master element
+// should be visited as well
+func (e *EbmlReader) Walk(visit func(node EbmlNode) bool) error {
+	return e.walk(e.Elements(), visit)
+}
+
+func (e *EbmlReader) walk(nodes iter.Seq2[EbmlNode, error], visit func(node EbmlNode) bool) error {
+	for node, nodeErr := range nodes {
+		if nodeErr != nil && !errors.Is(nodeErr, ErrEbmlElementOverflow) {
+			return nodeErr
+		}
+
+		//An overflowing element is still visited so that the damaged part of the file can be seen
+		if visit(node) && node.IsMaster() {
+			childrenErr := e.walk(e.Children(node), visit)
+			if childrenErr != nil {
+				return childrenErr
+			}
+		}
+
+		if nodeErr != nil {
+			return nodeErr
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package matroska
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+const unknownElementId = 0x4ABC
+
+// ebmlReaderSourceFile returns a WebM file with segment information, an element unknown to the schema and a cluster
+func ebmlReaderSourceFile() []byte {
+	return mkvtest.FileOfDocType("webm",
+		mkvtest.Element(uint32(ElementInfo),
+			mkvtest.UInt(uint32(ElementTimecodeScale), 1000000),
+			mkvtest.Float(uint32(ElementDuration), 1500),
+			mkvtest.Element(uint32(ElementDateUtc), binary.BigEndian.AppendUint64(nil, uint64(time.Hour))),
+			mkvtest.String(uint32(ElementTitle), "Title"),
+		),
+		mkvtest.Element(unknownElementId, []byte{1, 2, 3}),
+		mkvtest.Element(uint32(ElementCluster),
+			mkvtest.UInt(uint32(ElementTimecode), 0),
+			mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 0, 0x80, []byte("frame"))),
+		),
+	)
+}
+
+func TestEbmlReaderWalk(t *testing.T) {
+	data := ebmlReaderSourceFile()
+	reader := NewEbmlReaderFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	defer reader.Close()
+
+	visited := []string{}
+	docType := any(nil)
+	walkErr := reader.Walk(func(node EbmlNode) bool {
+		visited = append(visited, fmt.Sprintf("%d %s", node.Depth, node.Name()))
+
+		if node.Id == ElementDocType {
+			value, valueErr := reader.Value(node)
+			if valueErr != nil {
+				t.Fatal(valueErr)
+			}
+			docType = value
+		}
+
+		//The children of the cluster are skipped
+		return node.Id != ElementCluster
+	})
+	if walkErr != nil {
+		t.Fatal(walkErr)
+	}
+
+	expected := []string{
+		"0 EBML", "1 EBMLVersion", "1 EBMLReadVersion", "1 EBMLMaxIDLength", "1 EBMLMaxSizeLength", "1 DocType", "1 DocTypeVersion", "1 DocTypeReadVersion",
+		"0 Segment", "1 Info", "2 TimestampScale", "2 Duration", "2 DateUTC", "2 Title", "1 Unknown (0x4ABC)", "1 Cluster",
+	}
+	if !slices.Equal(visited, expected) {
+		t.Errorf("got %v, expected %v", visited, expected)
+	}
+
+	if docType != "webm" {
+		t.Errorf("got doc type %v, expected webm", docType)
+	}
+}
+
+func TestEbmlReaderChildrenAndValues(t *testing.T) {
+	data := ebmlReaderSourceFile()
+	reader := NewEbmlReaderFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	defer reader.Close()
+
+	segment := EbmlNode{}
+	for node, nodeErr := range reader.Elements() {
+		if nodeErr != nil {
+			t.Fatal(nodeErr)
+		}
+		if node.Id == ElementSegment {
+			segment = node
+		}
+	}
+
+	children := []EbmlNode{}
+	for node, nodeErr := range reader.Children(segment) {
+		if nodeErr != nil {
+			t.Fatal(nodeErr)
+		}
+		children = append(children, node)
+	}
+	if len(children) != 3 {
+		t.Fatalf("got %d children of the segment, expected 3", len(children))
+	}
+
+	//An unknown id is returned as a raw binary element
+	unknown := children[1]
+	if unknown.IsKnown || unknown.Id != unknownElementId || unknown.Schema.Type != ElementTypeBinary || unknown.Depth != 1 {
+		t.Errorf("got %v, expected an unknown binary element", &unknown)
+	}
+	if value, valueErr := reader.Value(unknown); valueErr != nil || !bytes.Equal(value.([]byte), []byte{1, 2, 3}) {
+		t.Errorf("got value %v (%v), expected the raw data", value, valueErr)
+	}
+
+	if value, valueErr := reader.Value(children[0]); valueErr != nil || value != nil {
+		t.Errorf("got value %v (%v) for a master element, expected nil", value, valueErr)
+	}
+
+	values := []any{}
+	for node, nodeErr := range reader.Children(children[0]) {
+		if nodeErr != nil {
+			t.Fatal(nodeErr)
+		}
+
+		value, valueErr := reader.Value(node)
+		if valueErr != nil {
+			t.Fatal(valueErr)
+		}
+		values = append(values, value)
+	}
+
+	expected := []any{uint64(1000000), float64(1500), ebmlDateEpoch.Add(time.Hour), "Title"}
+	if !slices.Equal(values, expected) {
+		t.Errorf("got %v, expected %v", values, expected)
+	}
+}
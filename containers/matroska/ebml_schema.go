@@ -0,0 +1,59 @@
+package matroska
+
+import "fmt"
+
+type ElementType int
+
+const (
+	ElementTypeUnknown ElementType = iota
+	ElementTypeMaster
+	ElementTypeUnsignedInteger
+	ElementTypeSignedInteger
+	ElementTypeFloat
+	ElementTypeString
+	ElementTypeUtf8
+	ElementTypeDate
+	ElementTypeBinary
+)
+
+type ElementSchema struct {
+	Name string
+	Type ElementType
+}
+
+// LookupElementSchema returns the schema of an element id, unknown ids are reported as binary elements
+func LookupElementSchema(id ElementId) (ElementSchema, bool) {
+	schema, found := ElementSchemas[id]
+	if !found {
+		return ElementSchema{Name: fmt.Sprintf("Unknown (0x%X)", uint32(id)), Type: ElementTypeBinary}, false
+	}
+
+	return schema, true
+}
+
+func (e ElementType) String() string {
+	switch e {
+	case ElementTypeMaster:
+		return "master"
+	case ElementTypeUnsignedInteger:
+		return "uinteger"
+	case ElementTypeSignedInteger:
+		return "integer"
+	case ElementTypeFloat:
+		return "float"
+	case ElementTypeString:
+		return "string"
+	case ElementTypeUtf8:
+		return "utf-8"
+	case ElementTypeDate:
+		return "date"
+	case ElementTypeBinary:
+		return "binary"
+	}
+
+	return "unknown"
+}
+
+func (e *ElementSchema) String() string {
+	return fmt.Sprintf("Name: %v , Type: %v", e.Name, e.Type)
+}
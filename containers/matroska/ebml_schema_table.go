@@ -0,0 +1,283 @@
+package matroska
+
+// ElementSchemas holds the name and type of the elements defined by the EBML (RFC 8794) and Matroska (RFC 9559)
+// specifications, including deprecated elements that can still be found in older files.
+//
+// The table is transcribed by hand from the element definitions of RFC 8794 and of ebml_matroska.xml as
+// published with RFC 9559 (October 2024), in the order of those files, with the names and types they use. Elements
+// added by later revisions of ebml_matroska.xml are not in the table and are reported as unknown binary elements.
+// Definitions copied from that version are kept in testdata/ebml_matroska.xml to check the table against.
+var ElementSchemas = map[ElementId]ElementSchema{
+	0x1A45DFA3: {Name: "EBML", Type: ElementTypeMaster},
+	0x4286:     {Name: "EBMLVersion", Type: ElementTypeUnsignedInteger},
+	0x42F7:     {Name: "EBMLReadVersion", Type: ElementTypeUnsignedInteger},
+	0x42F2:     {Name: "EBMLMaxIDLength", Type: ElementTypeUnsignedInteger},
+	0x42F3:     {Name: "EBMLMaxSizeLength", Type: ElementTypeUnsignedInteger},
+	0x4282:     {Name: "DocType", Type: ElementTypeString},
+	0x4287:     {Name: "DocTypeVersion", Type: ElementTypeUnsignedInteger},
+	0x4285:     {Name: "DocTypeReadVersion", Type: ElementTypeUnsignedInteger},
+	0x4281:     {Name: "DocTypeExtension", Type: ElementTypeMaster},
+	0x4283:     {Name: "DocTypeExtensionName", Type: ElementTypeString},
+	0x4284:     {Name: "DocTypeExtensionVersion", Type: ElementTypeUnsignedInteger},
+	0xEC:       {Name: "Void", Type: ElementTypeBinary},
+	0xBF:       {Name: "CRC-32", Type: ElementTypeBinary},
+	0x18538067: {Name: "Segment", Type: ElementTypeMaster},
+	0x114D9B74: {Name: "SeekHead", Type: ElementTypeMaster},
+	0x4DBB:     {Name: "Seek", Type: ElementTypeMaster},
+	0x53AB:     {Name: "SeekID", Type: ElementTypeBinary},
+	0x53AC:     {Name: "SeekPosition", Type: ElementTypeUnsignedInteger},
+	0x1549A966: {Name: "Info", Type: ElementTypeMaster},
+	0x73A4:     {Name: "SegmentUUID", Type: ElementTypeBinary},
+	0x7384:     {Name: "SegmentFilename", Type: ElementTypeUtf8},
+	0x3CB923:   {Name: "PrevUUID", Type: ElementTypeBinary},
+	0x3C83AB:   {Name: "PrevFilename", Type: ElementTypeUtf8},
+	0x3EB923:   {Name: "NextUUID", Type: ElementTypeBinary},
+	0x3E83BB:   {Name: "NextFilename", Type: ElementTypeUtf8},
+	0x4444:     {Name: "SegmentFamily", Type: ElementTypeBinary},
+	0x6924:     {Name: "ChapterTranslate", Type: ElementTypeMaster},
+	0x69A5:     {Name: "ChapterTranslateID", Type: ElementTypeBinary},
+	0x69BF:     {Name: "ChapterTranslateCodec", Type: ElementTypeUnsignedInteger},
+	0x69FC:     {Name: "ChapterTranslateEditionUID", Type: ElementTypeUnsignedInteger},
+	0x2AD7B1:   {Name: "TimestampScale", Type: ElementTypeUnsignedInteger},
+	0x4489:     {Name: "Duration", Type: ElementTypeFloat},
+	0x4461:     {Name: "DateUTC", Type: ElementTypeDate},
+	0x7BA9:     {Name: "Title", Type: ElementTypeUtf8},
+	0x4D80:     {Name: "MuxingApp", Type: ElementTypeUtf8},
+	0x5741:     {Name: "WritingApp", Type: ElementTypeUtf8},
+	0x1F43B675: {Name: "Cluster", Type: ElementTypeMaster},
+	0xE7:       {Name: "Timestamp", Type: ElementTypeUnsignedInteger},
+	0x5854:     {Name: "SilentTracks", Type: ElementTypeMaster},
+	0x58D7:     {Name: "SilentTrackNumber", Type: ElementTypeUnsignedInteger},
+	0xA7:       {Name: "Position", Type: ElementTypeUnsignedInteger},
+	0xAB:       {Name: "PrevSize", Type: ElementTypeUnsignedInteger},
+	0xA3:       {Name: "SimpleBlock", Type: ElementTypeBinary},
+	0xA0:       {Name: "BlockGroup", Type: ElementTypeMaster},
+	0xA1:       {Name: "Block", Type: ElementTypeBinary},
+	0xA2:       {Name: "BlockVirtual", Type: ElementTypeBinary},
+	0x75A1:     {Name: "BlockAdditions", Type: ElementTypeMaster},
+	0xA6:       {Name: "BlockMore", Type: ElementTypeMaster},
+	0xA5:       {Name: "BlockAdditional", Type: ElementTypeBinary},
+	0xEE:       {Name: "BlockAddID", Type: ElementTypeUnsignedInteger},
+	0x9B:       {Name: "BlockDuration", Type: ElementTypeUnsignedInteger},
+	0xFA:       {Name: "ReferencePriority", Type: ElementTypeUnsignedInteger},
+	0xFB:       {Name: "ReferenceBlock", Type: ElementTypeSignedInteger},
+	0xFD:       {Name: "ReferenceVirtual", Type: ElementTypeSignedInteger},
+	0xA4:       {Name: "CodecState", Type: ElementTypeBinary},
+	0x75A2:     {Name: "DiscardPadding", Type: ElementTypeSignedInteger},
+	0x8E:       {Name: "Slices", Type: ElementTypeMaster},
+	0xE8:       {Name: "TimeSlice", Type: ElementTypeMaster},
+	0xCC:       {Name: "LaceNumber", Type: ElementTypeUnsignedInteger},
+	0xCD:       {Name: "FrameNumber", Type: ElementTypeUnsignedInteger},
+	0xCB:       {Name: "BlockAdditionID", Type: ElementTypeUnsignedInteger},
+	0xCE:       {Name: "Delay", Type: ElementTypeUnsignedInteger},
+	0xCF:       {Name: "SliceDuration", Type: ElementTypeUnsignedInteger},
+	0xC8:       {Name: "ReferenceFrame", Type: ElementTypeMaster},
+	0xC9:       {Name: "ReferenceOffset", Type: ElementTypeUnsignedInteger},
+	0xCA:       {Name: "ReferenceTimestamp", Type: ElementTypeUnsignedInteger},
+	0xAF:       {Name: "EncryptedBlock", Type: ElementTypeBinary},
+	0x1654AE6B: {Name: "Tracks", Type: ElementTypeMaster},
+	0xAE:       {Name: "TrackEntry", Type: ElementTypeMaster},
+	0xD7:       {Name: "TrackNumber", Type: ElementTypeUnsignedInteger},
+	0x73C5:     {Name: "TrackUID", Type: ElementTypeUnsignedInteger},
+	0x83:       {Name: "TrackType", Type: ElementTypeUnsignedInteger},
+	0xB9:       {Name: "FlagEnabled", Type: ElementTypeUnsignedInteger},
+	0x88:       {Name: "FlagDefault", Type: ElementTypeUnsignedInteger},
+	0x55AA:     {Name: "FlagForced", Type: ElementTypeUnsignedInteger},
+	0x55AB:     {Name: "FlagHearingImpaired", Type: ElementTypeUnsignedInteger},
+	0x55AC:     {Name: "FlagVisualImpaired", Type: ElementTypeUnsignedInteger},
+	0x55AD:     {Name: "FlagTextDescriptions", Type: ElementTypeUnsignedInteger},
+	0x55AE:     {Name: "FlagOriginal", Type: ElementTypeUnsignedInteger},
+	0x55AF:     {Name: "FlagCommentary", Type: ElementTypeUnsignedInteger},
+	0x9C:       {Name: "FlagLacing", Type: ElementTypeUnsignedInteger},
+	0x6DE7:     {Name: "MinCache", Type: ElementTypeUnsignedInteger},
+	0x6DF8:     {Name: "MaxCache", Type: ElementTypeUnsignedInteger},
+	0x23E383:   {Name: "DefaultDuration", Type: ElementTypeUnsignedInteger},
+	0x234E7A:   {Name: "DefaultDecodedFieldDuration", Type: ElementTypeUnsignedInteger},
+	0x23314F:   {Name: "TrackTimestampScale", Type: ElementTypeFloat},
+	0x537F:     {Name: "TrackOffset", Type: ElementTypeSignedInteger},
+	0x55EE:     {Name: "MaxBlockAdditionID", Type: ElementTypeUnsignedInteger},
+	0x41E4:     {Name: "BlockAdditionMapping", Type: ElementTypeMaster},
+	0x41F0:     {Name: "BlockAddIDValue", Type: ElementTypeUnsignedInteger},
+	0x41A4:     {Name: "BlockAddIDName", Type: ElementTypeString},
+	0x41E7:     {Name: "BlockAddIDType", Type: ElementTypeUnsignedInteger},
+	0x41ED:     {Name: "BlockAddIDExtraData", Type: ElementTypeBinary},
+	0x536E:     {Name: "Name", Type: ElementTypeUtf8},
+	0x22B59C:   {Name: "Language", Type: ElementTypeString},
+	0x22B59D:   {Name: "LanguageBCP47", Type: ElementTypeString},
+	0x86:       {Name: "CodecID", Type: ElementTypeString},
+	0x63A2:     {Name: "CodecPrivate", Type: ElementTypeBinary},
+	0x258688:   {Name: "CodecName", Type: ElementTypeUtf8},
+	0x7446:     {Name: "AttachmentLink", Type: ElementTypeUnsignedInteger},
+	0x3A9697:   {Name: "CodecSettings", Type: ElementTypeUtf8},
+	0x3B4040:   {Name: "CodecInfoURL", Type: ElementTypeString},
+	0x26B240:   {Name: "CodecDownloadURL", Type: ElementTypeString},
+	0xAA:       {Name: "CodecDecodeAll", Type: ElementTypeUnsignedInteger},
+	0x6FAB:     {Name: "TrackOverlay", Type: ElementTypeUnsignedInteger},
+	0x56AA:     {Name: "CodecDelay", Type: ElementTypeUnsignedInteger},
+	0x56BB:     {Name: "SeekPreRoll", Type: ElementTypeUnsignedInteger},
+	0x6624:     {Name: "TrackTranslate", Type: ElementTypeMaster},
+	0x66A5:     {Name: "TrackTranslateTrackID", Type: ElementTypeBinary},
+	0x66BF:     {Name: "TrackTranslateCodec", Type: ElementTypeUnsignedInteger},
+	0x66FC:     {Name: "TrackTranslateEditionUID", Type: ElementTypeUnsignedInteger},
+	0xE0:       {Name: "Video", Type: ElementTypeMaster},
+	0x9A:       {Name: "FlagInterlaced", Type: ElementTypeUnsignedInteger},
+	0x9D:       {Name: "FieldOrder", Type: ElementTypeUnsignedInteger},
+	0x53B8:     {Name: "StereoMode", Type: ElementTypeUnsignedInteger},
+	0x53C0:     {Name: "AlphaMode", Type: ElementTypeUnsignedInteger},
+	0x53B9:     {Name: "OldStereoMode", Type: ElementTypeUnsignedInteger},
+	0xB0:       {Name: "PixelWidth", Type: ElementTypeUnsignedInteger},
+	0xBA:       {Name: "PixelHeight", Type: ElementTypeUnsignedInteger},
+	0x54AA:     {Name: "PixelCropBottom", Type: ElementTypeUnsignedInteger},
+	0x54BB:     {Name: "PixelCropTop", Type: ElementTypeUnsignedInteger},
+	0x54CC:     {Name: "PixelCropLeft", Type: ElementTypeUnsignedInteger},
+	0x54DD:     {Name: "PixelCropRight", Type: ElementTypeUnsignedInteger},
+	0x54B0:     {Name: "DisplayWidth", Type: ElementTypeUnsignedInteger},
+	0x54BA:     {Name: "DisplayHeight", Type: ElementTypeUnsignedInteger},
+	0x54B2:     {Name: "DisplayUnit", Type: ElementTypeUnsignedInteger},
+	0x54B3:     {Name: "AspectRatioType", Type: ElementTypeUnsignedInteger},
+	0x2EB524:   {Name: "UncompressedFourCC", Type: ElementTypeBinary},
+	0x2FB523:   {Name: "GammaValue", Type: ElementTypeFloat},
+	0x2383E3:   {Name: "FrameRate", Type: ElementTypeFloat},
+	0x55B0:     {Name: "Colour", Type: ElementTypeMaster},
+	0x55B1:     {Name: "MatrixCoefficients", Type: ElementTypeUnsignedInteger},
+	0x55B2:     {Name: "BitsPerChannel", Type: ElementTypeUnsignedInteger},
+	0x55B3:     {Name: "ChromaSubsamplingHorz", Type: ElementTypeUnsignedInteger},
+	0x55B4:     {Name: "ChromaSubsamplingVert", Type: ElementTypeUnsignedInteger},
+	0x55B5:     {Name: "CbSubsamplingHorz", Type: ElementTypeUnsignedInteger},
+	0x55B6:     {Name: "CbSubsamplingVert", Type: ElementTypeUnsignedInteger},
+	0x55B7:     {Name: "ChromaSitingHorz", Type: ElementTypeUnsignedInteger},
+	0x55B8:     {Name: "ChromaSitingVert", Type: ElementTypeUnsignedInteger},
+	0x55B9:     {Name: "Range", Type: ElementTypeUnsignedInteger},
+	0x55BA:     {Name: "TransferCharacteristics", Type: ElementTypeUnsignedInteger},
+	0x55BB:     {Name: "Primaries", Type: ElementTypeUnsignedInteger},
+	0x55BC:     {Name: "MaxCLL", Type: ElementTypeUnsignedInteger},
+	0x55BD:     {Name: "MaxFALL", Type: ElementTypeUnsignedInteger},
+	0x55D0:     {Name: "MasteringMetadata", Type: ElementTypeMaster},
+	0x55D1:     {Name: "PrimaryRChromaticityX", Type: ElementTypeFloat},
+	0x55D2:     {Name: "PrimaryRChromaticityY", Type: ElementTypeFloat},
+	0x55D3:     {Name: "PrimaryGChromaticityX", Type: ElementTypeFloat},
+	0x55D4:     {Name: "PrimaryGChromaticityY", Type: ElementTypeFloat},
+	0x55D5:     {Name: "PrimaryBChromaticityX", Type: ElementTypeFloat},
+	0x55D6:     {Name: "PrimaryBChromaticityY", Type: ElementTypeFloat},
+	0x55D7:     {Name: "WhitePointChromaticityX", Type: ElementTypeFloat},
+	0x55D8:     {Name: "WhitePointChromaticityY", Type: ElementTypeFloat},
+	0x55D9:     {Name: "LuminanceMax", Type: ElementTypeFloat},
+	0x55DA:     {Name: "LuminanceMin", Type: ElementTypeFloat},
+	0x7670:     {Name: "Projection", Type: ElementTypeMaster},
+	0x7671:     {Name: "ProjectionType", Type: ElementTypeUnsignedInteger},
+	0x7672:     {Name: "ProjectionPrivate", Type: ElementTypeBinary},
+	0x7673:     {Name: "ProjectionPoseYaw", Type: ElementTypeFloat},
+	0x7674:     {Name: "ProjectionPosePitch", Type: ElementTypeFloat},
+	0x7675:     {Name: "ProjectionPoseRoll", Type: ElementTypeFloat},
+	0xE1:       {Name: "Audio", Type: ElementTypeMaster},
+	0xB5:       {Name: "SamplingFrequency", Type: ElementTypeFloat},
+	0x78B5:     {Name: "OutputSamplingFrequency", Type: ElementTypeFloat},
+	0x9F:       {Name: "Channels", Type: ElementTypeUnsignedInteger},
+	0x7D7B:     {Name: "ChannelPositions", Type: ElementTypeBinary},
+	0x6264:     {Name: "BitDepth", Type: ElementTypeUnsignedInteger},
+	0x52F1:     {Name: "Emphasis", Type: ElementTypeUnsignedInteger},
+	0xE2:       {Name: "TrackOperation", Type: ElementTypeMaster},
+	0xE3:       {Name: "TrackCombinePlanes", Type: ElementTypeMaster},
+	0xE4:       {Name: "TrackPlane", Type: ElementTypeMaster},
+	0xE5:       {Name: "TrackPlaneUID", Type: ElementTypeUnsignedInteger},
+	0xE6:       {Name: "TrackPlaneType", Type: ElementTypeUnsignedInteger},
+	0xE9:       {Name: "TrackJoinBlocks", Type: ElementTypeMaster},
+	0xED:       {Name: "TrackJoinUID", Type: ElementTypeUnsignedInteger},
+	0xC0:       {Name: "TrickTrackUID", Type: ElementTypeUnsignedInteger},
+	0xC1:       {Name: "TrickTrackSegmentUID", Type: ElementTypeBinary},
+	0xC6:       {Name: "TrickTrackFlag", Type: ElementTypeUnsignedInteger},
+	0xC7:       {Name: "TrickMasterTrackUID", Type: ElementTypeUnsignedInteger},
+	0xC4:       {Name: "TrickMasterTrackSegmentUID", Type: ElementTypeBinary},
+	0x6D80:     {Name: "ContentEncodings", Type: ElementTypeMaster},
+	0x6240:     {Name: "ContentEncoding", Type: ElementTypeMaster},
+	0x5031:     {Name: "ContentEncodingOrder", Type: ElementTypeUnsignedInteger},
+	0x5032:     {Name: "ContentEncodingScope", Type: ElementTypeUnsignedInteger},
+	0x5033:     {Name: "ContentEncodingType", Type: ElementTypeUnsignedInteger},
+	0x5034:     {Name: "ContentCompression", Type: ElementTypeMaster},
+	0x4254:     {Name: "ContentCompAlgo", Type: ElementTypeUnsignedInteger},
+	0x4255:     {Name: "ContentCompSettings", Type: ElementTypeBinary},
+	0x5035:     {Name: "ContentEncryption", Type: ElementTypeMaster},
+	0x47E1:     {Name: "ContentEncAlgo", Type: ElementTypeUnsignedInteger},
+	0x47E2:     {Name: "ContentEncKeyID", Type: ElementTypeBinary},
+	0x47E7:     {Name: "ContentEncAESSettings", Type: ElementTypeMaster},
+	0x47E8:     {Name: "AESSettingsCipherMode", Type: ElementTypeUnsignedInteger},
+	0x47E3:     {Name: "ContentSignature", Type: ElementTypeBinary},
+	0x47E4:     {Name: "ContentSigKeyID", Type: ElementTypeBinary},
+	0x47E5:     {Name: "ContentSigAlgo", Type: ElementTypeUnsignedInteger},
+	0x47E6:     {Name: "ContentSigHashAlgo", Type: ElementTypeUnsignedInteger},
+	0x1C53BB6B: {Name: "Cues", Type: ElementTypeMaster},
+	0xBB:       {Name: "CuePoint", Type: ElementTypeMaster},
+	0xB3:       {Name: "CueTime", Type: ElementTypeUnsignedInteger},
+	0xB7:       {Name: "CueTrackPositions", Type: ElementTypeMaster},
+	0xF7:       {Name: "CueTrack", Type: ElementTypeUnsignedInteger},
+	0xF1:       {Name: "CueClusterPosition", Type: ElementTypeUnsignedInteger},
+	0xF0:       {Name: "CueRelativePosition", Type: ElementTypeUnsignedInteger},
+	0xB2:       {Name: "CueDuration", Type: ElementTypeUnsignedInteger},
+	0x5378:     {Name: "CueBlockNumber", Type: ElementTypeUnsignedInteger},
+	0xEA:       {Name: "CueCodecState", Type: ElementTypeUnsignedInteger},
+	0xDB:       {Name: "CueReference", Type: ElementTypeMaster},
+	0x96:       {Name: "CueRefTime", Type: ElementTypeUnsignedInteger},
+	0x97:       {Name: "CueRefCluster", Type: ElementTypeUnsignedInteger},
+	0x535F:     {Name: "CueRefNumber", Type: ElementTypeUnsignedInteger},
+	0xEB:       {Name: "CueRefCodecState", Type: ElementTypeUnsignedInteger},
+	0x1941A469: {Name: "Attachments", Type: ElementTypeMaster},
+	0x61A7:     {Name: "AttachedFile", Type: ElementTypeMaster},
+	0x467E:     {Name: "FileDescription", Type: ElementTypeUtf8},
+	0x466E:     {Name: "FileName", Type: ElementTypeUtf8},
+	0x4660:     {Name: "FileMediaType", Type: ElementTypeString},
+	0x465C:     {Name: "FileData", Type: ElementTypeBinary},
+	0x46AE:     {Name: "FileUID", Type: ElementTypeUnsignedInteger},
+	0x4675:     {Name: "FileReferral", Type: ElementTypeBinary},
+	0x4661:     {Name: "FileUsedStartTime", Type: ElementTypeUnsignedInteger},
+	0x4662:     {Name: "FileUsedEndTime", Type: ElementTypeUnsignedInteger},
+	0x1043A770: {Name: "Chapters", Type: ElementTypeMaster},
+	0x45B9:     {Name: "EditionEntry", Type: ElementTypeMaster},
+	0x45BC:     {Name: "EditionUID", Type: ElementTypeUnsignedInteger},
+	0x45BD:     {Name: "EditionFlagHidden", Type: ElementTypeUnsignedInteger},
+	0x45DB:     {Name: "EditionFlagDefault", Type: ElementTypeUnsignedInteger},
+	0x45DD:     {Name: "EditionFlagOrdered", Type: ElementTypeUnsignedInteger},
+	0x4520:     {Name: "EditionDisplay", Type: ElementTypeMaster},
+	0x4521:     {Name: "EditionString", Type: ElementTypeUtf8},
+	0x45E4:     {Name: "EditionLanguageIETF", Type: ElementTypeString},
+	0xB6:       {Name: "ChapterAtom", Type: ElementTypeMaster},
+	0x73C4:     {Name: "ChapterUID", Type: ElementTypeUnsignedInteger},
+	0x5654:     {Name: "ChapterStringUID", Type: ElementTypeUtf8},
+	0x91:       {Name: "ChapterTimeStart", Type: ElementTypeUnsignedInteger},
+	0x92:       {Name: "ChapterTimeEnd", Type: ElementTypeUnsignedInteger},
+	0x98:       {Name: "ChapterFlagHidden", Type: ElementTypeUnsignedInteger},
+	0x4598:     {Name: "ChapterFlagEnabled", Type: ElementTypeUnsignedInteger},
+	0x6E67:     {Name: "ChapterSegmentUUID", Type: ElementTypeBinary},
+	0x4588:     {Name: "ChapterSkipType", Type: ElementTypeUnsignedInteger},
+	0x6EBC:     {Name: "ChapterSegmentEditionUID", Type: ElementTypeUnsignedInteger},
+	0x63C3:     {Name: "ChapterPhysicalEquiv", Type: ElementTypeUnsignedInteger},
+	0x8F:       {Name: "ChapterTrack", Type: ElementTypeMaster},
+	0x89:       {Name: "ChapterTrackUID", Type: ElementTypeUnsignedInteger},
+	0x80:       {Name: "ChapterDisplay", Type: ElementTypeMaster},
+	0x85:       {Name: "ChapString", Type: ElementTypeUtf8},
+	0x437C:     {Name: "ChapLanguage", Type: ElementTypeString},
+	0x437D:     {Name: "ChapLanguageBCP47", Type: ElementTypeString},
+	0x437E:     {Name: "ChapCountry", Type: ElementTypeString},
+	0x6944:     {Name: "ChapProcess", Type: ElementTypeMaster},
+	0x6955:     {Name: "ChapProcessCodecID", Type: ElementTypeUnsignedInteger},
+	0x450D:     {Name: "ChapProcessPrivate", Type: ElementTypeBinary},
+	0x6911:     {Name: "ChapProcessCommand", Type: ElementTypeMaster},
+	0x6922:     {Name: "ChapProcessTime", Type: ElementTypeUnsignedInteger},
+	0x6933:     {Name: "ChapProcessData", Type: ElementTypeBinary},
+	0x1254C367: {Name: "Tags", Type: ElementTypeMaster},
+	0x7373:     {Name: "Tag", Type: ElementTypeMaster},
+	0x63C0:     {Name: "Targets", Type: ElementTypeMaster},
+	0x68CA:     {Name: "TargetTypeValue", Type: ElementTypeUnsignedInteger},
+	0x63CA:     {Name: "TargetType", Type: ElementTypeString},
+	0x63C5:     {Name: "TagTrackUID", Type: ElementTypeUnsignedInteger},
+	0x63C9:     {Name: "TagEditionUID", Type: ElementTypeUnsignedInteger},
+	0x63C4:     {Name: "TagChapterUID", Type: ElementTypeUnsignedInteger},
+	0x63C6:     {Name: "TagAttachmentUID", Type: ElementTypeUnsignedInteger},
+	0x67C8:     {Name: "SimpleTag", Type: ElementTypeMaster},
+	0x45A3:     {Name: "TagName", Type: ElementTypeUtf8},
+	0x447A:     {Name: "TagLanguage", Type: ElementTypeString},
+	0x447B:     {Name: "TagLanguageBCP47", Type: ElementTypeString},
+	0x4484:     {Name: "TagDefault", Type: ElementTypeUnsignedInteger},
+	0x44B4:     {Name: "TagDefaultBogus", Type: ElementTypeUnsignedInteger},
+	0x4487:     {Name: "TagString", Type: ElementTypeUtf8},
+	0x4485:     {Name: "TagBinary", Type: ElementTypeBinary},
+}
@@ -0,0 +1,105 @@
+package matroska
+
+import (
+	"encoding/xml"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// TestElementSchemasCoverElementIds checks the hand-written schema table against the element ids the package reads
+func TestElementSchemasCoverElementIds(t *testing.T) {
+	fileSet := token.NewFileSet()
+	file, fileErr := parser.ParseFile(fileSet, "ebml_element.go", nil, 0)
+	if fileErr != nil {
+		t.Fatal(fileErr)
+	}
+
+	checked := 0
+	for _, declaration := range file.Decls {
+		genDecl, isGenDecl := declaration.(*ast.GenDecl)
+		if !isGenDecl || genDecl.Tok != token.CONST {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, name := range valueSpec.Names {
+				if name.Name == "ElementNone" || i >= len(valueSpec.Values) {
+					continue
+				}
+
+				value, valueErr := types.Eval(fileSet, nil, token.NoPos, types.ExprString(valueSpec.Values[i]))
+				if valueErr != nil || value.Value == nil || value.Value.Kind() != constant.Int {
+					continue
+				}
+				id, _ := constant.Uint64Val(value.Value)
+
+				if _, found := ElementSchemas[ElementId(id)]; !found {
+					t.Errorf("%s (0x%X) is missing from ElementSchemas", name.Name, id)
+				}
+				checked++
+			}
+		}
+	}
+
+	if checked == 0 {
+		t.Fatal("found no element ids in ebml_element.go")
+	}
+}
+
+// TestElementSchemasMatchSpecification checks the schema table against element definitions copied from the
+// specification the table is transcribed from
+func TestElementSchemasMatchSpecification(t *testing.T) {
+	data, readErr := os.ReadFile("testdata/ebml_matroska.xml")
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	var specification struct {
+		Elements []struct {
+			Id   string `xml:"id,attr"`
+			Name string `xml:"name,attr"`
+			Path string `xml:"path,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"element"`
+	}
+	if unmarshalErr := xml.Unmarshal(data, &specification); unmarshalErr != nil {
+		t.Fatal(unmarshalErr)
+	}
+	if len(specification.Elements) == 0 {
+		t.Fatal("found no element definitions in the specification")
+	}
+
+	for _, element := range specification.Elements {
+		id, idErr := strconv.ParseUint(element.Id, 0, 32)
+		if idErr != nil {
+			t.Errorf("%s: invalid id %s", element.Path, element.Id)
+			continue
+		}
+
+		schema, found := ElementSchemas[ElementId(id)]
+		if !found {
+			t.Errorf("%s (%s) is missing from ElementSchemas", element.Path, element.Id)
+		} else if schema.Name != element.Name || schema.Type.String() != element.Type {
+			t.Errorf("%s (%s): got %v, expected name %s and type %s", element.Path, element.Id, schema, element.Name, element.Type)
+		}
+	}
+}
+
+func TestLookupElementSchema(t *testing.T) {
+	schema, found := LookupElementSchema(ElementCluster)
+	if !found || schema.Name != "Cluster" || schema.Type != ElementTypeMaster {
+		t.Errorf("got %v, expected the Cluster master element", schema)
+	}
+
+	schema, found = LookupElementSchema(0x1234)
+	if found || schema.Name != "Unknown (0x1234)" || schema.Type != ElementTypeBinary {
+		t.Errorf("got %v, expected an unknown binary element", schema)
+	}
+}
@@ -0,0 +1,117 @@
+package matroska
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// ebmlElement returns the encoded element, with the size stored in as few bytes as possible
+func ebmlElement(id ElementId, data []byte) []byte {
+	buffer := ebmlElementId(id)
+	buffer = append(buffer, ebmlSize(uint64(len(data)))...)
+
+	return append(buffer, data...)
+}
+
+func ebmlElementId(id ElementId) []byte {
+	//Element ids keep their length marker, so they are stored as-is in as few bytes as possible
+	switch {
+	case id > 0xFFFFFF:
+		return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFFFF:
+		return []byte{byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFF:
+		return []byte{byte(id >> 8), byte(id)}
+	}
+
+	return []byte{byte(id)}
+}
+
+func ebmlFloat(id ElementId, value float64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, math.Float64bits(value))
+
+	return ebmlElement(id, data)
+}
+
+// ebmlSize returns the variable length encoding of a size, a value with all bits set is reserved for unknown sizes
+func ebmlSize(size uint64) []byte {
+	length := 1
+	for length < 8 && size >= (uint64(1)<<(7*length))-1 {
+		length++
+	}
+
+	return ebmlSizeWithLength(size, length)
+}
+
+func ebmlSizeWithLength(size uint64, length int) []byte {
+	buffer := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		buffer[i] = byte(size)
+		size >>= 8
+	}
+	buffer[0] |= 0x80 >> (length - 1)
+
+	return buffer
+}
+
+func ebmlString(id ElementId, value string) []byte {
+	return ebmlElement(id, []byte(value))
+}
+
+func ebmlUInt(id ElementId, value uint64) []byte {
+	length := 1
+	for length < 8 && value >= uint64(1)<<(8*length) {
+		length++
+	}
+
+	return ebmlElement(id, ebmlUIntData(value, length))
+}
+
+func ebmlUIntData(value uint64, length int) []byte {
+	data := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		data[i] = byte(value)
+		value >>= 8
+	}
+
+	return data
+}
+
+// readElementFromBuffer reads the element header at the offset, the returned positions are relative to the buffer
+func readElementFromBuffer(buffer []byte, offset int) (Element, bool) {
+	if offset >= len(buffer) {
+		return InvalidElement, false
+	}
+
+	idLength := 0
+	mask := byte(0x80)
+	for i := 0; i < 4; i++ {
+		if (buffer[offset] & mask) == mask {
+			idLength = i + 1
+			break
+		}
+		mask >>= 1
+	}
+
+	if idLength == 0 || offset+idLength > len(buffer) {
+		return InvalidElement, false
+	}
+
+	id := uint32(0)
+	for i := 0; i < idLength; i++ {
+		id = id<<8 | uint32(buffer[offset+i])
+	}
+
+	size, sizeLength := readVariableLengthUIntFromBuffer(buffer[offset+idLength:])
+	if sizeLength == 0 {
+		return InvalidElement, false
+	}
+
+	dataPosition := offset + idLength + sizeLength
+	if uint64(len(buffer)-dataPosition) < size {
+		return InvalidElement, false
+	}
+
+	return *NewElement(ElementId(id), int64(dataPosition), int64(size)), true
+}
@@ -0,0 +1,216 @@
+package matroska
+
+import "github.com/cockroachdb/errors"
+
+var errLzoInputOverrun = errors.New("lzo1x input overrun")
+
+type lzo1xDecompressor struct {
+	input    []byte
+	output   []byte
+	position int
+}
+
+func (l *lzo1xDecompressor) copyLiterals(count int) error {
+	if l.position+count > len(l.input) {
+		return errLzoInputOverrun
+	}
+
+	l.output = append(l.output, l.input[l.position:l.position+count]...)
+	l.position += count
+
+	return nil
+}
+
+// copyMatch copies byte by byte as the match may overlap the bytes it produces
+func (l *lzo1xDecompressor) copyMatch(distance int, count int) error {
+	start := len(l.output) - distance
+	if start < 0 {
+		return errors.Newf("lzo1x match distance %d is before the start of the output", distance)
+	}
+
+	for i := 0; i < count; i++ {
+		l.output = append(l.output, l.output[start+i])
+	}
+
+	return nil
+}
+
+func (l *lzo1xDecompressor) readByte() (int, error) {
+	if l.position >= len(l.input) {
+		return 0, errLzoInputOverrun
+	}
+
+	value := int(l.input[l.position])
+	l.position++
+
+	return value, nil
+}
+
+// readLength reads a length that continues with a run of zero bytes each adding 255
+func (l *lzo1xDecompressor) readLength(base int) (int, error) {
+	length := base
+	for {
+		value, valueErr := l.readByte()
+		if valueErr != nil {
+			return 0, valueErr
+		}
+
+		if value != 0 {
+			return length + value, nil
+		}
+
+		length += 255
+	}
+}
+
+func (l *lzo1xDecompressor) readLittleEndianUInt16() (int, error) {
+	if l.position+2 > len(l.input) {
+		return 0, errLzoInputOverrun
+	}
+
+	value := int(l.input[l.position]) | int(l.input[l.position+1])<<8
+	l.position += 2
+
+	return value, nil
+}
+
+// lzo1xDecompress decompresses an LZO1X stream as produced by lzo1x_1_compress and lzo1x_999_compress
+func lzo1xDecompress(input []byte) ([]byte, error) {
+	l := &lzo1xDecompressor{input: input, output: make([]byte, 0, len(input)*3)}
+	if len(input) == 0 {
+		return nil, errLzoInputOverrun
+	}
+
+	//Number of literals to copy after the current instruction, -1 when a literal run instruction follows
+	trailingLiterals := -1
+
+	if input[0] > 17 {
+		l.position++
+
+		literals := int(input[0]) - 17
+		if copyErr := l.copyLiterals(literals); copyErr != nil {
+			return nil, copyErr
+		}
+
+		//A run of at least four literals is followed by the same instructions as any other literal run,
+		// a shorter one by the same instructions as literals trailing a match
+		trailingLiterals = min(literals, 4)
+	}
+
+	for {
+		instruction, instructionErr := l.readByte()
+		if instructionErr != nil {
+			return nil, instructionErr
+		}
+
+		if instruction < 16 {
+			switch {
+			case trailingLiterals < 0:
+				//Literal run
+				literals := instruction + 3
+				if instruction == 0 {
+					length, lengthErr := l.readLength(15)
+					if lengthErr != nil {
+						return nil, lengthErr
+					}
+
+					literals = length + 3
+				}
+
+				if copyErr := l.copyLiterals(literals); copyErr != nil {
+					return nil, copyErr
+				}
+
+				trailingLiterals = 4
+
+				continue
+			case trailingLiterals == 4:
+				//Three byte match directly following a literal run
+				next, nextErr := l.readByte()
+				if nextErr != nil {
+					return nil, nextErr
+				}
+
+				if copyErr := l.copyMatch(1+0x0800+(instruction>>2)+(next<<2), 3); copyErr != nil {
+					return nil, copyErr
+				}
+			default:
+				//Two byte match following a match with trailing literals
+				next, nextErr := l.readByte()
+				if nextErr != nil {
+					return nil, nextErr
+				}
+
+				if copyErr := l.copyMatch(1+(instruction>>2)+(next<<2), 2); copyErr != nil {
+					return nil, copyErr
+				}
+			}
+		} else {
+			var distance, length int
+
+			switch {
+			case instruction >= 64:
+				next, nextErr := l.readByte()
+				if nextErr != nil {
+					return nil, nextErr
+				}
+
+				distance = 1 + ((instruction >> 2) & 7) + (next << 3)
+				length = (instruction >> 5) + 1
+			case instruction >= 32:
+				length = instruction & 31
+				if length == 0 {
+					var lengthErr error
+					if length, lengthErr = l.readLength(31); lengthErr != nil {
+						return nil, lengthErr
+					}
+				}
+				length += 2
+
+				value, valueErr := l.readLittleEndianUInt16()
+				if valueErr != nil {
+					return nil, valueErr
+				}
+
+				distance = 1 + (value >> 2)
+			default:
+				length = instruction & 7
+				if length == 0 {
+					var lengthErr error
+					if length, lengthErr = l.readLength(7); lengthErr != nil {
+						return nil, lengthErr
+					}
+				}
+				length += 2
+
+				value, valueErr := l.readLittleEndianUInt16()
+				if valueErr != nil {
+					return nil, valueErr
+				}
+
+				distance = ((instruction & 8) << 11) + (value >> 2)
+				if distance == 0 {
+					//End of stream marker
+					return l.output, nil
+				}
+				distance += 0x4000
+			}
+
+			if copyErr := l.copyMatch(distance, length); copyErr != nil {
+				return nil, copyErr
+			}
+		}
+
+		//The two lowest bits of the second to last byte of a match hold the number of literals that follow it
+		trailingLiterals = int(l.input[l.position-2]) & 3
+		if trailingLiterals == 0 {
+			trailingLiterals = -1
+
+			continue
+		}
+
+		if copyErr := l.copyLiterals(trailingLiterals); copyErr != nil {
+			return nil, copyErr
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package matroska
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// lzoEnd is the end of stream marker, a match instruction with a distance of zero
+var lzoEnd = []byte{0x11, 0x00, 0x00}
+
+func TestLzo1xDecompress(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{name: "first literal run", input: slices.Concat([]byte{17 + 5}, []byte("hello"), lzoEnd), expected: "hello"},
+		//0xE8 is a match of 8 bytes at a distance of 3, overlapping the bytes it produces
+		{name: "overlapping match", input: slices.Concat([]byte{17 + 3}, []byte("abc"), []byte{0xE8, 0x00}, lzoEnd), expected: "abcabcabcab"},
+		//0x20 takes its length from the following bytes, each zero adding 255: 31 + 255 + 5 + 2 bytes at a distance of 1
+		{name: "long match", input: slices.Concat([]byte{17 + 1, 'a', 0x20, 0x00, 0x05, 0x00, 0x00}, lzoEnd), expected: strings.Repeat("a", 294)},
+		//0x4A is a match of 3 bytes at a distance of 3 followed by 2 literals, after which 0x04 is a 2 byte match at a
+		//distance of 2
+		{name: "trailing literals and short match", input: slices.Concat([]byte{17 + 3}, []byte("abc"), []byte{0x4A, 0x00, 'X', 'Y', 0x04, 0x00}, lzoEnd), expected: "abcabcXYXY"},
+		//After a match without trailing literals 0x01 is a run of 4 literals
+		{name: "literal run after match", input: slices.Concat([]byte{17 + 1, 'a', 0x40, 0x00, 0x01}, []byte("wxyz"), lzoEnd), expected: "aaaawxyz"},
+	}
+
+	for _, test := range tests {
+		output, outputErr := lzo1xDecompress(test.input)
+		if outputErr != nil {
+			t.Errorf("%s: %v", test.name, outputErr)
+
+			continue
+		}
+		if string(output) != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.name, output, test.expected)
+		}
+	}
+}
+
+func TestLzo1xDecompressErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "empty", input: []byte{}},
+		{name: "truncated literals", input: []byte{17 + 5, 'h', 'e'}},
+		{name: "missing end marker", input: slices.Concat([]byte{17 + 3}, []byte("abc"))},
+		{name: "distance before start", input: slices.Concat([]byte{17 + 1, 'a', 0xE8, 0x00}, lzoEnd)},
+	}
+
+	for _, test := range tests {
+		if _, outputErr := lzo1xDecompress(test.input); outputErr == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestLzo1xDecompressMatchesBytes(t *testing.T) {
+	output, outputErr := lzo1xDecompress(slices.Concat([]byte{17 + 2, 0x00, 0xFF, 0xE4, 0x00}, lzoEnd))
+	if outputErr != nil {
+		t.Fatal(outputErr)
+	}
+	if !bytes.Equal(output, []byte{0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF}) {
+		t.Errorf("got %v", output)
+	}
+}
@@ -0,0 +1,15 @@
+package matroska
+
+import "fmt"
+
+// BlockAddIdDefault is the BlockAddID of additional data that does not specify one
+const BlockAddIdDefault = 1
+
+type MatroskaBlockAddition struct {
+	Data []byte
+	Id   uint64
+}
+
+func (m *MatroskaBlockAddition) String() string {
+	return fmt.Sprintf("Id: %v , Data: %v bytes", m.Id, len(m.Data))
+}
@@ -0,0 +1,127 @@
+package matroska
+
+import "github.com/cockroachdb/errors"
+
+const (
+	lacingNone  = 0
+	lacingXiph  = 2
+	lacingFixed = 4
+	lacingEbml  = 6
+)
+
+// splitLacedFrames splits the data following the flags of a (Simple)Block into its frames, see
+// https://www.matroska.org/technical/notes.html#block-lacing
+func splitLacedFrames(flags byte, data []byte) ([][]byte, error) {
+	lacing := flags & 6
+	if lacing == lacingNone {
+		return [][]byte{data}, nil
+	}
+
+	if len(data) < 1 {
+		return nil, errors.New("missing lace count")
+	}
+
+	frameCount := int(data[0]) + 1
+	position := 1
+	frameSizes := make([]int, frameCount)
+	knownSize := 0
+
+	switch lacing {
+	case lacingXiph:
+		for i := 0; i < frameCount-1; i++ {
+			size := 0
+			for {
+				if position >= len(data) {
+					return nil, errors.New("truncated Xiph lace size")
+				}
+
+				value := int(data[position])
+				position++
+				size += value
+
+				if value != 255 {
+					break
+				}
+			}
+
+			frameSizes[i] = size
+			knownSize += size
+		}
+	case lacingFixed:
+		remaining := len(data) - position
+		if remaining%frameCount != 0 {
+			return nil, errors.Newf("fixed-size lacing of %d bytes cannot be split into %d frames", remaining, frameCount)
+		}
+
+		for i := 0; i < frameCount-1; i++ {
+			frameSizes[i] = remaining / frameCount
+			knownSize += frameSizes[i]
+		}
+	case lacingEbml:
+		size, length := readVariableLengthUIntFromBuffer(data[position:])
+		if length == 0 {
+			return nil, errors.New("truncated EBML lace size")
+		}
+		position += length
+
+		frameSizes[0] = int(size)
+		knownSize += frameSizes[0]
+
+		for i := 1; i < frameCount-1; i++ {
+			delta, length := readVariableLengthUIntFromBuffer(data[position:])
+			if length == 0 {
+				return nil, errors.New("truncated EBML lace size difference")
+			}
+			position += length
+
+			//The differences are stored as signed values by subtracting half of the range
+			frameSizes[i] = frameSizes[i-1] + int(int64(delta)-(int64(1)<<(7*length-1)-1))
+			if frameSizes[i] < 0 {
+				return nil, errors.New("negative EBML lace size")
+			}
+			knownSize += frameSizes[i]
+		}
+	}
+
+	frameSizes[frameCount-1] = len(data) - position - knownSize
+	if frameSizes[frameCount-1] < 0 {
+		return nil, errors.Newf("lace sizes exceed block size of %d bytes", len(data))
+	}
+
+	frames := make([][]byte, frameCount)
+	for i, frameSize := range frameSizes {
+		frames[i] = data[position : position+frameSize]
+		position += frameSize
+	}
+
+	return frames, nil
+}
+
+// readVariableLengthUIntFromBuffer reads an EBML variable length integer with the length marker removed,
+// returning its length in bytes or zero if the buffer is too short
+func readVariableLengthUIntFromBuffer(buffer []byte) (uint64, int) {
+	if len(buffer) < 1 {
+		return 0, 0
+	}
+
+	length := 0
+	mask := byte(0x80)
+	for i := 0; i < 8; i++ {
+		if (buffer[0] & mask) == mask {
+			length = i + 1
+			break
+		}
+		mask >>= 1
+	}
+
+	if length == 0 || length > len(buffer) {
+		return 0, 0
+	}
+
+	result := uint64(buffer[0] & (0xFF >> length))
+	for i := 1; i < length; i++ {
+		result = result<<8 | uint64(buffer[i])
+	}
+
+	return result, length
+}
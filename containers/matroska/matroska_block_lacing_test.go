@@ -0,0 +1,128 @@
+package matroska
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+func TestSplitLacedFrames(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	tenBytes := strings.Repeat("a", 10)
+	twoHundredBytes := strings.Repeat("b", 200)
+
+	tests := []struct {
+		name     string
+		flags    byte
+		data     []byte
+		expected []string
+	}{
+		{name: "no lacing", flags: 0x80, data: []byte("whole"), expected: []string{"whole"}},
+		{name: "xiph", flags: lacingXiph, data: slices.Concat([]byte{2, 3, 2}, []byte("onetwothree")), expected: []string{"one", "tw", "othree"}},
+		{name: "xiph size of 255 and more", flags: lacingXiph, data: slices.Concat([]byte{1, 255, 45}, []byte(long), []byte("end")), expected: []string{long, "end"}},
+		{name: "fixed", flags: lacingFixed, data: slices.Concat([]byte{2}, []byte("aabbcc")), expected: []string{"aa", "bb", "cc"}},
+		//Sizes 5 and 3, the difference -2 is stored as 61 (biased by 63) with the length marker
+		{name: "ebml negative delta", flags: lacingEbml, data: slices.Concat([]byte{2, 0x85, 0xBD}, []byte("fivesthrlast")), expected: []string{"fives", "thr", "last"}},
+		//Sizes 10 and 200, the difference 190 needs two bytes: 190 + 8191 = 0x20BD with the marker 0x40
+		{name: "ebml two byte delta", flags: lacingEbml, data: slices.Concat([]byte{2, 0x8A, 0x60, 0xBD}, []byte(tenBytes+twoHundredBytes+"z")), expected: []string{tenBytes, twoHundredBytes, "z"}},
+		{name: "ebml empty last frame", flags: lacingEbml, data: slices.Concat([]byte{1, 0x83}, []byte("abc")), expected: []string{"abc", ""}},
+	}
+
+	for _, test := range tests {
+		frames, framesErr := splitLacedFrames(test.flags, test.data)
+		if framesErr != nil {
+			t.Errorf("%s: %v", test.name, framesErr)
+
+			continue
+		}
+
+		actual := []string{}
+		for _, frame := range frames {
+			actual = append(actual, string(frame))
+		}
+		if !slices.Equal(actual, test.expected) {
+			t.Errorf("%s: got %q, expected %q", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestSplitLacedFramesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags byte
+		data  []byte
+	}{
+		{name: "missing lace count", flags: lacingXiph, data: []byte{}},
+		{name: "truncated xiph size", flags: lacingXiph, data: []byte{1, 255}},
+		{name: "fixed size not divisible", flags: lacingFixed, data: []byte{1, 'a', 'b', 'c'}},
+		{name: "truncated ebml size", flags: lacingEbml, data: []byte{1}},
+		{name: "truncated ebml difference", flags: lacingEbml, data: []byte{2, 0x81, 0x40}},
+		{name: "negative ebml size", flags: lacingEbml, data: []byte{2, 0x81, 0x80, 'a'}},
+		{name: "sizes exceed block", flags: lacingXiph, data: []byte{1, 10, 'a'}},
+	}
+
+	for _, test := range tests {
+		if _, framesErr := splitLacedFrames(test.flags, test.data); framesErr == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestSubtitleSplitsLacedBlocks(t *testing.T) {
+	//Three frames of the EBML lacing with sizes 3, 3 and the rest
+	lacedData := slices.Concat([]byte{2, 0x83, 0xBF}, []byte("onetwothree"))
+
+	for _, test := range []struct {
+		name            string
+		defaultDuration uint64
+		starts          []int64
+		durations       []int64
+	}{
+		{name: "block duration shared", starts: []int64{1000, 2000, 3000}, durations: []int64{1000, 1000, 1000}},
+		{name: "default duration", defaultDuration: 1500000000, starts: []int64{1000, 2500, 4000}, durations: []int64{1500, 1500, 1500}},
+	} {
+		trackEntry := [][]byte{
+			mkvtest.UInt(uint32(ElementTrackNumber), 1),
+			mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+			mkvtest.String(uint32(ElementCodecId), "S_TEXT/UTF8"),
+		}
+		if test.defaultDuration > 0 {
+			trackEntry = append(trackEntry, mkvtest.UInt(uint32(ElementDefaultDuration), test.defaultDuration))
+		}
+
+		data := mkvtest.File(
+			mkvtest.Element(uint32(ElementInfo), mkvtest.UInt(uint32(ElementTimecodeScale), 1000000)),
+			mkvtest.Element(uint32(ElementTracks), mkvtest.Element(uint32(ElementTrackEntry), trackEntry...)),
+			mkvtest.Element(uint32(ElementCluster),
+				mkvtest.UInt(uint32(ElementTimecode), 1000),
+				mkvtest.Element(uint32(ElementBlockGroup),
+					mkvtest.Element(uint32(ElementBlock), mkvtest.Block(1, 0, lacingEbml, lacedData)),
+					mkvtest.UInt(uint32(ElementBlockDuration), 3000),
+				),
+			),
+		)
+
+		matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+		if matroskaFileErr != nil {
+			t.Fatal(matroskaFileErr)
+		}
+
+		subtitles, subtitlesErr := matroskaFile.Subtitle(1, nil)
+		if subtitlesErr != nil {
+			t.Fatal(subtitlesErr)
+		}
+
+		texts, starts, durations := []string{}, []int64{}, []int64{}
+		for _, subtitle := range subtitles {
+			texts = append(texts, string(subtitle.Data))
+			starts = append(starts, subtitle.Start)
+			durations = append(durations, subtitle.Duration)
+		}
+		if !slices.Equal(texts, []string{"one", "two", "three"}) || !slices.Equal(starts, test.starts) || !slices.Equal(durations, test.durations) {
+			t.Errorf("%s: got %q starting at %v lasting %v, expected starts %v and durations %v", test.name, texts, starts, durations, test.starts, test.durations)
+		}
+	}
+}
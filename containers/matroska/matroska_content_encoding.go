@@ -0,0 +1,79 @@
+package matroska
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	ContentCompressionAlgorithmZlib            = 0
+	ContentCompressionAlgorithmBzlib           = 1
+	ContentCompressionAlgorithmLzo1x           = 2
+	ContentCompressionAlgorithmHeaderStripping = 3
+)
+
+var ErrContentEncrypted = errors.New("encrypted Matroska content is not supported")
+
+type MatroskaContentEncoding struct {
+	CompressionAlgorithm int
+	CompressionSettings  []byte
+	Order                uint64
+	Scope                uint
+	Type                 int
+}
+
+func (m *MatroskaContentEncoding) Decode(data []byte) ([]byte, error) {
+	if m.Type == ContentEncodingTypeEncryption {
+		return nil, ErrContentEncrypted
+	}
+
+	if m.Type != ContentEncodingTypeCompression {
+		return nil, errors.Newf("unknown content encoding type %d", m.Type)
+	}
+
+	switch m.CompressionAlgorithm {
+	case ContentCompressionAlgorithmZlib:
+		zlibReader, zlibReaderErr := zlib.NewReader(bytes.NewReader(data))
+		if zlibReaderErr != nil {
+			return nil, errors.Wrap(zlibReaderErr, "failed to create zlib reader")
+		}
+
+		uncompressedData, uncompressedDataErr := io.ReadAll(zlibReader)
+		if uncompressedDataErr != nil {
+			return nil, errors.Wrap(uncompressedDataErr, "failed to read all data from zlib reader")
+		}
+
+		return uncompressedData, nil
+	case ContentCompressionAlgorithmBzlib:
+		uncompressedData, uncompressedDataErr := io.ReadAll(bzip2.NewReader(bytes.NewReader(data)))
+		if uncompressedDataErr != nil {
+			return nil, errors.Wrap(uncompressedDataErr, "failed to read all data from bzip2 reader")
+		}
+
+		return uncompressedData, nil
+	case ContentCompressionAlgorithmLzo1x:
+		uncompressedData, uncompressedDataErr := lzo1xDecompress(data)
+		if uncompressedDataErr != nil {
+			return nil, errors.Wrap(uncompressedDataErr, "failed to decompress lzo1x data")
+		}
+
+		return uncompressedData, nil
+	case ContentCompressionAlgorithmHeaderStripping:
+		//The stripped bytes are stored once in the track and must be put back in front of every frame
+		uncompressedData := make([]byte, 0, len(m.CompressionSettings)+len(data))
+		uncompressedData = append(uncompressedData, m.CompressionSettings...)
+
+		return append(uncompressedData, data...), nil
+	}
+
+	return nil, errors.Newf("unknown content compression algorithm %d", m.CompressionAlgorithm)
+}
+
+func (m *MatroskaContentEncoding) String() string {
+	return fmt.Sprintf("Order: %v , Scope: %v , Type: %v , CompressionAlgorithm: %v", m.Order, m.Scope, m.Type, m.CompressionAlgorithm)
+}
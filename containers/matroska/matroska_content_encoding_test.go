@@ -0,0 +1,100 @@
+package matroska
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"testing"
+
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+func TestContentEncodingDecode(t *testing.T) {
+	zlibData := bytes.Buffer{}
+	zlibWriter := zlib.NewWriter(&zlibData)
+	_, _ = zlibWriter.Write([]byte("hello zlib"))
+	_ = zlibWriter.Close()
+
+	//bz2.compress(b"hello bzip2") in Python
+	bzip2Data := []byte{66, 90, 104, 57, 49, 65, 89, 38, 83, 89, 85, 90, 68, 247, 0, 0, 2, 25, 128, 64, 0, 16, 0, 18, 100, 192, 16, 32, 0, 34, 0, 105, 234, 16, 3, 5, 211, 182, 33, 131, 197, 220, 145, 78, 20, 36, 21, 86, 145, 61, 192}
+
+	tests := []struct {
+		name     string
+		encoding MatroskaContentEncoding
+		data     []byte
+		expected string
+	}{
+		{name: "zlib", encoding: MatroskaContentEncoding{CompressionAlgorithm: ContentCompressionAlgorithmZlib}, data: zlibData.Bytes(), expected: "hello zlib"},
+		{name: "bzlib", encoding: MatroskaContentEncoding{CompressionAlgorithm: ContentCompressionAlgorithmBzlib}, data: bzip2Data, expected: "hello bzip2"},
+		{name: "lzo1x", encoding: MatroskaContentEncoding{CompressionAlgorithm: ContentCompressionAlgorithmLzo1x}, data: append([]byte{17 + 5}, append([]byte("hello"), lzoEnd...)...), expected: "hello"},
+		{name: "header stripping", encoding: MatroskaContentEncoding{CompressionAlgorithm: ContentCompressionAlgorithmHeaderStripping, CompressionSettings: []byte("Hel")}, data: []byte("lo"), expected: "Hello"},
+	}
+
+	for _, test := range tests {
+		decoded, decodedErr := test.encoding.Decode(test.data)
+		if decodedErr != nil {
+			t.Errorf("%s: %v", test.name, decodedErr)
+
+			continue
+		}
+		if string(decoded) != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.name, decoded, test.expected)
+		}
+	}
+}
+
+func TestContentEncodingDecodeErrors(t *testing.T) {
+	encrypted := MatroskaContentEncoding{Type: ContentEncodingTypeEncryption}
+	if _, decodedErr := encrypted.Decode([]byte("secret")); !errors.Is(decodedErr, ErrContentEncrypted) {
+		t.Errorf("encrypted content: got %v, expected ErrContentEncrypted", decodedErr)
+	}
+
+	unknown := MatroskaContentEncoding{CompressionAlgorithm: 9}
+	if _, decodedErr := unknown.Decode([]byte("data")); decodedErr == nil {
+		t.Errorf("unknown compression algorithm: expected an error")
+	}
+}
+
+func TestSubtitleTextUndoesHeaderStripping(t *testing.T) {
+	data := mkvtest.File(
+		mkvtest.Element(uint32(ElementInfo), mkvtest.UInt(uint32(ElementTimecodeScale), 1000000)),
+		mkvtest.Element(uint32(ElementTracks), mkvtest.Element(uint32(ElementTrackEntry),
+			mkvtest.UInt(uint32(ElementTrackNumber), 1),
+			mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+			mkvtest.String(uint32(ElementCodecId), "S_TEXT/UTF8"),
+			mkvtest.Element(uint32(ElementContentEncodings), mkvtest.Element(uint32(ElementContentEncoding),
+				mkvtest.Element(uint32(ElementContentCompression),
+					mkvtest.UInt(uint32(ElementContentCompAlgo), ContentCompressionAlgorithmHeaderStripping),
+					mkvtest.Element(uint32(ElementContentCompSettings), []byte("<i>")),
+				),
+			)),
+		)),
+		mkvtest.Element(uint32(ElementCluster),
+			mkvtest.UInt(uint32(ElementTimecode), 0),
+			mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 0, 0x80, []byte("Hi</i>\nthere"))),
+		),
+	)
+
+	matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if matroskaFileErr != nil {
+		t.Fatal(matroskaFileErr)
+	}
+
+	tracks, tracksErr := matroskaFile.Tracks(true)
+	if tracksErr != nil || len(tracks) != 1 {
+		t.Fatalf("got tracks %v and error %v, expected one track", tracks, tracksErr)
+	}
+
+	subtitles, subtitlesErr := matroskaFile.Subtitle(1, nil)
+	if subtitlesErr != nil || len(subtitles) != 1 {
+		t.Fatalf("got subtitles %v and error %v, expected one subtitle", subtitles, subtitlesErr)
+	}
+
+	text, textErr := subtitles[0].Text(tracks[0])
+	if textErr != nil {
+		t.Fatal(textErr)
+	}
+	if text != "<i>Hi</i>\nthere" {
+		t.Errorf("got %q, expected %q", text, "<i>Hi</i>\nthere")
+	}
+}
@@ -0,0 +1,25 @@
+package matroska
+
+import "fmt"
+
+type MatroskaCuePoint struct {
+	Time           uint64
+	TrackPositions []MatroskaCueTrackPosition
+}
+
+type MatroskaCueTrackPosition struct {
+	BlockNumber     uint64
+	ClusterPosition uint64
+	Duration        uint64
+	//RelativePosition is relative to the data of the cluster, zero means it was not stored in the file
+	RelativePosition uint64
+	Track            uint64
+}
+
+func (m *MatroskaCuePoint) String() string {
+	return fmt.Sprintf("Time: %v , TrackPositions: %v", m.Time, m.TrackPositions)
+}
+
+func (m *MatroskaCueTrackPosition) String() string {
+	return fmt.Sprintf("Track: %v , ClusterPosition: %v , RelativePosition: %v , Duration: %v , BlockNumber: %v", m.Track, m.ClusterPosition, m.RelativePosition, m.Duration, m.BlockNumber)
+}
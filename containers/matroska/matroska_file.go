@@ -4,26 +4,45 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/ristryder/gse/common"
 )
 
+const (
+	DocTypeMatroska = "matroska"
+	DocTypeWebM     = "webm"
+)
+
 type MatroskaFile struct {
+	DateUtc        time.Time
+	DocType        string
 	Duration       float64
 	FrameRate      float64
 	IsValid        bool
+	MuxingApp      string
 	Path           string
 	PixelHeight    int
 	PixelWidth     int
 	SegmentElement *Element
+	SegmentUid     []byte
 	TimeCodeScale  int64
-	VideoCodecId   string
+	Title          string
+	//UseCues reads only the clusters the Cues point to when they index the subtitle tracks, which is faster but
+	//misses blocks in clusters without a cue for their track. Every cluster is read by default
+	UseCues      bool
+	VideoCodecId string
+	WritingApp   string
 
-	file      *common.FileStream
-	isOpen    bool
-	subtitles []MatroskaSubtitle
-	tracks    []MatroskaTrackInfo
+	cuePoints     []MatroskaCuePoint
+	file          common.Stream
+	isOpen        bool
+	seekPositions map[ElementId]int64
+	subtitles     map[uint64][]MatroskaSubtitle
+	tags          []MatroskaTag
+	tracks        []MatroskaTrackInfo
 }
 
 func (m *MatroskaFile) scaleTime32(time float32) float64 {
@@ -34,33 +53,108 @@ func (m *MatroskaFile) scaleTime64(time float64) float64 {
 	return time * float64(m.TimeCodeScale) / 1000000.0
 }
 
+// trackDefaultDuration returns the default duration of a track in milliseconds, or zero if it is unknown
+func (m *MatroskaFile) trackDefaultDuration(trackNumber uint64) float64 {
+	for _, track := range m.tracks {
+		if uint64(track.TrackNumber) == trackNumber {
+			return float64(track.DefaultDuration) / 1000000.0
+		}
+	}
+
+	return 0
+}
+
 func (m *MatroskaFile) Close() error {
 	if !m.isOpen {
 		return nil
 	}
 
+	m.cuePoints = nil
+	m.DateUtc = time.Time{}
+	m.DocType = ""
 	m.Duration = -1
 	m.FrameRate = -1
 	m.isOpen = false
 	m.IsValid = false
+	m.MuxingApp = ""
 	m.Path = ""
 	m.PixelHeight = 0
 	m.PixelWidth = 0
+	m.seekPositions = nil
 	m.SegmentElement = nil
+	m.SegmentUid = nil
 	m.subtitles = nil
+	m.tags = nil
 	m.TimeCodeScale = -1
+	m.Title = ""
 	m.tracks = nil
 	m.VideoCodecId = ""
+	m.WritingApp = ""
 
 	return m.file.Close()
 }
 
+// CuePoints returns the index stored in the Cues element, which is empty if the file has no Cues
+func (m *MatroskaFile) CuePoints() ([]MatroskaCuePoint, error) {
+	if m.tracks == nil {
+		segmentInfoAndTracksErr := m.readSegmentInfoAndTracks()
+		if segmentInfoAndTracksErr != nil {
+			return nil, errors.Wrap(segmentInfoAndTracksErr, "failed to read cue points")
+		}
+	}
+
+	if m.cuePoints == nil {
+		return []MatroskaCuePoint{}, nil
+	}
+
+	return m.cuePoints, nil
+}
+
+// Frames reads the frames of any track in a single pass over the clusters, handing them to the frame callback in
+// file order instead of keeping them in memory, which suits video and audio tracks
+func (m *MatroskaFile) Frames(trackNumbers []uint64, frameCallback func(trackNumber uint64, frame MatroskaSubtitle), progressCallback func(int64, int64)) error {
+	//Time code scale and default durations are needed to read the clusters
+	if m.tracks == nil {
+		segmentInfoAndTracksErr := m.readSegmentInfoAndTracks()
+		if segmentInfoAndTracksErr != nil {
+			return errors.Wrap(segmentInfoAndTracksErr, "failed to read tracks before reading frames")
+		}
+	}
+
+	matroskaFileOptions := MatroskaFileOptions{
+		FrameCallback: func(trackNumber uint64, frames []MatroskaSubtitle) {
+			for _, frame := range frames {
+				frameCallback(trackNumber, frame)
+			}
+		},
+		SubtitleTracks: trackNumbers,
+	}
+
+	readSegmentClusterErr := m.readSegmentCluster(matroskaFileOptions, progressCallback)
+	if readSegmentClusterErr != nil {
+		return errors.Wrap(readSegmentClusterErr, "failed to read frames")
+	}
+
+	return nil
+}
+
 func NewMatroskaFile(path string) (*MatroskaFile, error) {
 	file, openErr := common.NewFileStream(path)
 	if openErr != nil {
 		return nil, errors.Wrapf(openErr, "failed to open Matroska file %s", path)
 	}
 
+	matroskaFile, matroskaFileErr := newMatroskaFile(file, path)
+	if matroskaFileErr != nil {
+		file.Close()
+
+		return nil, errors.Wrapf(matroskaFileErr, "failed to open Matroska file %s", path)
+	}
+
+	return matroskaFile, nil
+}
+
+func newMatroskaFile(file common.Stream, path string) (*MatroskaFile, error) {
 	matroskaFile := &MatroskaFile{file: file, isOpen: true, IsValid: false, Path: path}
 
 	headerElement, headerErr := matroskaFile.readElement()
@@ -69,17 +163,33 @@ func NewMatroskaFile(path string) (*MatroskaFile, error) {
 	}
 
 	if headerElement != InvalidElement && headerElement.Id == ElementEbml {
-		_, seekErr := matroskaFile.file.Seek(headerElement.DataSize, io.SeekCurrent)
+		docType, docTypeErr := matroskaFile.readDocType(headerElement)
+		if docTypeErr != nil {
+			return nil, errors.Wrap(docTypeErr, "failed to read EBML header")
+		}
+
+		if docType != DocTypeMatroska && docType != DocTypeWebM {
+			return nil, errors.Newf("unsupported EBML document type %q", docType)
+		}
+
+		matroskaFile.DocType = docType
+
+		_, seekErr := matroskaFile.file.Seek(headerElement.EndPosition(), io.SeekStart)
 		if seekErr != nil {
-			return nil, errors.Wrapf(seekErr, "failed to seek while opening Matroska file %s", path)
+			return nil, errors.Wrap(seekErr, "failed to seek past EBML header")
 		}
 
 		segmentElement, segmentErr := matroskaFile.readElement()
 		if segmentErr != nil {
-			return nil, errors.Wrapf(segmentErr, "failed to read segment element while opening Matroska file %s", path)
+			return nil, errors.Wrap(segmentErr, "failed to read segment element")
 		}
 
 		if segmentElement != InvalidElement && segmentElement.Id == ElementSegment {
+			//A truncated file, such as a recording still being written, is read up to where it ends
+			if segmentElement.EndPosition() > file.Size() {
+				segmentElement.DataSize = file.Size() - segmentElement.DataPosition
+			}
+
 			matroskaFile.IsValid = true
 			matroskaFile.SegmentElement = &segmentElement
 
@@ -87,24 +197,122 @@ func NewMatroskaFile(path string) (*MatroskaFile, error) {
 		}
 	}
 
-	return nil, errors.Newf("failed to read header of Matroska file %s", path)
+	return nil, errors.New("failed to read header of Matroska file")
+}
+
+// IsWebM reports whether the file is a WebM file, the subset of Matroska used on the web
+func (m *MatroskaFile) IsWebM() bool {
+	return m.DocType == DocTypeWebM
+}
+
+// NewMatroskaFileFromReaderAt reads a Matroska file of the given size from a reader, such as a bytes.Reader or a
+// reader issuing range requests. Closing the Matroska file does not close the reader
+func NewMatroskaFileFromReaderAt(reader io.ReaderAt, size int64) (*MatroskaFile, error) {
+	matroskaFile, matroskaFileErr := newMatroskaFile(common.NewReaderAtStream(reader, size), "")
+	if matroskaFileErr != nil {
+		return nil, errors.Wrap(matroskaFileErr, "failed to open Matroska file from reader")
+	}
+
+	return matroskaFile, nil
+}
+
+// NewMatroskaFileFromReadSeeker reads a Matroska file from a reader, such as a file opened from an fs.FS.
+// Closing the Matroska file does not close the reader
+func NewMatroskaFileFromReadSeeker(reader io.ReadSeeker) (*MatroskaFile, error) {
+	stream, streamErr := common.NewReadSeekerStream(reader)
+	if streamErr != nil {
+		return nil, errors.Wrap(streamErr, "failed to open Matroska file from reader")
+	}
+
+	matroskaFile, matroskaFileErr := newMatroskaFile(stream, "")
+	if matroskaFileErr != nil {
+		return nil, errors.Wrap(matroskaFileErr, "failed to open Matroska file from reader")
+	}
+
+	return matroskaFile, nil
 }
 
 func (m *MatroskaFile) String() string {
-	return fmt.Sprintf("Duration: %v , FrameRate: %v", m.Duration, m.FrameRate)
+	return fmt.Sprintf("Title: %v , Duration: %v , FrameRate: %v , MuxingApp: %v , WritingApp: %v", m.Title, m.Duration, m.FrameRate, m.MuxingApp, m.WritingApp)
 }
 
 func (m *MatroskaFile) Subtitle(trackNumber uint64, progressCallback func(int64, int64)) ([]MatroskaSubtitle, error) {
-	m.subtitles = nil
+	subtitles, subtitlesErr := m.Subtitles([]uint64{trackNumber}, progressCallback)
+	if subtitlesErr != nil {
+		return nil, subtitlesErr
+	}
 
-	matroskaFileOptions := MatroskaFileOptions{SubtitleTrack: trackNumber}
+	return subtitles[trackNumber], nil
+}
+
+// Subtitles reads several subtitle tracks in a single pass over the clusters, keyed by track number. Set UseCues to
+// read only the clusters the Cues point to
+func (m *MatroskaFile) Subtitles(trackNumbers []uint64, progressCallback func(int64, int64)) (map[uint64][]MatroskaSubtitle, error) {
+	m.subtitles = make(map[uint64][]MatroskaSubtitle, len(trackNumbers))
+	for _, trackNumber := range trackNumbers {
+		m.subtitles[trackNumber] = []MatroskaSubtitle{}
+	}
+
+	//Time code scale and cues are needed to read the clusters
+	if m.tracks == nil {
+		segmentInfoAndTracksErr := m.readSegmentInfoAndTracks()
+		if segmentInfoAndTracksErr != nil {
+			return nil, errors.Wrap(segmentInfoAndTracksErr, "failed to read tracks before reading subtitles")
+		}
+	}
+
+	matroskaFileOptions := MatroskaFileOptions{SubtitleTracks: trackNumbers}
 
 	readSegmentClusterErr := m.readSegmentCluster(matroskaFileOptions, progressCallback)
 	if readSegmentClusterErr != nil {
 		return nil, errors.Wrap(readSegmentClusterErr, "failed to read subtitles")
 	}
 
-	return m.subtitles, nil
+	subtitles := m.subtitles
+	m.subtitles = nil
+
+	return subtitles, nil
+}
+
+// Tags returns the tags stored in the Tags element, which is empty if the file has no tags
+func (m *MatroskaFile) Tags() ([]MatroskaTag, error) {
+	if m.tracks == nil {
+		segmentInfoAndTracksErr := m.readSegmentInfoAndTracks()
+		if segmentInfoAndTracksErr != nil {
+			return nil, errors.Wrap(segmentInfoAndTracksErr, "failed to read tags")
+		}
+	}
+
+	if m.tags == nil {
+		return []MatroskaTag{}, nil
+	}
+
+	return m.tags, nil
+}
+
+// TrackTags returns the simple tags targeting a track, such as the BPS and NUMBER_OF_FRAMES statistics written by
+// mkvmerge, keyed by tag name
+func (m *MatroskaFile) TrackTags(trackUid uint64) (map[string]MatroskaSimpleTag, error) {
+	tags, tagsErr := m.Tags()
+	if tagsErr != nil {
+		return nil, tagsErr
+	}
+
+	trackTags := map[string]MatroskaSimpleTag{}
+	for _, tag := range tags {
+		if !tag.AppliesToTrack(trackUid) {
+			continue
+		}
+
+		for _, simpleTag := range tag.SimpleTags {
+			//The first default tag of a name wins over translations of it
+			if existingTag, exists := trackTags[simpleTag.Name]; !exists || (!existingTag.IsDefault && simpleTag.IsDefault) {
+				trackTags[simpleTag.Name] = simpleTag
+			}
+		}
+	}
+
+	return trackTags, nil
 }
 
 func (m *MatroskaFile) Tracks(subtitleOnly bool) ([]MatroskaTrackInfo, error) {
@@ -131,3 +339,57 @@ func (m *MatroskaFile) Tracks(subtitleOnly bool) ([]MatroskaTrackInfo, error) {
 
 	return m.tracks, nil
 }
+
+// WebVttCues reads a WebVTT track of a Matroska or WebM file as WebVTT cues, keeping their settings and identifiers
+func (m *MatroskaFile) WebVttCues(trackNumber uint64, progressCallback func(int64, int64)) ([]MatroskaWebVttCue, error) {
+	subtitles, subtitlesErr := m.Subtitle(trackNumber, progressCallback)
+	if subtitlesErr != nil {
+		return nil, errors.Wrap(subtitlesErr, "failed to read WebVTT track")
+	}
+
+	trackIndex := slices.IndexFunc(m.tracks, func(track MatroskaTrackInfo) bool {
+		return uint64(track.TrackNumber) == trackNumber
+	})
+	if trackIndex < 0 {
+		return nil, errors.Newf("track %d does not exist", trackNumber)
+	}
+
+	cues := make([]MatroskaWebVttCue, 0, len(subtitles))
+	for _, subtitle := range subtitles {
+		cue, cueErr := NewMatroskaWebVttCue(subtitle, m.tracks[trackIndex])
+		if cueErr != nil {
+			return nil, errors.Wrap(cueErr, "failed to read WebVTT cue")
+		}
+
+		cues = append(cues, *cue)
+	}
+
+	return cues, nil
+}
+
+// WebVttDocument reads a WebVTT track as a complete WebVTT file, including the header, styles and regions that
+// Matroska stores in the codec private data
+func (m *MatroskaFile) WebVttDocument(trackNumber uint64, progressCallback func(int64, int64)) (string, error) {
+	cues, cuesErr := m.WebVttCues(trackNumber, progressCallback)
+	if cuesErr != nil {
+		return "", cuesErr
+	}
+
+	header := "WEBVTT"
+	for _, track := range m.tracks {
+		if uint64(track.TrackNumber) == trackNumber && strings.HasPrefix(string(track.CodecPrivate), "WEBVTT") {
+			header = strings.TrimRight(strings.ReplaceAll(string(track.CodecPrivate), "\r\n", "\n"), "\n\x00")
+		}
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(header)
+	builder.WriteString("\n")
+
+	for _, cue := range cues {
+		builder.WriteString("\n")
+		builder.WriteString(cue.String())
+	}
+
+	return builder.String(), nil
+}
@@ -1,5 +1,27 @@
 package matroska
 
+import "slices"
+
 type MatroskaFileOptions struct {
-	SubtitleTrack uint64
+	//FrameCallback receives the frames of the tracks as they are read instead of collecting them
+	FrameCallback func(trackNumber uint64, frames []MatroskaSubtitle)
+	//SubtitleTrack is a single track to read, in addition to SubtitleTracks
+	//
+	//Deprecated: use SubtitleTracks instead
+	SubtitleTrack  uint64
+	SubtitleTracks []uint64
+}
+
+// HasSubtitleTrack reports whether a track is one of the SubtitleTracks or the deprecated SubtitleTrack
+func (m *MatroskaFileOptions) HasSubtitleTrack(trackNumber uint64) bool {
+	return slices.Contains(m.SubtitleTracks, trackNumber) || m.SubtitleTrack != 0 && m.SubtitleTrack == trackNumber
+}
+
+// subtitleTracks returns the SubtitleTracks and the deprecated SubtitleTrack
+func (m *MatroskaFileOptions) subtitleTracks() []uint64 {
+	if m.SubtitleTrack == 0 || slices.Contains(m.SubtitleTracks, m.SubtitleTrack) {
+		return m.SubtitleTracks
+	}
+
+	return append(slices.Clone(m.SubtitleTracks), m.SubtitleTrack)
 }
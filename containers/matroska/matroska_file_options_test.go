@@ -0,0 +1,22 @@
+package matroska
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHasSubtitleTrackAcceptsDeprecatedSubtitleTrack(t *testing.T) {
+	options := MatroskaFileOptions{SubtitleTrack: 3, SubtitleTracks: []uint64{1, 2}}
+
+	for trackNumber, expected := range map[uint64]bool{0: false, 1: true, 2: true, 3: true, 4: false} {
+		if actual := options.HasSubtitleTrack(trackNumber); actual != expected {
+			t.Errorf("HasSubtitleTrack(%d) = %v, expected %v", trackNumber, actual, expected)
+		}
+	}
+	if tracks := options.subtitleTracks(); !slices.Equal(tracks, []uint64{1, 2, 3}) {
+		t.Errorf("subtitleTracks() = %v, expected [1 2 3]", tracks)
+	}
+	if old := (&MatroskaFileOptions{SubtitleTrack: 5}); !old.HasSubtitleTrack(5) || !slices.Equal(old.subtitleTracks(), []uint64{5}) {
+		t.Errorf("a lone SubtitleTrack is not read")
+	}
+}
@@ -1,19 +1,191 @@
 package matroska
 
 import (
+	"bytes"
+	"cmp"
 	"encoding/binary"
 	"io"
 	"math"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/cockroachdb/errors"
-	"golang.org/x/sys/cpu"
 )
 
+var errInvalidCuePosition = errors.New("cue position does not point to a cluster")
+
+// addFrames hands the frames of a track to the frame callback or keeps them for later
+func (m *MatroskaFile) addFrames(trackNumber uint64, frames []MatroskaSubtitle, options MatroskaFileOptions) {
+	if options.FrameCallback != nil {
+		options.FrameCallback(trackNumber, frames)
+
+		return
+	}
+
+	m.subtitles[trackNumber] = append(m.subtitles[trackNumber], frames...)
+}
+
+// cueClusters returns the positions of the clusters containing blocks of the tracks according to the Cues, sorted
+// by position. Muxers such as mkvmerge and FFmpeg index every block of subtitle tracks but only keyframes of other
+// tracks, so if any of the tracks is not a subtitle track or is missing from the Cues nothing is returned, as its
+// blocks could be in any cluster. Every block of the returned clusters is read, not only the indexed blocks, but
+// blocks in clusters without a cue for their track are missed, see MatroskaFile.UseCues
+func (m *MatroskaFile) cueClusters(trackNumbers []uint64) []int64 {
+	for _, trackNumber := range trackNumbers {
+		if !slices.ContainsFunc(m.tracks, func(track MatroskaTrackInfo) bool {
+			return uint64(track.TrackNumber) == trackNumber && track.IsSubtitle
+		}) {
+			return nil
+		}
+	}
+
+	positions := []int64{}
+	indexedTracks := map[uint64]bool{}
+
+	for _, cuePoint := range m.cuePoints {
+		for _, trackPosition := range cuePoint.TrackPositions {
+			if !slices.Contains(trackNumbers, trackPosition.Track) {
+				continue
+			}
+
+			indexedTracks[trackPosition.Track] = true
+
+			position := m.SegmentElement.DataPosition + int64(trackPosition.ClusterPosition)
+			if !slices.Contains(positions, position) {
+				positions = append(positions, position)
+			}
+		}
+	}
+
+	for _, trackNumber := range trackNumbers {
+		if !indexedTracks[trackNumber] {
+			return nil
+		}
+	}
+
+	slices.Sort(positions)
+
+	return positions
+}
+
+// distributeBlockDuration applies the duration of a block group to its frames, laced frames without a
+// default duration of their track share the block duration evenly
+func distributeBlockDuration(subtitles []MatroskaSubtitle, blockDuration float64, defaultDuration float64) {
+	if len(subtitles) == 1 {
+		subtitles[0].Duration = int64(math.Round(blockDuration))
+
+		return
+	}
+
+	if defaultDuration > 0 {
+		return
+	}
+
+	frameDuration := blockDuration / float64(len(subtitles))
+	blockStart := float64(subtitles[0].Start)
+	for i := range subtitles {
+		subtitles[i].Start = int64(math.Round(blockStart + float64(i)*frameDuration))
+		subtitles[i].Duration = int64(math.Round(frameDuration))
+	}
+}
+
+func (m *MatroskaFile) readAudioElement(audioElement Element, track *MatroskaTrackInfo) error {
+	element := EmptyElement
+	var elementErr error
+
+	//Defaults of the Matroska specification
+	track.Channels = 1
+	track.SamplingFrequency = 8000
+
+	for m.file.Position() < audioElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return errors.Wrap(elementErr, "failed to read audio element")
+		}
+
+		switch element.Id {
+		case ElementSamplingFrequency:
+			samplingFrequency, samplingFrequencyErr := m.readFloat(int(element.DataSize))
+			if samplingFrequencyErr != nil {
+				return errors.Wrap(samplingFrequencyErr, "failed to read sampling frequency")
+			}
+
+			track.SamplingFrequency = samplingFrequency
+		case ElementOutputSamplingFrequency:
+			outputSamplingFrequency, outputSamplingFrequencyErr := m.readFloat(int(element.DataSize))
+			if outputSamplingFrequencyErr != nil {
+				return errors.Wrap(outputSamplingFrequencyErr, "failed to read output sampling frequency")
+			}
+
+			track.OutputSamplingFrequency = outputSamplingFrequency
+		case ElementChannels:
+			channels, channelsErr := m.readUInt(int(element.DataSize))
+			if channelsErr != nil {
+				return errors.Wrap(channelsErr, "failed to read channel count")
+			}
+
+			track.Channels = int(channels)
+		case ElementBitDepth:
+			bitDepth, bitDepthErr := m.readUInt(int(element.DataSize))
+			if bitDepthErr != nil {
+				return errors.Wrap(bitDepthErr, "failed to read bit depth")
+			}
+
+			track.BitDepth = int(bitDepth)
+		}
+
+		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to seek while reading audio element")
+		}
+	}
+
+	//Without an explicit output frequency it is the same as the sampling frequency
+	if track.OutputSamplingFrequency == 0 {
+		track.OutputSamplingFrequency = track.SamplingFrequency
+	}
+
+	return nil
+}
+
+func (m *MatroskaFile) readBlockAdditionsElement(blockAdditionsElement Element) ([]MatroskaBlockAddition, error) {
+	blockAdditions := []MatroskaBlockAddition{}
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < blockAdditionsElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read block additions element")
+		}
+
+		if element.Id == ElementBlockMore {
+			blockAddition, blockAdditionErr := m.readBlockMoreElement(element)
+			if blockAdditionErr != nil {
+				return nil, errors.Wrap(blockAdditionErr, "failed to read block more element")
+			}
+
+			blockAdditions = append(blockAdditions, *blockAddition)
+		}
+
+		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return nil, errors.Wrap(seekErr, "failed to advance to next block addition")
+		}
+	}
+
+	return blockAdditions, nil
+}
+
 func (m *MatroskaFile) readBlockGroupElement(clusterElement Element, clusterTimeCode int64, options MatroskaFileOptions) error {
 	element := EmptyElement
 	var elementErr error
-	var subtitle *MatroskaSubtitle
-	var subtitleErr error
+	var blockAdditions []MatroskaBlockAddition
+	var subtitles []MatroskaSubtitle
+	var subtitlesErr error
+	var trackNumber uint64
+	blockDuration := float64(-1)
 
 	for m.file.Position() < clusterElement.EndPosition() && element != InvalidElement {
 		element, elementErr = m.readElement()
@@ -22,18 +194,14 @@ func (m *MatroskaFile) readBlockGroupElement(clusterElement Element, clusterTime
 		}
 
 		if element == InvalidElement {
-			return nil
+			break
 		}
 
 		switch element.Id {
 		case ElementBlock:
-			subtitle, subtitleErr = m.readSubtitleBlock(element, clusterTimeCode, options)
-			if subtitleErr != nil {
-				return errors.Wrap(subtitleErr, "failed to read subtitle block")
-			}
-
-			if subtitle != nil {
-				m.subtitles = append(m.subtitles, *subtitle)
+			trackNumber, subtitles, subtitlesErr = m.readSubtitleBlock(element, clusterTimeCode, options)
+			if subtitlesErr != nil {
+				return errors.Wrap(subtitlesErr, "failed to read subtitle block")
 			}
 		case ElementBlockDuration:
 			duration, durationErr := m.readUInt(int(element.DataSize))
@@ -41,8 +209,13 @@ func (m *MatroskaFile) readBlockGroupElement(clusterElement Element, clusterTime
 				return errors.Wrap(durationErr, "failed to read block duration element")
 			}
 
-			if subtitle != nil {
-				subtitle.Duration = int64(math.Round(m.scaleTime64(float64(duration))))
+			blockDuration = m.scaleTime64(float64(duration))
+		case ElementBlockAdditions:
+			var blockAdditionsErr error
+
+			blockAdditions, blockAdditionsErr = m.readBlockAdditionsElement(element)
+			if blockAdditionsErr != nil {
+				return errors.Wrap(blockAdditionsErr, "failed to read block additions element")
 			}
 		default:
 			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
@@ -52,9 +225,59 @@ func (m *MatroskaFile) readBlockGroupElement(clusterElement Element, clusterTime
 		}
 	}
 
+	//The block duration usually follows the block, so the subtitles are only added once the whole group is read
+	if len(subtitles) > 0 {
+		if blockDuration >= 0 {
+			distributeBlockDuration(subtitles, blockDuration, m.trackDefaultDuration(trackNumber))
+		}
+
+		for i := range subtitles {
+			subtitles[i].BlockAdditions = blockAdditions
+		}
+
+		m.addFrames(trackNumber, subtitles, options)
+	}
+
 	return nil
 }
 
+func (m *MatroskaFile) readBlockMoreElement(blockMoreElement Element) (*MatroskaBlockAddition, error) {
+	blockAddition := &MatroskaBlockAddition{Id: BlockAddIdDefault}
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < blockMoreElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read block more element")
+		}
+
+		switch element.Id {
+		case ElementBlockAddId:
+			blockAddId, blockAddIdErr := m.readUInt(int(element.DataSize))
+			if blockAddIdErr != nil {
+				return nil, errors.Wrap(blockAddIdErr, "failed to read block addition id")
+			}
+
+			blockAddition.Id = blockAddId
+		case ElementBlockAdditional:
+			data, dataErr := m.readElementData(element)
+			if dataErr != nil {
+				return nil, errors.Wrap(dataErr, "failed to read block additional data")
+			}
+
+			blockAddition.Data = data
+		}
+
+		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return nil, errors.Wrap(seekErr, "failed to advance to next block more element")
+		}
+	}
+
+	return blockAddition, nil
+}
+
 func (m *MatroskaFile) readCluster(clusterElement Element, options MatroskaFileOptions) error {
 	clusterTimeCode := int64(0)
 	element := EmptyElement
@@ -66,7 +289,8 @@ func (m *MatroskaFile) readCluster(clusterElement Element, options MatroskaFileO
 			return errors.Wrap(elementErr, "failed to read cluster element")
 		}
 
-		if element == InvalidElement {
+		//A block cut off by the end of a truncated file is left out
+		if element == InvalidElement || element.EndPosition() > clusterElement.EndPosition() {
 			return nil
 		}
 
@@ -84,13 +308,13 @@ func (m *MatroskaFile) readCluster(clusterElement Element, options MatroskaFileO
 				return errors.Wrap(blockGroupElementErr, "failed to read block group element")
 			}
 		case ElementSimpleBlock:
-			subtitle, subtitleErr := m.readSubtitleBlock(element, clusterTimeCode, options)
-			if subtitleErr != nil {
-				return errors.Wrap(subtitleErr, "failed to read subtitle block")
+			trackNumber, subtitles, subtitlesErr := m.readSubtitleBlock(element, clusterTimeCode, options)
+			if subtitlesErr != nil {
+				return errors.Wrap(subtitlesErr, "failed to read subtitle block")
 			}
 
-			if subtitle != nil {
-				m.subtitles = append(m.subtitles, *subtitle)
+			if len(subtitles) > 0 {
+				m.addFrames(trackNumber, subtitles, options)
 			}
 		default:
 			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
@@ -103,37 +327,71 @@ func (m *MatroskaFile) readCluster(clusterElement Element, options MatroskaFileO
 	return nil
 }
 
-func (m *MatroskaFile) readContentEncodingElement(contentEncodingElement Element) (int, int, uint, error) {
-	contentCompressionAlgorithm, contentEncodingType, contentEncodingScope := 0, 0, uint(1)
+// readClusterTimeCode reads the children of a cluster until its time code is found, it must precede all blocks
+func (m *MatroskaFile) readClusterTimeCode(clusterElement Element) (int64, bool, error) {
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < clusterElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return 0, false, errors.Wrap(elementErr, "failed to read cluster element")
+		}
+
+		switch element.Id {
+		case ElementTimecode:
+			clusterTimeCode, clusterTimeCodeErr := m.readUInt(int(element.DataSize))
+			if clusterTimeCodeErr != nil {
+				return 0, false, errors.Wrap(clusterTimeCodeErr, "failed to read cluster time code")
+			}
+
+			return int64(clusterTimeCode), true, nil
+		case ElementSimpleBlock, ElementBlockGroup:
+			return 0, false, nil
+		default:
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return 0, false, errors.Wrap(seekErr, "failed to seek while searching for cluster time code")
+			}
+		}
+	}
+
+	return 0, false, nil
+}
+
+func (m *MatroskaFile) readContentEncodingElement(contentEncodingElement Element) (*MatroskaContentEncoding, error) {
+	contentEncoding := &MatroskaContentEncoding{Scope: ContentEncodingScopeTracks, Type: ContentEncodingTypeCompression}
 	element := EmptyElement
 	var elementErr error
 
 	for m.file.Position() < contentEncodingElement.EndPosition() && element != InvalidElement {
 		element, elementErr = m.readElement()
 		if elementErr != nil {
-			return 0, 0, 0, errors.Wrap(elementErr, "failed to read content encoding element")
+			return nil, errors.Wrap(elementErr, "failed to read content encoding element")
 		}
 
 		switch element.Id {
 		case ElementContentEncodingOrder:
-			_, contentEncodingOrderErr := m.readUInt(int(contentEncodingElement.DataSize))
+			ceo, contentEncodingOrderErr := m.readUInt(int(element.DataSize))
 			if contentEncodingOrderErr != nil {
-				return 0, 0, 0, errors.Wrap(contentEncodingOrderErr, "failed to read content encoding order")
+				return nil, errors.Wrap(contentEncodingOrderErr, "failed to read content encoding order")
 			}
+
+			contentEncoding.Order = ceo
 		case ElementContentEncodingScope:
-			ces, contentEncodingScopeErr := m.readUInt(int(contentEncodingElement.DataSize))
+			ces, contentEncodingScopeErr := m.readUInt(int(element.DataSize))
 			if contentEncodingScopeErr != nil {
-				return 0, 0, 0, errors.Wrap(contentEncodingScopeErr, "failed to read content encoding scope")
+				return nil, errors.Wrap(contentEncodingScopeErr, "failed to read content encoding scope")
 			}
 
-			contentEncodingScope = uint(ces)
+			contentEncoding.Scope = uint(ces)
 		case ElementContentEncodingType:
-			cet, pixelHeightErr := m.readUInt(int(contentEncodingElement.DataSize))
-			if pixelHeightErr != nil {
-				return 0, 0, 0, errors.Wrap(pixelHeightErr, "failed to read content encoding type")
+			cet, contentEncodingTypeErr := m.readUInt(int(element.DataSize))
+			if contentEncodingTypeErr != nil {
+				return nil, errors.Wrap(contentEncodingTypeErr, "failed to read content encoding type")
 			}
 
-			contentEncodingType = int(cet)
+			contentEncoding.Type = int(cet)
 		case ElementContentCompression:
 			compressionElement := EmptyElement
 			var compressionElementErr error
@@ -141,38 +399,254 @@ func (m *MatroskaFile) readContentEncodingElement(contentEncodingElement Element
 			for m.file.Position() < element.EndPosition() && compressionElement != InvalidElement {
 				compressionElement, compressionElementErr = m.readElement()
 				if compressionElementErr != nil {
-					return 0, 0, 0, errors.Wrap(elementErr, "failed to read content compression element")
+					return nil, errors.Wrap(compressionElementErr, "failed to read content compression element")
 				}
 
 				switch compressionElement.Id {
 				case ElementContentCompAlgo:
-					cca, pixelHeightErr := m.readUInt(int(compressionElement.DataSize))
-					if pixelHeightErr != nil {
-						return 0, 0, 0, errors.Wrap(pixelHeightErr, "failed to read content compression algorithm")
+					cca, contentCompAlgoErr := m.readUInt(int(compressionElement.DataSize))
+					if contentCompAlgoErr != nil {
+						return nil, errors.Wrap(contentCompAlgoErr, "failed to read content compression algorithm")
 					}
 
-					contentCompressionAlgorithm = int(cca)
+					contentEncoding.CompressionAlgorithm = int(cca)
 				case ElementContentCompSettings:
-					_, contentCompSettingsErr := m.readUInt(int(compressionElement.DataSize))
-					if contentCompSettingsErr != nil {
-						return 0, 0, 0, errors.Wrap(contentCompSettingsErr, "failed to read content encoding order")
+					contentCompSettings := make([]byte, compressionElement.DataSize)
+					bytesRead, readErr := m.file.Read(contentCompSettings)
+					if (bytesRead == 0 && compressionElement.DataSize > 0) || (readErr != nil && readErr != io.EOF) {
+						return nil, errors.Wrap(readErr, "failed to read content compression settings")
 					}
+
+					contentEncoding.CompressionSettings = contentCompSettings[:bytesRead]
 				default:
-					_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+					_, seekErr := m.file.Seek(compressionElement.DataSize, io.SeekCurrent)
 					if seekErr != nil {
-						return 0, 0, 0, errors.Wrap(seekErr, "failed to seek while reading content compression element")
+						return nil, errors.Wrap(seekErr, "failed to seek while reading content compression element")
 					}
 				}
 			}
+		case ElementContentEncryption:
+			//Only the presence matters, the content cannot be decrypted
+			contentEncoding.Type = ContentEncodingTypeEncryption
+
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return nil, errors.Wrap(seekErr, "failed to seek while reading content encryption element")
+			}
+		default:
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return nil, errors.Wrap(seekErr, "failed to seek while reading content encoding element")
+			}
+		}
+	}
+
+	return contentEncoding, nil
+}
+
+func (m *MatroskaFile) readContentEncodingsElement(contentEncodingsElement Element) ([]MatroskaContentEncoding, error) {
+	contentEncodings := []MatroskaContentEncoding{}
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < contentEncodingsElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read content encodings element")
+		}
+
+		if element.Id == ElementContentEncoding {
+			contentEncoding, contentEncodingErr := m.readContentEncodingElement(element)
+			if contentEncodingErr != nil {
+				return nil, errors.Wrap(contentEncodingErr, "failed to read content encoding")
+			}
+
+			contentEncodings = append(contentEncodings, *contentEncoding)
+		} else {
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return nil, errors.Wrap(seekErr, "failed to advance to next content encoding")
+			}
+		}
+	}
+
+	//Decoding starts with the highest order number
+	slices.SortStableFunc(contentEncodings, func(a, b MatroskaContentEncoding) int {
+		return cmp.Compare(b.Order, a.Order)
+	})
+
+	return contentEncodings, nil
+}
+
+// readCueClusters reads every block of the clusters that the Cues point to
+func (m *MatroskaFile) readCueClusters(clusterPositions []int64, options MatroskaFileOptions, progressCallback func(int64, int64)) error {
+	for _, clusterPosition := range clusterPositions {
+		_, seekErr := m.file.Seek(clusterPosition, io.SeekStart)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to advance to cue cluster")
+		}
+
+		clusterElement, clusterElementErr := m.readElement()
+		if clusterElementErr != nil {
+			return errors.Wrap(clusterElementErr, "failed to read cue cluster")
+		}
+
+		if clusterElement.Id != ElementCluster {
+			return errInvalidCuePosition
+		}
+
+		clusterErr := m.readCluster(clusterElement, options)
+		if clusterErr != nil {
+			return errors.Wrap(clusterErr, "failed to read cue cluster")
+		}
+
+		if progressCallback != nil {
+			progressCallback(clusterElement.EndPosition(), m.file.Size())
+		}
+	}
+
+	return nil
+}
+
+func (m *MatroskaFile) readCuePointElement(cuePointElement Element) (*MatroskaCuePoint, error) {
+	element := EmptyElement
+	var elementErr error
+	cuePoint := &MatroskaCuePoint{}
+
+	for m.file.Position() < cuePointElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read cue point element")
+		}
+
+		switch element.Id {
+		case ElementCueTime:
+			cueTime, cueTimeErr := m.readUInt(int(element.DataSize))
+			if cueTimeErr != nil {
+				return nil, errors.Wrap(cueTimeErr, "failed to read cue time")
+			}
+
+			cuePoint.Time = cueTime
+		case ElementCueTrackPositions:
+			trackPosition, trackPositionErr := m.readCueTrackPositionsElement(element)
+			if trackPositionErr != nil {
+				return nil, errors.Wrap(trackPositionErr, "failed to read cue track positions")
+			}
+
+			cuePoint.TrackPositions = append(cuePoint.TrackPositions, *trackPosition)
+		default:
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return nil, errors.Wrap(seekErr, "failed to advance to next cue point element")
+			}
+		}
+	}
+
+	return cuePoint, nil
+}
+
+func (m *MatroskaFile) readCuesElement(cuesElement Element) error {
+	m.cuePoints = []MatroskaCuePoint{}
+
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < cuesElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return errors.Wrap(elementErr, "failed to read cues element")
+		}
+
+		if element.Id == ElementCuePoint {
+			cuePoint, cuePointErr := m.readCuePointElement(element)
+			if cuePointErr != nil {
+				return errors.Wrap(cuePointErr, "failed to read cue point element")
+			}
+
+			m.cuePoints = append(m.cuePoints, *cuePoint)
+		} else {
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return errors.Wrap(seekErr, "failed to advance to next cue point")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *MatroskaFile) readCueTrackPositionsElement(cueTrackPositionsElement Element) (*MatroskaCueTrackPosition, error) {
+	element := EmptyElement
+	var elementErr error
+	trackPosition := &MatroskaCueTrackPosition{}
+
+	for m.file.Position() < cueTrackPositionsElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read cue track positions element")
+		}
+
+		var value uint64
+		var valueErr error
+
+		switch element.Id {
+		case ElementCueTrack, ElementCueClusterPosition, ElementCueRelativePosition, ElementCueDuration, ElementCueBlockNumber:
+			value, valueErr = m.readUInt(int(element.DataSize))
+			if valueErr != nil {
+				return nil, errors.Wrap(valueErr, "failed to read cue track position value")
+			}
 		default:
 			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
 			if seekErr != nil {
-				return 0, 0, 0, errors.Wrap(seekErr, "failed to seek while reading content encoding element")
+				return nil, errors.Wrap(seekErr, "failed to advance to next cue track positions element")
 			}
 		}
+
+		switch element.Id {
+		case ElementCueTrack:
+			trackPosition.Track = value
+		case ElementCueClusterPosition:
+			trackPosition.ClusterPosition = value
+		case ElementCueRelativePosition:
+			trackPosition.RelativePosition = value
+		case ElementCueDuration:
+			trackPosition.Duration = value
+		case ElementCueBlockNumber:
+			trackPosition.BlockNumber = value
+		}
+	}
+
+	return trackPosition, nil
+}
+
+// readDocType returns the document type stored in the EBML header, which is "matroska" when it is missing
+func (m *MatroskaFile) readDocType(headerElement Element) (string, error) {
+	docType := DocTypeMatroska
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < headerElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return "", errors.Wrap(elementErr, "failed to read EBML header element")
+		}
+
+		if element.Id == ElementDocType {
+			var docTypeErr error
+
+			docType, docTypeErr = m.readString(int(element.DataSize))
+			if docTypeErr != nil {
+				return "", errors.Wrap(docTypeErr, "failed to read document type")
+			}
+		}
+
+		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return "", errors.Wrap(seekErr, "failed to advance to next EBML header element")
+		}
 	}
 
-	return contentCompressionAlgorithm, contentEncodingType, contentEncodingScope, nil
+	return docType, nil
 }
 
 func (m *MatroskaFile) readElement() (Element, error) {
@@ -186,12 +660,74 @@ func (m *MatroskaFile) readElement() (Element, error) {
 		return InvalidElement, nil
 	}
 
-	sizeElement, sizeErr := m.readVariableLengthUIntDefault()
+	size, sizeErr := m.readElementSize(id)
 	if sizeErr != nil {
 		return InvalidElement, errors.Wrap(sizeErr, "failed to read size element from Matroska file")
 	}
 
-	return *NewElement(id, m.file.Position(), int64(sizeElement)), nil
+	return *NewElement(id, m.file.Position(), size), nil
+}
+
+// readElementData reads the whole payload of an element, leaving the position at its end
+func (m *MatroskaFile) readElementData(element Element) ([]byte, error) {
+	_, seekErr := m.file.Seek(element.DataPosition, io.SeekStart)
+	if seekErr != nil {
+		return nil, errors.Wrap(seekErr, "failed to advance to element data")
+	}
+
+	data := make([]byte, element.DataSize)
+	_, readErr := io.ReadFull(m.file, data)
+	if readErr != nil {
+		return nil, errors.Wrap(readErr, "failed to read element data")
+	}
+
+	return data, nil
+}
+
+// readElementSize reads the size following an element id, an unknown size is resolved to where the element ends
+func (m *MatroskaFile) readElementSize(id ElementId) (int64, error) {
+	sizePosition := m.file.Position()
+
+	size, sizeErr := m.readVariableLengthUIntDefault()
+	if sizeErr != nil {
+		return 0, sizeErr
+	}
+
+	//A size with all its value bits set means the size is unknown, as written by live muxers
+	sizeLength := m.file.Position() - sizePosition
+	if sizeLength == 0 || sizeLength > 8 || size != (uint64(1)<<(7*sizeLength))-1 {
+		return int64(size), nil
+	}
+
+	dataPosition := m.file.Position()
+
+	unknownSize, unknownSizeErr := m.resolveUnknownSize(id, dataPosition)
+	if unknownSizeErr != nil {
+		return 0, errors.Wrap(unknownSizeErr, "failed to resolve unknown element size")
+	}
+
+	_, seekErr := m.file.Seek(dataPosition, io.SeekStart)
+	if seekErr != nil {
+		return 0, errors.Wrap(seekErr, "failed to return to element data")
+	}
+
+	return unknownSize, nil
+}
+
+// readFloat reads a float element, which is stored in either 4 or 8 bytes or is empty for zero
+func (m *MatroskaFile) readFloat(length int) (float64, error) {
+	switch length {
+	case 0:
+		return 0, nil
+	case 4:
+		value, valueErr := m.readFloat32()
+
+		return float64(value), valueErr
+	case 8:
+		return m.readFloat64()
+	}
+
+	return 0, errors.Newf("invalid float length %d in Matroska file", length)
 }
 
 func (m *MatroskaFile) readFloat32() (float32, error) {
@@ -201,15 +737,8 @@ func (m *MatroskaFile) readFloat32() (float32, error) {
 		return 0, errors.Wrap(readErr, "failed to read 32-bit float from Matroska file")
 	}
 
-	var bits uint32
-
-	if cpu.IsBigEndian {
-		bits = binary.BigEndian.Uint32(data)
-	} else {
-		bits = binary.LittleEndian.Uint32(data)
-	}
-
-	return math.Float32frombits(bits), nil
+	//EBML floats are always big endian
+	return math.Float32frombits(binary.BigEndian.Uint32(data)), nil
 }
 
 func (m *MatroskaFile) readFloat64() (float64, error) {
@@ -219,15 +748,22 @@ func (m *MatroskaFile) readFloat64() (float64, error) {
 		return 0, errors.Wrap(readErr, "failed to read 64-bit float from Matroska file")
 	}
 
-	var bits uint64
+	//EBML floats are always big endian
+	return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+}
 
-	if cpu.IsBigEndian {
-		bits = binary.BigEndian.Uint64(data)
-	} else {
-		bits = binary.LittleEndian.Uint64(data)
+// readInt reads a big endian signed integer of up to 8 bytes
+func (m *MatroskaFile) readInt(length int) (int64, error) {
+	value, valueErr := m.readUInt(length)
+	if valueErr != nil {
+		return 0, errors.Wrap(valueErr, "failed to read int from Matroska file")
+	}
+
+	if length > 0 && length < 8 && value&(uint64(1)<<(8*length-1)) != 0 {
+		value |= ^uint64(0) << (8 * length)
 	}
 
-	return math.Float64frombits(bits), nil
+	return int64(value), nil
 }
 
 func (m *MatroskaFile) readInt16() (int16, error) {
@@ -237,17 +773,17 @@ func (m *MatroskaFile) readInt16() (int16, error) {
 		return 0, errors.Wrap(readErr, "failed to read 16-bit integer from Matroska file")
 	}
 
-	return int16(data[0]<<8 | data[1]), nil
+	return int16(uint16(data[0])<<8 | uint16(data[1])), nil
 }
 
-func (m *MatroskaFile) readInfoElement(tracksElement Element) error {
+func (m *MatroskaFile) readInfoElement(infoElement Element) error {
 	element := EmptyElement
 	var elementErr error
 
-	for m.file.Position() < tracksElement.EndPosition() && element != InvalidElement {
+	for m.file.Position() < infoElement.EndPosition() && element != InvalidElement {
 		element, elementErr = m.readElement()
 		if elementErr != nil {
-			return errors.Wrap(elementErr, "failed to read tracks element")
+			return errors.Wrap(elementErr, "failed to read info element")
 		}
 
 		switch element.Id {
@@ -276,6 +812,42 @@ func (m *MatroskaFile) readInfoElement(tracksElement Element) error {
 			if durationErr != nil {
 				return errors.Wrap(durationErr, "failed to read duration")
 			}
+		case ElementDateUtc:
+			//Nanoseconds since the start of the millennium
+			dateUtc, dateUtcErr := m.readInt(int(element.DataSize))
+			if dateUtcErr != nil {
+				return errors.Wrap(dateUtcErr, "failed to read date")
+			}
+
+			m.DateUtc = ebmlDateEpoch.Add(time.Duration(dateUtc))
+		case ElementMuxingApp:
+			muxingApp, muxingAppErr := m.readString(int(element.DataSize))
+			if muxingAppErr != nil {
+				return errors.Wrap(muxingAppErr, "failed to read muxing application")
+			}
+
+			m.MuxingApp = muxingApp
+		case ElementSegmentUid:
+			segmentUid, segmentUidErr := m.readElementData(element)
+			if segmentUidErr != nil {
+				return errors.Wrap(segmentUidErr, "failed to read segment uid")
+			}
+
+			m.SegmentUid = segmentUid
+		case ElementTitle:
+			title, titleErr := m.readString(int(element.DataSize))
+			if titleErr != nil {
+				return errors.Wrap(titleErr, "failed to read title")
+			}
+
+			m.Title = title
+		case ElementWritingApp:
+			writingApp, writingAppErr := m.readString(int(element.DataSize))
+			if writingAppErr != nil {
+				return errors.Wrap(writingAppErr, "failed to read writing application")
+			}
+
+			m.WritingApp = writingApp
 		default:
 			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
 			if seekErr != nil {
@@ -287,7 +859,219 @@ func (m *MatroskaFile) readInfoElement(tracksElement Element) error {
 	return nil
 }
 
+// resolveUnknownSize finds the end of an element with an unknown size, which is the start of the first element
+// that cannot be one of its children. Children that are cut off by the end of the file are left out, so that
+// recordings that are still being written can be read up to their last complete block
+func (m *MatroskaFile) resolveUnknownSize(id ElementId, dataPosition int64) (int64, error) {
+	//Nothing can follow a segment other than another segment, which is not supported
+	if id == ElementSegment {
+		return m.file.Size() - dataPosition, nil
+	}
+
+	position := dataPosition
+	for position < m.file.Size() {
+		_, seekErr := m.file.Seek(position, io.SeekStart)
+		if seekErr != nil {
+			return 0, errors.Wrap(seekErr, "failed to advance to next child element")
+		}
+
+		element, elementErr := m.readElement()
+		if elementErr != nil || element == InvalidElement || element.EndPosition() > m.file.Size() {
+			break
+		}
+
+		if element.Id == ElementEbml || element.Id == ElementSegment || slices.Contains(segmentChildIds, element.Id) {
+			break
+		}
+
+		position = element.EndPosition()
+	}
+
+	return position - dataPosition, nil
+}
+
+func (m *MatroskaFile) readSeekHeadElement(seekHeadElement Element) ([]int64, error) {
+	element := EmptyElement
+	var elementErr error
+	var seekHeadPositions []int64
+
+	for m.file.Position() < seekHeadElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read seek head element")
+		}
+
+		if element.Id != ElementSeek {
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return nil, errors.Wrap(seekErr, "failed to advance to next seek element")
+			}
+
+			continue
+		}
+
+		seekId, seekPosition := ElementNone, int64(-1)
+		seekElement := EmptyElement
+
+		for m.file.Position() < element.EndPosition() && seekElement != InvalidElement {
+			seekElement, elementErr = m.readElement()
+			if elementErr != nil {
+				return nil, errors.Wrap(elementErr, "failed to read seek element")
+			}
+
+			switch seekElement.Id {
+			case ElementSeekId:
+				id, idErr := m.readUInt(int(seekElement.DataSize))
+				if idErr != nil {
+					return nil, errors.Wrap(idErr, "failed to read seek id")
+				}
+
+				seekId = ElementId(id)
+			case ElementSeekPosition:
+				position, positionErr := m.readUInt(int(seekElement.DataSize))
+				if positionErr != nil {
+					return nil, errors.Wrap(positionErr, "failed to read seek position")
+				}
+
+				seekPosition = m.SegmentElement.DataPosition + int64(position)
+			default:
+				_, seekErr := m.file.Seek(seekElement.DataSize, io.SeekCurrent)
+				if seekErr != nil {
+					return nil, errors.Wrap(seekErr, "failed to advance to next seek element")
+				}
+			}
+		}
+
+		if seekId == ElementNone || seekPosition < 0 {
+			continue
+		}
+
+		if seekId == ElementSeekHead {
+			seekHeadPositions = append(seekHeadPositions, seekPosition)
+		} else if _, exists := m.seekPositions[seekId]; !exists {
+			m.seekPositions[seekId] = seekPosition
+		}
+	}
+
+	return seekHeadPositions, nil
+}
+
+// readSeekHeads reads the SeekHead at the start of the segment and any further SeekHeads it points to
+func (m *MatroskaFile) readSeekHeads() error {
+	m.seekPositions = map[ElementId]int64{}
+
+	seekHeadPositions := []int64{m.SegmentElement.DataPosition}
+	visitedPositions := map[int64]bool{}
+
+	for len(seekHeadPositions) > 0 {
+		position := seekHeadPositions[0]
+		seekHeadPositions = seekHeadPositions[1:]
+
+		if visitedPositions[position] || position >= m.SegmentElement.EndPosition() {
+			continue
+		}
+		visitedPositions[position] = true
+
+		_, seekErr := m.file.Seek(position, io.SeekStart)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to advance to seek head")
+		}
+
+		element, elementErr := m.readElement()
+		if elementErr != nil {
+			return errors.Wrap(elementErr, "failed to read seek head")
+		}
+
+		if element.Id != ElementSeekHead {
+			continue
+		}
+
+		positions, seekHeadErr := m.readSeekHeadElement(element)
+		if seekHeadErr != nil {
+			return errors.Wrap(seekHeadErr, "failed to read seek head element")
+		}
+
+		seekHeadPositions = append(seekHeadPositions, positions...)
+	}
+
+	return nil
+}
+
+// readSeekTarget reads the top level element that the SeekHead points to, returning false if it is not there
+func (m *MatroskaFile) readSeekTarget(id ElementId) (bool, error) {
+	position, exists := m.seekPositions[id]
+	if !exists {
+		return false, nil
+	}
+
+	_, seekErr := m.file.Seek(position, io.SeekStart)
+	if seekErr != nil {
+		return false, errors.Wrap(seekErr, "failed to advance to seek target")
+	}
+
+	element, elementErr := m.readElement()
+	if elementErr != nil || element.Id != id {
+		//A broken SeekHead should not prevent reading the file, the caller falls back to scanning
+		return false, nil
+	}
+
+	segmentChildErr := m.readSegmentChildElement(element)
+	if segmentChildErr != nil {
+		return false, errors.Wrap(segmentChildErr, "failed to read seek target")
+	}
+
+	return true, nil
+}
+
+func (m *MatroskaFile) readSegmentChildElement(element Element) error {
+	switch element.Id {
+	case ElementInfo:
+		infoError := m.readInfoElement(element)
+		if infoError != nil {
+			return errors.Wrap(infoError, "failed to read info element")
+		}
+	case ElementTracks:
+		tracksError := m.readTracksElement(element)
+		if tracksError != nil {
+			return errors.Wrap(tracksError, "failed to read tracks element")
+		}
+	case ElementCues:
+		cuesError := m.readCuesElement(element)
+		if cuesError != nil {
+			return errors.Wrap(cuesError, "failed to read cues element")
+		}
+	case ElementTags:
+		tagsError := m.readTagsElement(element)
+		if tagsError != nil {
+			return errors.Wrap(tagsError, "failed to read tags element")
+		}
+	default:
+		_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to advance to next element")
+		}
+	}
+
+	return nil
+}
+
 func (m *MatroskaFile) readSegmentCluster(options MatroskaFileOptions, progressCallback func(int64, int64)) error {
+	//Frames handed to a callback cannot be taken back if the cues turn out to be broken
+	if cueClusters := m.cueClusters(options.subtitleTracks()); len(cueClusters) > 0 && options.FrameCallback == nil && m.UseCues {
+		cueClustersErr := m.readCueClusters(cueClusters, options, progressCallback)
+		if cueClustersErr == nil {
+			return nil
+		}
+		if !errors.Is(cueClustersErr, errInvalidCuePosition) {
+			return errors.Wrap(cueClustersErr, "failed to read clusters using cues")
+		}
+
+		//The Cues are broken, read every cluster instead
+		for trackNumber := range m.subtitles {
+			m.subtitles[trackNumber] = []MatroskaSubtitle{}
+		}
+	}
+
 	//go to segment
 	_, seekErr := m.file.Seek(m.SegmentElement.DataPosition, io.SeekStart)
 	if seekErr != nil {
@@ -330,19 +1114,29 @@ func (m *MatroskaFile) readSegmentCluster(options MatroskaFileOptions, progressC
 			}
 		}
 
-		size, sizeErr := m.readVariableLengthUIntDefault()
+		size, sizeErr := m.readElementSize(elementId)
 		if sizeErr != nil {
 			return errors.Wrap(sizeErr, "failed to read size for segment cluster")
 		}
 
-		element := NewElement(elementId, m.file.Position(), int64(size))
+		element := NewElement(elementId, m.file.Position(), size)
+		if element.EndPosition() > m.file.Size() {
+			//The file is truncated, the complete blocks of the last cluster are still read
+			if element.Id != ElementCluster {
+				break
+			}
+
+			element.DataSize = m.file.Size() - element.DataPosition
+		}
+
 		if element.Id == ElementCluster {
 			m.readCluster(*element, options)
-		} else {
-			_, seekErr = m.file.Seek(element.DataSize, io.SeekCurrent)
-			if seekErr != nil {
-				return errors.Wrap(seekErr, "failed to advance while reading segment cluster")
-			}
+		}
+
+		//Reading a cluster stops before its end when its last block is cut off
+		_, seekErr = m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to advance while reading segment cluster")
 		}
 
 		if progressCallback != nil {
@@ -354,6 +1148,36 @@ func (m *MatroskaFile) readSegmentCluster(options MatroskaFileOptions, progressC
 }
 
 func (m *MatroskaFile) readSegmentInfoAndTracks() error {
+	m.cuePoints = nil
+	m.tags = nil
+
+	seekHeadsErr := m.readSeekHeads()
+	if seekHeadsErr != nil {
+		return errors.Wrap(seekHeadsErr, "failed to read seek heads")
+	}
+
+	//Jump straight to the elements if the SeekHead knows where they are
+	_, hasInfo := m.seekPositions[ElementInfo]
+	_, hasTracks := m.seekPositions[ElementTracks]
+	if hasInfo && hasTracks {
+		foundAll := true
+
+		for _, id := range []ElementId{ElementInfo, ElementTracks, ElementCues, ElementTags} {
+			found, seekTargetErr := m.readSeekTarget(id)
+			if seekTargetErr != nil {
+				return errors.Wrap(seekTargetErr, "failed to read element from seek head")
+			}
+
+			if !found && id != ElementCues && id != ElementTags {
+				foundAll = false
+			}
+		}
+
+		if foundAll {
+			return nil
+		}
+	}
+
 	//go to segment
 	_, seekErr := m.file.Seek(m.SegmentElement.DataPosition, io.SeekStart)
 	if seekErr != nil {
@@ -369,26 +1193,85 @@ func (m *MatroskaFile) readSegmentInfoAndTracks() error {
 			return errors.Wrap(elementErr, "failed to read tracks element")
 		}
 
+		segmentChildErr := m.readSegmentChildElement(element)
+		if segmentChildErr != nil {
+			return errors.Wrap(segmentChildErr, "failed to read segment element")
+		}
+	}
+
+	return nil
+}
+
+func (m *MatroskaFile) readSimpleTagElement(simpleTagElement Element) (*MatroskaSimpleTag, error) {
+	element := EmptyElement
+	var elementErr error
+	simpleTag := &MatroskaSimpleTag{IsDefault: true, Language: "und"}
+
+	for m.file.Position() < simpleTagElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read simple tag element")
+		}
+
 		switch element.Id {
-		case ElementInfo:
-			infoError := m.readInfoElement(element)
-			if infoError != nil {
-				return errors.Wrap(infoError, "failed to read info element")
+		case ElementTagName:
+			name, nameErr := m.readString(int(element.DataSize))
+			if nameErr != nil {
+				return nil, errors.Wrap(nameErr, "failed to read tag name")
 			}
-		case ElementTracks:
-			tracksError := m.readTracksElement(element)
-			if tracksError != nil {
-				return errors.Wrap(tracksError, "failed to read tracks element")
+
+			simpleTag.Name = name
+		case ElementTagString:
+			value, valueErr := m.readString(int(element.DataSize))
+			if valueErr != nil {
+				return nil, errors.Wrap(valueErr, "failed to read tag string")
 			}
-		default:
-			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
-			if seekErr != nil {
-				return errors.Wrap(seekErr, "failed to advance to next element")
+
+			simpleTag.Value = value
+		case ElementTagBinary:
+			tagBinary, tagBinaryErr := m.readElementData(element)
+			if tagBinaryErr != nil {
+				return nil, errors.Wrap(tagBinaryErr, "failed to read tag binary")
+			}
+
+			simpleTag.Binary = tagBinary
+		case ElementTagLanguage:
+			language, languageErr := m.readString(int(element.DataSize))
+			if languageErr != nil {
+				return nil, errors.Wrap(languageErr, "failed to read tag language")
+			}
+
+			simpleTag.Language = language
+		case ElementTagLanguageBcp47:
+			languageBcp47, languageBcp47Err := m.readString(int(element.DataSize))
+			if languageBcp47Err != nil {
+				return nil, errors.Wrap(languageBcp47Err, "failed to read tag BCP 47 language")
+			}
+
+			simpleTag.LanguageBcp47 = languageBcp47
+		case ElementTagDefault:
+			tagDefault, tagDefaultErr := m.readUInt(int(element.DataSize))
+			if tagDefaultErr != nil {
+				return nil, errors.Wrap(tagDefaultErr, "failed to read tag 'default' flag")
+			}
+
+			simpleTag.IsDefault = tagDefault == 1
+		case ElementSimpleTag:
+			nestedSimpleTag, nestedSimpleTagErr := m.readSimpleTagElement(element)
+			if nestedSimpleTagErr != nil {
+				return nil, errors.Wrap(nestedSimpleTagErr, "failed to read nested simple tag")
 			}
+
+			simpleTag.SimpleTags = append(simpleTag.SimpleTags, *nestedSimpleTag)
+		}
+
+		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return nil, errors.Wrap(seekErr, "failed to advance to next simple tag element")
 		}
 	}
 
-	return nil
+	return simpleTag, nil
 }
 
 func (m *MatroskaFile) readString(length int) (string, error) {
@@ -398,94 +1281,184 @@ func (m *MatroskaFile) readString(length int) (string, error) {
 		return "", errors.Wrap(readErr, "failed to read string from Matroska file")
 	}
 
+	//Strings may be padded with zero bytes
+	if end := bytes.IndexByte(buffer, 0); end >= 0 {
+		buffer = buffer[:end]
+	}
+
 	return string(buffer), nil
 }
 
-func (m *MatroskaFile) readSubtitleBlock(blockElement Element, clusterTimeCode int64, options MatroskaFileOptions) (*MatroskaSubtitle, error) {
+// readSubtitleBlock returns one subtitle per frame of the block, laced frames follow each other by the track's default duration
+func (m *MatroskaFile) readSubtitleBlock(blockElement Element, clusterTimeCode int64, options MatroskaFileOptions) (uint64, []MatroskaSubtitle, error) {
 	trackNumber, trackNumberErr := m.readVariableLengthUIntDefault()
 	if trackNumberErr != nil {
-		return nil, errors.Wrap(trackNumberErr, "failed to read subtitle track number")
+		return 0, nil, errors.Wrap(trackNumberErr, "failed to read subtitle track number")
 	}
 
-	if options.SubtitleTrack != trackNumber {
+	if !options.HasSubtitleTrack(trackNumber) {
 		_, seekErr := m.file.Seek(blockElement.EndPosition(), io.SeekStart)
 		if seekErr != nil {
-			return nil, errors.Wrap(seekErr, "failed to advance to next element")
+			return 0, nil, errors.Wrap(seekErr, "failed to advance to next element")
 		}
 
-		return nil, nil
+		return 0, nil, nil
 	}
 
 	timeCode, timeCodeErr := m.readInt16()
 	if timeCodeErr != nil {
-		return nil, errors.Wrap(timeCodeErr, "failed to read subtitle time code")
+		return 0, nil, errors.Wrap(timeCodeErr, "failed to read subtitle time code")
 	}
 
-	//lacing
 	buffer := make([]byte, 1)
 	bytesRead, readErr := m.file.Read(buffer)
 	if bytesRead == 0 || (readErr != nil && readErr != io.EOF) {
-		return nil, errors.Wrap(readErr, "failed to read flags for subtitle block")
+		return 0, nil, errors.Wrap(readErr, "failed to read flags for subtitle block")
 	}
 
 	flags := buffer[0]
 
-	var frames int
-	switch flags & 6 {
-	//00000000 = No lacing
-	//case 0:
-	//fmt.Println("No lacing")
-	//00000010 = Xiph lacing
-	case 2:
-		bytesRead, readErr = m.file.Read(buffer)
-		if bytesRead == 0 || (readErr != nil && readErr != io.EOF) {
-			return nil, errors.Wrap(readErr, "failed to read frames for subtitle block")
-		}
+	//save subtitle data, including the lace sizes
+	dataLength := blockElement.EndPosition() - m.file.Position()
+	data := make([]byte, dataLength)
+	bytesRead, readErr = m.file.Read(data)
+	if (bytesRead == 0 && dataLength > 0) || (readErr != nil && readErr != io.EOF) {
+		return 0, nil, errors.Wrap(readErr, "failed to read data for subtitle")
+	}
 
-		frames = int(buffer[0]) + 1
-	//00000100 = Fixed-size lacing
-	case 4:
-		bytesRead, readErr = m.file.Read(buffer)
-		if bytesRead == 0 || (readErr != nil && readErr != io.EOF) {
-			return nil, errors.Wrap(readErr, "failed to read frames for subtitle block")
+	frames, framesErr := splitLacedFrames(flags, data[:bytesRead])
+	if framesErr != nil {
+		return 0, nil, errors.Wrap(framesErr, "failed to split laced subtitle block")
+	}
+
+	subtitleStart := m.scaleTime64(float64(clusterTimeCode + int64(timeCode)))
+	defaultDuration := m.trackDefaultDuration(trackNumber)
+
+	subtitles := make([]MatroskaSubtitle, len(frames))
+	for i, frame := range frames {
+		subtitles[i] = *NewMatroskaSubtitle(frame, int64(math.Round(subtitleStart+float64(i)*defaultDuration)))
+		if len(frames) > 1 {
+			subtitles[i].Duration = int64(math.Round(defaultDuration))
 		}
+	}
 
-		frames = int(buffer[0]) + 1
+	return trackNumber, subtitles, nil
+}
 
-		for i := 0; i < frames; i++ {
-			//frames
-			bytesRead, readErr = m.file.Read(buffer)
-			if bytesRead == 0 || (readErr != nil && readErr != io.EOF) {
-				return nil, errors.Wrap(readErr, "failed to read frames for subtitle block")
-			}
+func (m *MatroskaFile) readTagElement(tagElement Element) (*MatroskaTag, error) {
+	element := EmptyElement
+	var elementErr error
+	tag := &MatroskaTag{Targets: MatroskaTagTargets{TargetTypeValue: TagTargetTypeValueAlbum}}
+
+	for m.file.Position() < tagElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read tag element")
 		}
-	//00000110 = EMBL lacing
-	case 6:
-		bytesRead, readErr = m.file.Read(buffer)
-		if bytesRead == 0 || (readErr != nil && readErr != io.EOF) {
-			return nil, errors.Wrap(readErr, "failed to read frames for subtitle block")
+
+		switch element.Id {
+		case ElementTargets:
+			targetsErr := m.readTagTargetsElement(element, &tag.Targets)
+			if targetsErr != nil {
+				return nil, errors.Wrap(targetsErr, "failed to read tag targets")
+			}
+		case ElementSimpleTag:
+			simpleTag, simpleTagErr := m.readSimpleTagElement(element)
+			if simpleTagErr != nil {
+				return nil, errors.Wrap(simpleTagErr, "failed to read simple tag")
+			}
+
+			tag.SimpleTags = append(tag.SimpleTags, *simpleTag)
 		}
 
-		frames = int(buffer[0]) + 1
+		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return nil, errors.Wrap(seekErr, "failed to advance to next tag element")
+		}
 	}
 
-	//save subtitle data
-	dataLength := blockElement.EndPosition() - m.file.Position()
-	data := make([]byte, dataLength)
-	bytesRead, readErr = m.file.Read(data)
-	if bytesRead == 0 || (readErr != nil && readErr != io.EOF) {
-		return nil, errors.Wrap(readErr, "failed to read data for subtitle")
+	return tag, nil
+}
+
+func (m *MatroskaFile) readTagsElement(tagsElement Element) error {
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < tagsElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return errors.Wrap(elementErr, "failed to read tags element")
+		}
+
+		if element.Id == ElementTag {
+			tag, tagErr := m.readTagElement(element)
+			if tagErr != nil {
+				return errors.Wrap(tagErr, "failed to read tag element")
+			}
+
+			m.tags = append(m.tags, *tag)
+		} else {
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
+			if seekErr != nil {
+				return errors.Wrap(seekErr, "failed to advance to next tag")
+			}
+		}
 	}
 
-	subtitleStart := int64(math.Round(m.scaleTime64(float64(clusterTimeCode + int64(timeCode)))))
+	return nil
+}
+
+func (m *MatroskaFile) readTagTargetsElement(targetsElement Element, targets *MatroskaTagTargets) error {
+	element := EmptyElement
+	var elementErr error
+
+	for m.file.Position() < targetsElement.EndPosition() && element != InvalidElement {
+		element, elementErr = m.readElement()
+		if elementErr != nil {
+			return errors.Wrap(elementErr, "failed to read targets element")
+		}
+
+		switch element.Id {
+		case ElementTargetType:
+			targetType, targetTypeErr := m.readString(int(element.DataSize))
+			if targetTypeErr != nil {
+				return errors.Wrap(targetTypeErr, "failed to read target type")
+			}
+
+			targets.TargetType = targetType
+		case ElementTargetTypeValue, ElementTagTrackUid, ElementTagEditionUid, ElementTagChapterUid, ElementTagAttachmentUid:
+			value, valueErr := m.readUInt(int(element.DataSize))
+			if valueErr != nil {
+				return errors.Wrap(valueErr, "failed to read tag target")
+			}
+
+			switch element.Id {
+			case ElementTargetTypeValue:
+				targets.TargetTypeValue = value
+			case ElementTagTrackUid:
+				targets.TrackUids = append(targets.TrackUids, value)
+			case ElementTagEditionUid:
+				targets.EditionUids = append(targets.EditionUids, value)
+			case ElementTagChapterUid:
+				targets.ChapterUids = append(targets.ChapterUids, value)
+			case ElementTagAttachmentUid:
+				targets.AttachmentUids = append(targets.AttachmentUids, value)
+			}
+		}
+
+		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to advance to next target")
+		}
+	}
 
-	return NewMatroskaSubtitle(data, subtitleStart), nil
+	return nil
 }
 
 func (m *MatroskaFile) readTrackEntryElement(trackEntryElement Element) (*MatroskaTrackInfo, error) {
 	element := EmptyElement
 	var elementErr error
-	track := &MatroskaTrackInfo{CodecId: "", IsDefault: true, Language: "eng", Name: ""}
+	track := &MatroskaTrackInfo{CodecId: "", IsDefault: true, IsEnabled: true, Language: "eng", Name: ""}
 
 	for m.file.Position() < trackEntryElement.EndPosition() && element != InvalidElement {
 		element, elementErr = m.readElement()
@@ -509,6 +1482,11 @@ func (m *MatroskaFile) readTrackEntryElement(trackEntryElement Element) (*Matros
 
 			track.IsVideo = true
 		case ElementAudio:
+			audioErr := m.readAudioElement(element, track)
+			if audioErr != nil {
+				return nil, errors.Wrap(audioErr, "failed to read track audio")
+			}
+
 			track.IsAudio = true
 		case ElementTrackNumber:
 			trackNumber, trackNumberErr := m.readUInt(int(element.DataSize))
@@ -531,6 +1509,28 @@ func (m *MatroskaFile) readTrackEntryElement(trackEntryElement Element) (*Matros
 			}
 
 			track.Language = language
+		case ElementLanguageBcp47:
+			languageBcp47, languageBcp47Err := m.readString(int(element.DataSize))
+			if languageBcp47Err != nil {
+				return nil, errors.Wrap(languageBcp47Err, "failed to read track BCP 47 language")
+			}
+
+			track.LanguageBcp47 = languageBcp47
+		case ElementCodecName:
+			codecName, codecNameErr := m.readString(int(element.DataSize))
+			if codecNameErr != nil {
+				return nil, errors.Wrap(codecNameErr, "failed to read track codec name")
+			}
+
+			track.CodecName = codecName
+		case ElementTrackUid:
+			trackUid, trackUidErr := m.readUInt(int(element.DataSize))
+			if trackUidErr != nil {
+				return nil, errors.Wrap(trackUidErr, "failed to read track uid")
+			}
+
+			track.TrackUid = trackUid
+			track.Uid = strconv.FormatUint(trackUid, 10)
 		case ElementCodecId:
 			codecId, codecIdErr := m.readString(int(element.DataSize))
 			if codecIdErr != nil {
@@ -554,25 +1554,24 @@ func (m *MatroskaFile) readTrackEntryElement(trackEntryElement Element) (*Matros
 				track.IsSubtitle = true
 			}
 		case ElementCodecPrivate:
-			codecPrivateRaw := make([]byte, element.DataSize)
-			bytesRead, readErr := m.file.Read(codecPrivateRaw)
-			if bytesRead == 0 || (readErr != nil && readErr != io.EOF) {
-				return nil, errors.Wrap(readErr, "failed to read track private codec")
+			codecPrivate, codecPrivateErr := m.readElementData(element)
+			if codecPrivateErr != nil {
+				return nil, errors.Wrap(codecPrivateErr, "failed to read track private codec")
 			}
+
+			track.CodecPrivate = codecPrivate
 		case ElementContentEncodings:
-			contentEncodingElement, contentEncodingElementErr := m.readElement()
-			if contentEncodingElementErr != nil || contentEncodingElement == InvalidElement || contentEncodingElement.Id != ElementContentEncoding {
-				return nil, errors.Wrap(contentEncodingElementErr, "failed to read track content encoding element")
+			contentEncodings, contentEncodingsErr := m.readContentEncodingsElement(element)
+			if contentEncodingsErr != nil {
+				return nil, errors.Wrap(contentEncodingsErr, "failed to read track content encodings")
 			}
 
-			contentCompressionAlgorithm, contentEncodingType, contentEncodingScope, contentEncodingErr := m.readContentEncodingElement(contentEncodingElement)
-			if contentEncodingErr != nil {
-				return nil, errors.Wrap(contentEncodingElementErr, "failed to read track content encoding")
+			track.ContentEncodings = contentEncodings
+			if len(contentEncodings) > 0 {
+				track.ContentCompressionAlgorithm = contentEncodings[0].CompressionAlgorithm
+				track.ContentEncodingScope = contentEncodings[0].Scope
+				track.ContentEncodingType = contentEncodings[0].Type
 			}
-
-			track.ContentCompressionAlgorithm = contentCompressionAlgorithm
-			track.ContentEncodingScope = contentEncodingScope
-			track.ContentEncodingType = contentEncodingType
 		case ElementFlagDefault:
 			flagDefault, flagDefaultErr := m.readUInt(int(element.DataSize))
 			if flagDefaultErr != nil {
@@ -583,10 +1582,30 @@ func (m *MatroskaFile) readTrackEntryElement(trackEntryElement Element) (*Matros
 		case ElementFlagForced:
 			flagForced, flagForcedErr := m.readUInt(int(element.DataSize))
 			if flagForcedErr != nil {
-				return nil, errors.Wrap(flagForcedErr, "failed to read track 'default' flag")
+				return nil, errors.Wrap(flagForcedErr, "failed to read track 'forced' flag")
 			}
 
-			track.IsDefault = flagForced == 1
+			track.IsForced = flagForced == 1
+		case ElementFlagEnabled, ElementFlagHearingImpaired, ElementFlagVisualImpaired, ElementFlagTextDescriptions, ElementFlagOriginal, ElementFlagCommentary:
+			flag, flagErr := m.readUInt(int(element.DataSize))
+			if flagErr != nil {
+				return nil, errors.Wrap(flagErr, "failed to read track flag")
+			}
+
+			switch element.Id {
+			case ElementFlagEnabled:
+				track.IsEnabled = flag == 1
+			case ElementFlagHearingImpaired:
+				track.IsHearingImpaired = flag == 1
+			case ElementFlagVisualImpaired:
+				track.IsVisualImpaired = flag == 1
+			case ElementFlagTextDescriptions:
+				track.IsTextDescriptions = flag == 1
+			case ElementFlagOriginal:
+				track.IsOriginal = flag == 1
+			case ElementFlagCommentary:
+				track.IsCommentary = flag == 1
+			}
 		}
 
 		_, seekErr := m.file.Seek(element.EndPosition(), io.SeekStart)
@@ -596,6 +1615,7 @@ func (m *MatroskaFile) readTrackEntryElement(trackEntryElement Element) (*Matros
 	}
 
 	if track.IsVideo {
+		//Without a default duration the frame rate stays unknown, which frame based formats replace with a default
 		if track.DefaultDuration > 0 {
 			m.FrameRate = 1.0 / (float64(track.DefaultDuration) / 1000000000.0)
 		}
@@ -717,21 +1737,21 @@ func (m *MatroskaFile) readVideoElement(videoElement Element) error {
 
 		switch element.Id {
 		case ElementPixelWidth:
-			pixelWidth, pixelWidthErr := m.readUInt(int(videoElement.DataSize))
+			pixelWidth, pixelWidthErr := m.readUInt(int(element.DataSize))
 			if pixelWidthErr != nil {
 				return errors.Wrap(pixelWidthErr, "failed to read pixel width")
 			}
 
 			m.PixelWidth = int(pixelWidth)
 		case ElementPixelHeight:
-			pixelHeight, pixelHeightErr := m.readUInt(int(videoElement.DataSize))
+			pixelHeight, pixelHeightErr := m.readUInt(int(element.DataSize))
 			if pixelHeightErr != nil {
 				return errors.Wrap(pixelHeightErr, "failed to read pixel height")
 			}
 
 			m.PixelHeight = int(pixelHeight)
 		default:
-			_, seekErr := m.file.Seek(videoElement.DataSize, io.SeekCurrent)
+			_, seekErr := m.file.Seek(element.DataSize, io.SeekCurrent)
 			if seekErr != nil {
 				return errors.Wrap(seekErr, "failed to seek while reading video element")
 			}
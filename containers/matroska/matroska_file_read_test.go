@@ -0,0 +1,394 @@
+package matroska
+
+import (
+	"bytes"
+	"encoding/binary"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+// cuedFile returns a file with a subtitle track in three clusters, the Cues index only the first block of the first
+// cluster, the second cluster without a relative position and not the third cluster
+func cuedFile() []byte {
+	info := mkvtest.Element(uint32(ElementInfo), mkvtest.UInt(uint32(ElementTimecodeScale), 1000000))
+	tracks := mkvtest.Element(uint32(ElementTracks), mkvtest.Element(uint32(ElementTrackEntry),
+		mkvtest.UInt(uint32(ElementTrackNumber), 1),
+		mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+		mkvtest.String(uint32(ElementCodecId), "S_TEXT/UTF8"),
+	))
+
+	firstBlock := mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 100, 0x80, []byte("one")))
+	timeCode := mkvtest.UInt(uint32(ElementTimecode), 0)
+	clusters := [][]byte{
+		mkvtest.Element(uint32(ElementCluster), timeCode, firstBlock, mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 500, 0x80, []byte("two")))),
+		mkvtest.Element(uint32(ElementCluster), mkvtest.UInt(uint32(ElementTimecode), 2000), mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 0, 0x80, []byte("three")))),
+		mkvtest.Element(uint32(ElementCluster), mkvtest.UInt(uint32(ElementTimecode), 4000), mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 0, 0x80, []byte("four")))),
+	}
+
+	firstCluster := uint64(len(info) + len(tracks))
+	secondCluster := firstCluster + uint64(len(clusters[0]))
+	cuePoint := func(time uint64, clusterPosition uint64, trackPosition ...[]byte) []byte {
+		return mkvtest.Element(uint32(ElementCuePoint), mkvtest.UInt(uint32(ElementCueTime), time), mkvtest.Element(uint32(ElementCueTrackPositions),
+			append([][]byte{mkvtest.UInt(uint32(ElementCueTrack), 1), mkvtest.UInt(uint32(ElementCueClusterPosition), clusterPosition)}, trackPosition...)...))
+	}
+	cues := mkvtest.Element(uint32(ElementCues),
+		cuePoint(100, firstCluster, mkvtest.UInt(uint32(ElementCueRelativePosition), uint64(len(timeCode)))),
+		cuePoint(2000, secondCluster),
+	)
+
+	return mkvtest.File(slices.Concat([][]byte{info, tracks}, clusters, [][]byte{cues})...)
+}
+
+func TestSubtitleReadsEveryBlockOfCuedClusters(t *testing.T) {
+	data := cuedFile()
+
+	for _, test := range []struct {
+		useCues  bool
+		expected []string
+	}{
+		{useCues: false, expected: []string{"one", "two", "three", "four"}},
+		{useCues: true, expected: []string{"one", "two", "three"}},
+	} {
+		matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+		if matroskaFileErr != nil {
+			t.Fatal(matroskaFileErr)
+		}
+		matroskaFile.UseCues = test.useCues
+
+		subtitles, subtitlesErr := matroskaFile.Subtitle(1, nil)
+		if subtitlesErr != nil {
+			t.Fatal(subtitlesErr)
+		}
+
+		texts := []string{}
+		for _, subtitle := range subtitles {
+			texts = append(texts, string(subtitle.Data))
+		}
+		if !slices.Equal(texts, test.expected) {
+			t.Errorf("UseCues %v: got %q, expected %q", test.useCues, texts, test.expected)
+		}
+	}
+}
+
+// unknownSize marks the 8 byte size following an element id of a given length as unknown, as live muxers write it
+func unknownSize(data []byte, sizePosition int) []byte {
+	copy(data[sizePosition:], []byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+
+	return data
+}
+
+// unknownSizeFile returns a file with a subtitle track in two clusters, and the position of the first cluster
+func unknownSizeFile(firstCluster []byte) ([]byte, int) {
+	info := mkvtest.Element(uint32(ElementInfo), mkvtest.UInt(uint32(ElementTimecodeScale), 1000000))
+	tracks := mkvtest.Element(uint32(ElementTracks), mkvtest.Element(uint32(ElementTrackEntry),
+		mkvtest.UInt(uint32(ElementTrackNumber), 1),
+		mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+		mkvtest.String(uint32(ElementCodecId), "S_TEXT/UTF8"),
+	))
+	secondCluster := mkvtest.Element(uint32(ElementCluster), mkvtest.UInt(uint32(ElementTimecode), 2000), mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 0, 0x80, []byte("three"))))
+
+	return mkvtest.File(info, tracks, firstCluster, secondCluster), mkvtest.SegmentDataOffset() + len(info) + len(tracks)
+}
+
+func TestSubtitleReadsElementsOfUnknownSize(t *testing.T) {
+	firstCluster := mkvtest.Element(uint32(ElementCluster),
+		mkvtest.UInt(uint32(ElementTimecode), 0),
+		mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 100, 0x80, []byte("one"))),
+		mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 500, 0x80, []byte("two"))),
+	)
+
+	for _, test := range []struct {
+		name     string
+		data     func() []byte
+		expected []MatroskaSubtitle
+	}{
+		{
+			name: "segment",
+			data: func() []byte {
+				data, _ := unknownSizeFile(firstCluster)
+
+				return unknownSize(data, mkvtest.SegmentDataOffset()-8)
+			},
+			expected: []MatroskaSubtitle{{Data: []byte("one"), Start: 100}, {Data: []byte("two"), Start: 500}, {Data: []byte("three"), Start: 2000}},
+		},
+		{
+			//The cluster ends where the next cluster starts
+			name: "cluster",
+			data: func() []byte {
+				data, clusterPosition := unknownSizeFile(slices.Clone(firstCluster))
+
+				return unknownSize(data, clusterPosition+4)
+			},
+			expected: []MatroskaSubtitle{{Data: []byte("one"), Start: 100}, {Data: []byte("two"), Start: 500}, {Data: []byte("three"), Start: 2000}},
+		},
+		{
+			name: "segment and cluster",
+			data: func() []byte {
+				data, clusterPosition := unknownSizeFile(slices.Clone(firstCluster))
+
+				return unknownSize(unknownSize(data, clusterPosition+4), mkvtest.SegmentDataOffset()-8)
+			},
+			expected: []MatroskaSubtitle{{Data: []byte("one"), Start: 100}, {Data: []byte("two"), Start: 500}, {Data: []byte("three"), Start: 2000}},
+		},
+		{
+			//A file cut in the middle of the second block of a cluster
+			name: "truncated cluster",
+			data: func() []byte {
+				data, clusterPosition := unknownSizeFile(firstCluster)
+
+				return data[:clusterPosition+len(firstCluster)-2]
+			},
+			expected: []MatroskaSubtitle{{Data: []byte("one"), Start: 100}},
+		},
+		{
+			name: "truncated cluster of unknown size",
+			data: func() []byte {
+				data, clusterPosition := unknownSizeFile(slices.Clone(firstCluster))
+
+				return unknownSize(data, clusterPosition+4)[:clusterPosition+len(firstCluster)-2]
+			},
+			expected: []MatroskaSubtitle{{Data: []byte("one"), Start: 100}},
+		},
+	} {
+		data := test.data()
+		matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+		if matroskaFileErr != nil {
+			t.Fatalf("%s: %v", test.name, matroskaFileErr)
+		}
+
+		subtitles, subtitlesErr := matroskaFile.Subtitle(1, nil)
+		if subtitlesErr != nil {
+			t.Fatalf("%s: %v", test.name, subtitlesErr)
+		}
+
+		if !slices.EqualFunc(subtitles, test.expected, func(a, b MatroskaSubtitle) bool {
+			return bytes.Equal(a.Data, b.Data) && a.Start == b.Start
+		}) {
+			t.Errorf("%s: got %v, expected %v", test.name, subtitles, test.expected)
+		}
+	}
+}
+
+func TestNewMatroskaFileFromReadSeeker(t *testing.T) {
+	matroskaFile, matroskaFileErr := NewMatroskaFileFromReadSeeker(bytes.NewReader(cuedFile()))
+	if matroskaFileErr != nil {
+		t.Fatal(matroskaFileErr)
+	}
+	defer matroskaFile.Close()
+
+	subtitles, subtitlesErr := matroskaFile.Subtitle(1, nil)
+	if subtitlesErr != nil {
+		t.Fatal(subtitlesErr)
+	}
+
+	texts := []string{}
+	for _, subtitle := range subtitles {
+		texts = append(texts, string(subtitle.Data))
+	}
+	if expected := []string{"one", "two", "three", "four"}; !slices.Equal(texts, expected) {
+		t.Errorf("got %q, expected %q", texts, expected)
+	}
+}
+
+// metadataFile returns a file with segment information, a subtitle track and tags for the segment and the track
+func metadataFile(date time.Duration) []byte {
+	info := mkvtest.Element(uint32(ElementInfo),
+		mkvtest.UInt(uint32(ElementTimecodeScale), 1000000),
+		mkvtest.String(uint32(ElementTitle), "Title"),
+		mkvtest.String(uint32(ElementMuxingApp), "libebml"),
+		mkvtest.String(uint32(ElementWritingApp), "gse"),
+		mkvtest.Element(uint32(ElementDateUtc), binary.BigEndian.AppendUint64(nil, uint64(date))),
+		mkvtest.Element(uint32(ElementSegmentUid), []byte{1, 2, 3, 4}),
+	)
+	tracks := mkvtest.Element(uint32(ElementTracks), mkvtest.Element(uint32(ElementTrackEntry),
+		mkvtest.UInt(uint32(ElementTrackNumber), 1),
+		mkvtest.UInt(uint32(ElementTrackUid), 7),
+		mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+		mkvtest.String(uint32(ElementCodecId), "S_TEXT/UTF8"),
+	))
+	simpleTag := func(name string, value string, language string, isDefault uint64, nested ...[]byte) []byte {
+		return mkvtest.Element(uint32(ElementSimpleTag), append([][]byte{
+			mkvtest.String(uint32(ElementTagName), name),
+			mkvtest.String(uint32(ElementTagString), value),
+			mkvtest.String(uint32(ElementTagLanguage), language),
+			mkvtest.UInt(uint32(ElementTagDefault), isDefault),
+		}, nested...)...)
+	}
+	tags := mkvtest.Element(uint32(ElementTags),
+		mkvtest.Element(uint32(ElementTag),
+			mkvtest.Element(uint32(ElementTargets), mkvtest.UInt(uint32(ElementTargetTypeValue), 50)),
+			simpleTag("PUBLISHER", "Studio", "eng", 1, simpleTag("URL", "https://example.com", "und", 1)),
+		),
+		mkvtest.Element(uint32(ElementTag),
+			mkvtest.Element(uint32(ElementTargets), mkvtest.UInt(uint32(ElementTagTrackUid), 7)),
+			simpleTag("BPS", "1200", "fre", 0),
+			simpleTag("BPS", "1000", "eng", 1),
+			simpleTag("NUMBER_OF_FRAMES", "42", "eng", 1),
+		),
+		mkvtest.Element(uint32(ElementTag),
+			mkvtest.Element(uint32(ElementTargets), mkvtest.UInt(uint32(ElementTagTrackUid), 8)),
+			simpleTag("BPS", "9999", "eng", 1),
+		),
+	)
+
+	return mkvtest.File(info, tracks, tags)
+}
+
+func TestReadsSegmentInfoAndTags(t *testing.T) {
+	for _, test := range []struct {
+		date     time.Duration
+		expected time.Time
+	}{
+		{date: 24*time.Hour + time.Second, expected: time.Date(2001, time.January, 2, 0, 0, 1, 0, time.UTC)},
+		{date: -time.Hour, expected: time.Date(2000, time.December, 31, 23, 0, 0, 0, time.UTC)},
+	} {
+		data := metadataFile(test.date)
+		matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+		if matroskaFileErr != nil {
+			t.Fatal(matroskaFileErr)
+		}
+
+		if _, tracksErr := matroskaFile.Tracks(false); tracksErr != nil {
+			t.Fatal(tracksErr)
+		}
+
+		if !matroskaFile.DateUtc.Equal(test.expected) {
+			t.Errorf("got date %v, expected %v", matroskaFile.DateUtc, test.expected)
+		}
+		if matroskaFile.Title != "Title" || matroskaFile.MuxingApp != "libebml" || matroskaFile.WritingApp != "gse" || !bytes.Equal(matroskaFile.SegmentUid, []byte{1, 2, 3, 4}) {
+			t.Errorf("got %v with segment uid %v", matroskaFile, matroskaFile.SegmentUid)
+		}
+	}
+
+	data := metadataFile(0)
+	matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if matroskaFileErr != nil {
+		t.Fatal(matroskaFileErr)
+	}
+
+	tags, tagsErr := matroskaFile.Tags()
+	if tagsErr != nil {
+		t.Fatal(tagsErr)
+	}
+	if len(tags) != 3 {
+		t.Fatalf("got %d tags, expected 3", len(tags))
+	}
+	if publisher := tags[0].SimpleTags[0]; tags[0].Targets.TargetTypeValue != 50 || publisher.Name != "PUBLISHER" || publisher.Value != "Studio" ||
+		len(publisher.SimpleTags) != 1 || publisher.SimpleTags[0].Value != "https://example.com" {
+		t.Errorf("got segment tag %v, expected the publisher with its nested url", tags[0])
+	}
+	if !slices.Equal(tags[1].Targets.TrackUids, []uint64{7}) || len(tags[1].SimpleTags) != 3 {
+		t.Errorf("got track tag %v", tags[1])
+	}
+
+	//Only the tags targeting the track are returned, the default BPS wins over its translation
+	trackTags, trackTagsErr := matroskaFile.TrackTags(7)
+	if trackTagsErr != nil {
+		t.Fatal(trackTagsErr)
+	}
+	values := map[string]string{}
+	for name, simpleTag := range trackTags {
+		values[name] = simpleTag.Value
+	}
+	if expected := map[string]string{"BPS": "1000", "NUMBER_OF_FRAMES": "42"}; !maps.Equal(values, expected) {
+		t.Errorf("got track tags %v, expected %v", values, expected)
+	}
+}
+
+func TestReadTrackEntry(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		entry        [][]byte
+		check        func(track MatroskaTrackInfo) bool
+		ietfLanguage string
+	}{
+		{
+			//Missing flags take the defaults of the specification
+			name: "defaults",
+			check: func(track MatroskaTrackInfo) bool {
+				return track.IsDefault && track.IsEnabled && !track.IsForced && !track.IsHearingImpaired && track.Language == "eng" && track.LanguageBcp47 == ""
+			},
+			ietfLanguage: "eng",
+		},
+		{
+			name: "flags",
+			entry: [][]byte{
+				mkvtest.UInt(uint32(ElementFlagDefault), 0),
+				mkvtest.UInt(uint32(ElementFlagEnabled), 0),
+				mkvtest.UInt(uint32(ElementFlagForced), 1),
+				mkvtest.UInt(uint32(ElementFlagHearingImpaired), 1),
+				mkvtest.String(uint32(ElementLanguage), "ger"),
+			},
+			check: func(track MatroskaTrackInfo) bool {
+				return !track.IsDefault && !track.IsEnabled && track.IsForced && track.IsHearingImpaired && track.Language == "ger"
+			},
+			ietfLanguage: "ger",
+		},
+		{
+			name: "bcp 47 language",
+			entry: [][]byte{
+				mkvtest.String(uint32(ElementLanguageBcp47), "de-CH"),
+				mkvtest.String(uint32(ElementLanguage), "ger"),
+			},
+			check: func(track MatroskaTrackInfo) bool {
+				return track.Language == "ger" && track.LanguageBcp47 == "de-CH"
+			},
+			ietfLanguage: "de-CH",
+		},
+	} {
+		entry := append([][]byte{
+			mkvtest.UInt(uint32(ElementTrackNumber), 1),
+			mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+			mkvtest.String(uint32(ElementCodecId), "S_TEXT/UTF8"),
+		}, test.entry...)
+		data := mkvtest.File(mkvtest.Element(uint32(ElementTracks), mkvtest.Element(uint32(ElementTrackEntry), entry...)))
+
+		matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+		if matroskaFileErr != nil {
+			t.Fatal(matroskaFileErr)
+		}
+
+		tracks, tracksErr := matroskaFile.Tracks(false)
+		if tracksErr != nil {
+			t.Fatal(tracksErr)
+		}
+		if len(tracks) != 1 {
+			t.Fatalf("%s: got %d tracks, expected 1", test.name, len(tracks))
+		}
+
+		if !test.check(tracks[0]) || tracks[0].IetfLanguage() != test.ietfLanguage {
+			t.Errorf("%s: got %v, expected language %s", test.name, &tracks[0], test.ietfLanguage)
+		}
+	}
+}
+
+func TestNewMatroskaFileChecksDocType(t *testing.T) {
+	for _, test := range []struct {
+		docType string
+		isValid bool
+	}{
+		{docType: DocTypeMatroska, isValid: true},
+		{docType: DocTypeWebM, isValid: true},
+		{docType: "dvb", isValid: false},
+	} {
+		data := mkvtest.FileOfDocType(test.docType, mkvtest.Element(uint32(ElementInfo), mkvtest.UInt(uint32(ElementTimecodeScale), 1000000)))
+		matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+
+		if !test.isValid {
+			if matroskaFileErr == nil {
+				t.Errorf("%s: expected an error for an unsupported document type", test.docType)
+			}
+			continue
+		}
+
+		if matroskaFileErr != nil {
+			t.Errorf("%s: %v", test.docType, matroskaFileErr)
+		} else if matroskaFile.DocType != test.docType {
+			t.Errorf("got document type %s, expected %s", matroskaFile.DocType, test.docType)
+		}
+	}
+}
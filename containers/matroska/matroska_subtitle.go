@@ -1,18 +1,27 @@
 package matroska
 
 import (
-	"bytes"
-	"compress/zlib"
-	"io"
-
 	"github.com/andybalholm/crlf"
 	"github.com/cockroachdb/errors"
 )
 
 type MatroskaSubtitle struct {
-	Data     []byte
-	Duration int64
-	Start    int64
+	//BlockAdditions hold data stored alongside the block, such as WebVTT cue settings
+	BlockAdditions []MatroskaBlockAddition
+	Data           []byte
+	Duration       int64
+	Start          int64
+}
+
+// BlockAddition returns the additional data with the given BlockAddID, or nil if there is none
+func (m *MatroskaSubtitle) BlockAddition(id uint64) []byte {
+	for _, blockAddition := range m.BlockAdditions {
+		if blockAddition.Id == id {
+			return blockAddition.Data
+		}
+	}
+
+	return nil
 }
 
 func (m *MatroskaSubtitle) End() int64 {
@@ -54,21 +63,22 @@ func (m *MatroskaSubtitle) Text(matroskaTrackInfo MatroskaTrackInfo) (string, er
 	return text, nil
 }
 
+// UncompressedData undoes the content encodings of the track that apply to frames, which are sorted highest order first
 func (m *MatroskaSubtitle) UncompressedData(matroskaTrackInfo MatroskaTrackInfo) ([]byte, error) {
-	if matroskaTrackInfo.ContentEncodingType != ContentEncodingTypeCompression || (matroskaTrackInfo.ContentEncodingScope&ContentEncodingScopeTracks) == 0 {
-		return m.Data, nil
-	}
+	data := m.Data
 
-	buffer := bytes.NewBuffer(m.Data)
-	zlibReader, zlibReaderErr := zlib.NewReader(buffer)
-	if zlibReaderErr != nil {
-		return nil, errors.Wrap(zlibReaderErr, "failed to create zlib reader")
-	}
+	for _, contentEncoding := range matroskaTrackInfo.ContentEncodings {
+		if (contentEncoding.Scope & ContentEncodingScopeTracks) == 0 {
+			continue
+		}
 
-	uncompressedData, uncompressedDataErr := io.ReadAll(zlibReader)
-	if uncompressedDataErr != nil {
-		return nil, errors.Wrap(uncompressedDataErr, "failed to read all data from zlib reader")
+		decodedData, decodedDataErr := contentEncoding.Decode(data)
+		if decodedDataErr != nil {
+			return nil, errors.Wrap(decodedDataErr, "failed to decode subtitle data")
+		}
+
+		data = decodedData
 	}
 
-	return uncompressedData, nil
+	return data, nil
 }
@@ -0,0 +1,19 @@
+package matroska
+
+import "fmt"
+
+// MatroskaSubtitleTrack describes a subtitle track to be written by a MatroskaWriter, the subtitles are stored
+// uncompressed and their start and duration are in milliseconds
+type MatroskaSubtitleTrack struct {
+	CodecId      string
+	CodecPrivate []byte
+	IsDefault    bool
+	IsForced     bool
+	Language     string
+	Name         string
+	Subtitles    []MatroskaSubtitle
+}
+
+func (m *MatroskaSubtitleTrack) String() string {
+	return fmt.Sprintf("Codec: %v , Name: %v , Language: %v , Default? %v , Forced? %v , Subtitles: %v", m.CodecId, m.Name, m.Language, m.IsDefault, m.IsForced, len(m.Subtitles))
+}
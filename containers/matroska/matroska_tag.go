@@ -0,0 +1,57 @@
+package matroska
+
+import (
+	"fmt"
+	"slices"
+)
+
+const TagTargetTypeValueAlbum = 50
+
+type MatroskaSimpleTag struct {
+	Binary        []byte
+	IsDefault     bool
+	Language      string
+	LanguageBcp47 string
+	Name          string
+	//SimpleTags are nested tags that refine this one, such as the URL of a publisher
+	SimpleTags []MatroskaSimpleTag
+	Value      string
+}
+
+type MatroskaTag struct {
+	SimpleTags []MatroskaSimpleTag
+	Targets    MatroskaTagTargets
+}
+
+type MatroskaTagTargets struct {
+	AttachmentUids  []uint64
+	ChapterUids     []uint64
+	EditionUids     []uint64
+	TargetType      string
+	TargetTypeValue uint64
+	TrackUids       []uint64
+}
+
+// AppliesToTrack reports whether the tag targets the track, tags without any target apply to the whole segment
+// and are not returned for tracks
+func (m *MatroskaTag) AppliesToTrack(trackUid uint64) bool {
+	return slices.Contains(m.Targets.TrackUids, trackUid)
+}
+
+// IsGlobal reports whether the tag applies to the whole segment rather than to specific tracks, editions,
+// chapters or attachments
+func (m *MatroskaTagTargets) IsGlobal() bool {
+	return len(m.AttachmentUids) == 0 && len(m.ChapterUids) == 0 && len(m.EditionUids) == 0 && len(m.TrackUids) == 0
+}
+
+func (m *MatroskaSimpleTag) String() string {
+	return fmt.Sprintf("Name: %v , Value: %v , Language: %v , Default? %v , SimpleTags: %v", m.Name, m.Value, m.Language, m.IsDefault, len(m.SimpleTags))
+}
+
+func (m *MatroskaTag) String() string {
+	return fmt.Sprintf("Targets: %v , SimpleTags: %v", m.Targets, m.SimpleTags)
+}
+
+func (m *MatroskaTagTargets) String() string {
+	return fmt.Sprintf("TargetTypeValue: %v , TargetType: %v , TrackUids: %v , EditionUids: %v , ChapterUids: %v , AttachmentUids: %v", m.TargetTypeValue, m.TargetType, m.TrackUids, m.EditionUids, m.ChapterUids, m.AttachmentUids)
+}
@@ -6,25 +6,51 @@ const (
 	ContentEncodingScopePrivateData = 2
 	ContentEncodingScopeTracks      = 1
 	ContentEncodingTypeCompression  = 0
+	ContentEncodingTypeEncryption   = 1
 )
 
 type MatroskaTrackInfo struct {
+	BitDepth                    int
+	Channels                    int
 	CodecId                     string
+	CodecName                   string
+	CodecPrivate                []byte
 	ContentCompressionAlgorithm int
 	ContentEncodingScope        uint
 	ContentEncodingType         int
+	ContentEncodings            []MatroskaContentEncoding
 	DefaultDuration             int
 	IsAudio                     bool
+	IsCommentary                bool
 	IsDefault                   bool
+	IsEnabled                   bool
 	IsForced                    bool
+	IsHearingImpaired           bool
+	IsOriginal                  bool
 	IsSubtitle                  bool
+	IsTextDescriptions          bool
 	IsVideo                     bool
+	IsVisualImpaired            bool
 	Language                    string
+	LanguageBcp47               string
 	Name                        string
+	OutputSamplingFrequency     float64
+	SamplingFrequency           float64
 	TrackNumber                 int
+	TrackUid                    uint64
 	Uid                         string
 }
 
+// IetfLanguage returns the BCP 47 language of the track, which takes precedence over the legacy ISO 639-2 language
+// when both are present
+func (m *MatroskaTrackInfo) IetfLanguage() string {
+	if m.LanguageBcp47 != "" {
+		return m.LanguageBcp47
+	}
+
+	return m.Language
+}
+
 func (m *MatroskaTrackInfo) String() string {
-	return fmt.Sprintf("Codec: %v , ContentCompressionAlgorithm: %v, ContentEncodingScope: %v , ContentEncodingType: %v , Duration: %v , Name: %v , Language: %v , Subtitle? %v , Video? %v", m.CodecId, m.ContentCompressionAlgorithm, m.ContentEncodingScope, m.ContentEncodingType, m.DefaultDuration, m.Name, m.Language, m.IsSubtitle, m.IsVideo)
+	return fmt.Sprintf("Codec: %v , ContentCompressionAlgorithm: %v, ContentEncodingScope: %v , ContentEncodingType: %v , Duration: %v , Name: %v , Language: %v , LanguageBcp47: %v , Default? %v , Forced? %v , HearingImpaired? %v , Commentary? %v , Subtitle? %v , Video? %v", m.CodecId, m.ContentCompressionAlgorithm, m.ContentEncodingScope, m.ContentEncodingType, m.DefaultDuration, m.Name, m.Language, m.LanguageBcp47, m.IsDefault, m.IsForced, m.IsHearingImpaired, m.IsCommentary, m.IsSubtitle, m.IsVideo)
 }
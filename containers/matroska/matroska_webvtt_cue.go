@@ -0,0 +1,126 @@
+package matroska
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ristryder/gse/common"
+	"github.com/ristryder/gse/formatting"
+)
+
+const (
+	CodecIdWebVtt             = "S_TEXT/WEBVTT"
+	CodecIdWebVttCaptions     = "D_WEBVTT/CAPTIONS"
+	CodecIdWebVttDescriptions = "D_WEBVTT/DESCRIPTIONS"
+	CodecIdWebVttMetadata     = "D_WEBVTT/METADATA"
+	CodecIdWebVttSubtitles    = "D_WEBVTT/SUBTITLES"
+)
+
+// MatroskaWebVttCue is a WebVTT cue read from a Matroska or WebM subtitle track, with its start and duration in
+// milliseconds
+type MatroskaWebVttCue struct {
+	//Comments are the NOTE blocks preceding the cue, without the NOTE keyword
+	Comments   []string
+	Duration   int64
+	Identifier string
+	Settings   string
+	Start      int64
+	Text       string
+}
+
+// IsWebVttCodec reports whether a codec id is one of the WebVTT codecs of Matroska (S_TEXT/WEBVTT) or
+// WebM (D_WEBVTT/*)
+func IsWebVttCodec(codecId string) bool {
+	return codecId == CodecIdWebVtt || strings.HasPrefix(codecId, "D_WEBVTT/")
+}
+
+// NewMatroskaWebVttCue converts a block of a WebVTT track. WebM stores the identifier and settings lines in front
+// of the cue text, while Matroska stores the settings, identifier and comments in the block additions
+func NewMatroskaWebVttCue(subtitle MatroskaSubtitle, track MatroskaTrackInfo) (*MatroskaWebVttCue, error) {
+	if !IsWebVttCodec(track.CodecId) {
+		return nil, errors.Newf("track %d is not a WebVTT track but %s", track.TrackNumber, track.CodecId)
+	}
+
+	text, textErr := subtitle.Text(track)
+	if textErr != nil {
+		return nil, errors.Wrap(textErr, "failed to read WebVTT cue text")
+	}
+
+	cue := &MatroskaWebVttCue{Duration: subtitle.Duration, Start: subtitle.Start}
+
+	if strings.HasPrefix(track.CodecId, "D_WEBVTT/") {
+		lines := strings.SplitN(text, "\n", 3)
+		if len(lines) < 3 {
+			return nil, errors.Newf("WebVTT block of track %d lacks the identifier and settings lines", track.TrackNumber)
+		}
+
+		cue.Identifier = lines[0]
+		cue.Settings = lines[1]
+		text = lines[2]
+	}
+
+	cue.Text = strings.TrimRight(text, "\n")
+
+	if additional := subtitle.BlockAddition(BlockAddIdDefault); additional != nil {
+		lines := strings.SplitN(strings.ReplaceAll(string(additional), "\r\n", "\n"), "\n", 3)
+
+		cue.Settings = lines[0]
+		if len(lines) > 1 {
+			cue.Identifier = lines[1]
+		}
+		if len(lines) > 2 {
+			for _, comment := range strings.Split(strings.Trim(lines[2], "\n"), "\n\n") {
+				if comment = strings.TrimSpace(strings.TrimPrefix(comment, "NOTE")); comment != "" {
+					cue.Comments = append(cue.Comments, comment)
+				}
+			}
+		}
+	}
+
+	return cue, nil
+}
+
+func (m *MatroskaWebVttCue) End() int64 {
+	return m.Start + m.Duration
+}
+
+// Paragraph converts the cue to a paragraph, with its WebVTT tags converted to paragraph formatting
+func (m *MatroskaWebVttCue) Paragraph() *common.Paragraph {
+	return common.NewParagraph(formatting.FromWebVtt(m.Text), float64(m.Start), float64(m.End()))
+}
+
+// String returns the cue as it is written in a WebVTT file, preceded by its comments
+func (m *MatroskaWebVttCue) String() string {
+	builder := strings.Builder{}
+
+	for _, comment := range m.Comments {
+		builder.WriteString("NOTE ")
+		builder.WriteString(comment)
+		builder.WriteString("\n\n")
+	}
+
+	if m.Identifier != "" {
+		builder.WriteString(m.Identifier)
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(webVttTimestamp(m.Start))
+	builder.WriteString(" --> ")
+	builder.WriteString(webVttTimestamp(m.End()))
+	if m.Settings != "" {
+		builder.WriteString(" ")
+		builder.WriteString(m.Settings)
+	}
+	builder.WriteString("\n")
+	builder.WriteString(m.Text)
+	builder.WriteString("\n")
+
+	return builder.String()
+}
+
+func webVttTimestamp(milliseconds int64) string {
+	milliseconds = max(0, milliseconds)
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", milliseconds/3600000, milliseconds/60000%60, milliseconds/1000%60, milliseconds%1000)
+}
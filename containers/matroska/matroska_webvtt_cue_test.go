@@ -0,0 +1,94 @@
+package matroska
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+// webVttFile returns a file of a document type with a WebVTT track of a codec and a single block group
+func webVttFile(docType string, codecId string, blockGroup ...[]byte) []byte {
+	tracks := mkvtest.Element(uint32(ElementTracks), mkvtest.Element(uint32(ElementTrackEntry),
+		mkvtest.UInt(uint32(ElementTrackNumber), 1),
+		mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+		mkvtest.String(uint32(ElementCodecId), codecId),
+	))
+	cluster := mkvtest.Element(uint32(ElementCluster),
+		mkvtest.UInt(uint32(ElementTimecode), 1000),
+		mkvtest.Element(uint32(ElementBlockGroup), blockGroup...),
+	)
+
+	return mkvtest.FileOfDocType(docType, mkvtest.Element(uint32(ElementInfo), mkvtest.UInt(uint32(ElementTimecodeScale), 1000000)), tracks, cluster)
+}
+
+func TestNewMatroskaWebVttCue(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		data     []byte
+		expected MatroskaWebVttCue
+	}{
+		{
+			//WebM stores the identifier and settings in front of the text
+			name: "webm",
+			data: webVttFile(DocTypeWebM, CodecIdWebVttSubtitles,
+				mkvtest.Element(uint32(ElementBlock), mkvtest.Block(1, 500, 0, []byte("intro\nline:0 align:start\nHello\nworld\n"))),
+				mkvtest.UInt(uint32(ElementBlockDuration), 2000),
+			),
+			expected: MatroskaWebVttCue{Duration: 2000, Identifier: "intro", Settings: "line:0 align:start", Start: 1500, Text: "Hello\nworld"},
+		},
+		{
+			//Matroska stores the settings, identifier and comments in a block addition
+			name: "matroska",
+			data: webVttFile(DocTypeMatroska, CodecIdWebVtt,
+				mkvtest.Element(uint32(ElementBlock), mkvtest.Block(1, 0, 0, []byte("Hello"))),
+				mkvtest.UInt(uint32(ElementBlockDuration), 1000),
+				mkvtest.Element(uint32(ElementBlockAdditions), mkvtest.Element(uint32(ElementBlockMore),
+					mkvtest.UInt(uint32(ElementBlockAddId), BlockAddIdDefault),
+					mkvtest.Element(uint32(ElementBlockAdditional), []byte("align:end\r\nintro\r\nNOTE first\n\nNOTE second\n")),
+				)),
+			),
+			expected: MatroskaWebVttCue{Comments: []string{"first", "second"}, Duration: 1000, Identifier: "intro", Settings: "align:end", Start: 1000, Text: "Hello"},
+		},
+	} {
+		matroskaFile, matroskaFileErr := NewMatroskaFileFromReaderAt(bytes.NewReader(test.data), int64(len(test.data)))
+		if matroskaFileErr != nil {
+			t.Fatalf("%s: %v", test.name, matroskaFileErr)
+		}
+
+		tracks, tracksErr := matroskaFile.Tracks(true)
+		if tracksErr != nil {
+			t.Fatalf("%s: %v", test.name, tracksErr)
+		}
+
+		subtitles, subtitlesErr := matroskaFile.Subtitle(1, nil)
+		if subtitlesErr != nil {
+			t.Fatalf("%s: %v", test.name, subtitlesErr)
+		}
+		if len(tracks) != 1 || len(subtitles) != 1 {
+			t.Fatalf("%s: got %d tracks and %d subtitles, expected one of each", test.name, len(tracks), len(subtitles))
+		}
+
+		cue, cueErr := NewMatroskaWebVttCue(subtitles[0], tracks[0])
+		if cueErr != nil {
+			t.Fatalf("%s: %v", test.name, cueErr)
+		}
+
+		if cue.Identifier != test.expected.Identifier || cue.Settings != test.expected.Settings || cue.Text != test.expected.Text ||
+			cue.Start != test.expected.Start || cue.Duration != test.expected.Duration || !slices.Equal(cue.Comments, test.expected.Comments) {
+			t.Errorf("%s: got %+v, expected %+v", test.name, *cue, test.expected)
+		}
+	}
+}
+
+func TestNewMatroskaWebVttCueRejectsOtherCodecs(t *testing.T) {
+	if _, cueErr := NewMatroskaWebVttCue(MatroskaSubtitle{Data: []byte("text")}, MatroskaTrackInfo{CodecId: "S_TEXT/UTF8"}); cueErr == nil {
+		t.Errorf("expected an error for a track which is not a WebVTT track")
+	}
+
+	//A WebM block without the identifier and settings lines is invalid
+	if _, cueErr := NewMatroskaWebVttCue(MatroskaSubtitle{Data: []byte("text")}, MatroskaTrackInfo{CodecId: CodecIdWebVttSubtitles}); cueErr == nil {
+		t.Errorf("expected an error for a WebM block without identifier and settings lines")
+	}
+}
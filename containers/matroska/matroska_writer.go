@@ -0,0 +1,837 @@
+package matroska
+
+import (
+	"cmp"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	maxRelativeBlockTimeCode = math.MaxInt16
+	segmentSizeLength        = 8
+)
+
+// MatroskaWriter writes a copy of a Matroska file with subtitle tracks added, removed or replaced.
+// Video and audio blocks are copied unchanged, while the Tracks, Cues and SeekHead elements are rewritten.
+type MatroskaWriter struct {
+	AddedSubtitleTracks    []MatroskaSubtitleTrack
+	RemovedTracks          []uint64
+	ReplacedSubtitleTracks map[uint64]MatroskaSubtitleTrack
+
+	source *MatroskaFile
+}
+
+type writerBlock struct {
+	subtitle    MatroskaSubtitle
+	timeCode    int64
+	trackNumber uint64
+}
+
+type writerCluster struct {
+	element  Element
+	timeCode int64
+}
+
+type writerCuePoint struct {
+	clusterPosition  uint64
+	duration         uint64
+	relativePosition uint64
+	time             uint64
+	trackNumber      uint64
+}
+
+type writerElement struct {
+	data []byte
+	id   ElementId
+}
+
+type writerState struct {
+	cuePoints        []writerCuePoint
+	cueTracks        map[uint64]bool
+	droppedTrackUids map[uint64]bool
+	droppedTracks    map[uint64]bool
+	duration         float64
+	durationPosition int64
+	output           io.WriteSeeker
+	pending          []writerBlock
+	position         int64
+	segmentPosition  int64
+	subtitleTracks   map[uint64]bool
+	timeCodeScale    float64
+	topLevelElements []Element
+}
+
+func NewMatroskaWriter(source *MatroskaFile) *MatroskaWriter {
+	return &MatroskaWriter{ReplacedSubtitleTracks: map[uint64]MatroskaSubtitleTrack{}, source: source}
+}
+
+func (m *MatroskaWriter) Write(output io.WriteSeeker, progressCallback func(int64, int64)) error {
+	tracks, tracksErr := m.source.Tracks(false)
+	if tracksErr != nil {
+		return errors.Wrap(tracksErr, "failed to read tracks of source Matroska file")
+	}
+
+	state := &writerState{
+		cueTracks:        map[uint64]bool{},
+		droppedTrackUids: map[uint64]bool{},
+		droppedTracks:    map[uint64]bool{},
+		output:           output,
+		subtitleTracks:   map[uint64]bool{},
+		timeCodeScale:    float64(m.source.TimeCodeScale),
+	}
+	if state.timeCodeScale <= 0 {
+		state.timeCodeScale = 1000000
+	}
+
+	for _, trackNumber := range m.RemovedTracks {
+		state.droppedTracks[trackNumber] = true
+	}
+
+	for trackNumber := range m.ReplacedSubtitleTracks {
+		if !slices.ContainsFunc(tracks, func(track MatroskaTrackInfo) bool { return uint64(track.TrackNumber) == trackNumber }) {
+			return errors.Newf("cannot replace track %d as it does not exist", trackNumber)
+		}
+
+		state.droppedTracks[trackNumber] = true
+	}
+
+	//Index keyframes of the video tracks, or of the audio tracks if there is no video
+	hasVideo := slices.ContainsFunc(tracks, func(track MatroskaTrackInfo) bool { return track.IsVideo })
+	for _, track := range tracks {
+		trackNumber := uint64(track.TrackNumber)
+		if state.droppedTracks[trackNumber] {
+			//Tags of removed and replaced tracks no longer describe a track of the output
+			state.droppedTrackUids[track.TrackUid] = true
+
+			continue
+		}
+
+		if track.IsSubtitle {
+			state.subtitleTracks[trackNumber] = true
+		} else if (hasVideo && track.IsVideo) || (!hasVideo && track.IsAudio) {
+			state.cueTracks[trackNumber] = true
+		}
+	}
+
+	topLevelElements, topLevelElementsErr := m.readTopLevelElements()
+	if topLevelElementsErr != nil {
+		return errors.Wrap(topLevelElementsErr, "failed to read top level elements")
+	}
+	state.topLevelElements = topLevelElements
+
+	tracksData, tracksDataErr := m.tracksData(state)
+	if tracksDataErr != nil {
+		return errors.Wrap(tracksDataErr, "failed to create tracks element")
+	}
+
+	slices.SortStableFunc(state.pending, func(a, b writerBlock) int {
+		return cmp.Compare(a.timeCode, b.timeCode)
+	})
+
+	ebmlHeaderErr := m.writeEbmlHeader(state)
+	if ebmlHeaderErr != nil {
+		return errors.Wrap(ebmlHeaderErr, "failed to write EBML header")
+	}
+
+	//The segment size and the seek head are written again once all positions are known
+	segmentSizePosition := state.position + int64(len(ebmlElementId(ElementSegment)))
+	segmentHeaderErr := state.write(append(ebmlElementId(ElementSegment), ebmlSizeWithLength(0, segmentSizeLength)...))
+	if segmentHeaderErr != nil {
+		return errors.Wrap(segmentHeaderErr, "failed to write segment header")
+	}
+	state.segmentPosition = state.position
+
+	metadataElements, metadataElementsErr := m.metadataElements(state)
+	if metadataElementsErr != nil {
+		return errors.Wrap(metadataElementsErr, "failed to read metadata elements")
+	}
+
+	seekIds := []ElementId{ElementInfo, ElementTracks}
+	for _, element := range metadataElements {
+		if element.id == ElementChapters || element.id == ElementAttachments || element.id == ElementTags {
+			if !slices.Contains(seekIds, element.id) {
+				seekIds = append(seekIds, element.id)
+			}
+		}
+	}
+	seekIds = append(seekIds, ElementCues)
+	seekPositions := map[ElementId]int64{}
+
+	seekHeadPosition := state.position
+	seekHeadErr := state.write(seekHeadData(seekIds, seekPositions))
+	if seekHeadErr != nil {
+		return errors.Wrap(seekHeadErr, "failed to write seek head")
+	}
+
+	//Metadata first, then the clusters
+	for _, element := range metadataElements {
+		if _, exists := seekPositions[element.id]; !exists {
+			seekPositions[element.id] = state.position - state.segmentPosition
+		}
+
+		writeErr := state.write(ebmlElement(element.id, element.data))
+		if writeErr != nil {
+			return errors.Wrap(writeErr, "failed to write top level element")
+		}
+
+		if element.id == ElementInfo {
+			//The duration is the last child of the segment information, it is written again after the clusters
+			state.durationPosition = state.position - 8
+
+			//The tracks follow the segment information
+			if _, exists := seekPositions[ElementTracks]; !exists {
+				tracksErr := state.writeTracks(tracksData, seekPositions)
+				if tracksErr != nil {
+					return tracksErr
+				}
+			}
+		}
+	}
+
+	if _, exists := seekPositions[ElementTracks]; !exists {
+		tracksErr := state.writeTracks(tracksData, seekPositions)
+		if tracksErr != nil {
+			return tracksErr
+		}
+	}
+
+	clustersErr := m.writeClusters(state, progressCallback)
+	if clustersErr != nil {
+		return errors.Wrap(clustersErr, "failed to write clusters")
+	}
+
+	seekPositions[ElementCues] = state.position - state.segmentPosition
+	cuesErr := state.write(ebmlElement(ElementCues, cuesData(state.cuePoints)))
+	if cuesErr != nil {
+		return errors.Wrap(cuesErr, "failed to write cues")
+	}
+
+	endPosition := state.position
+
+	_, seekErr := output.Seek(segmentSizePosition, io.SeekStart)
+	if seekErr != nil {
+		return errors.Wrap(seekErr, "failed to seek to segment size")
+	}
+
+	_, writeErr := output.Write(ebmlSizeWithLength(uint64(endPosition-state.segmentPosition), segmentSizeLength))
+	if writeErr != nil {
+		return errors.Wrap(writeErr, "failed to write segment size")
+	}
+
+	_, seekErr = output.Seek(seekHeadPosition, io.SeekStart)
+	if seekErr != nil {
+		return errors.Wrap(seekErr, "failed to seek to seek head")
+	}
+
+	_, writeErr = output.Write(seekHeadData(seekIds, seekPositions))
+	if writeErr != nil {
+		return errors.Wrap(writeErr, "failed to write seek head")
+	}
+
+	if state.durationPosition > 0 {
+		_, seekErr = output.Seek(state.durationPosition, io.SeekStart)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to seek to duration")
+		}
+
+		_, writeErr = output.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(state.duration)))
+		if writeErr != nil {
+			return errors.Wrap(writeErr, "failed to write duration")
+		}
+	}
+
+	_, seekErr = output.Seek(endPosition, io.SeekStart)
+	if seekErr != nil {
+		return errors.Wrap(seekErr, "failed to seek to end of output")
+	}
+
+	return nil
+}
+
+// blockGroupData returns a BlockGroup containing a single unlaced frame with its duration
+func blockGroupData(trackNumber uint64, relativeTimeCode int16, data []byte, duration uint64) []byte {
+	block := ebmlSize(trackNumber)
+	block = binary.BigEndian.AppendUint16(block, uint16(relativeTimeCode))
+	block = append(block, 0)
+	block = append(block, data...)
+
+	blockGroup := ebmlElement(ElementBlock, block)
+	blockGroup = append(blockGroup, ebmlUInt(ElementBlockDuration, duration)...)
+
+	return ebmlElement(ElementBlockGroup, blockGroup)
+}
+
+func cuesData(cuePoints []writerCuePoint) []byte {
+	slices.SortStableFunc(cuePoints, func(a, b writerCuePoint) int {
+		return cmp.Compare(a.time, b.time)
+	})
+
+	data := []byte{}
+	for _, cuePoint := range cuePoints {
+		trackPositions := ebmlUInt(ElementCueTrack, cuePoint.trackNumber)
+		trackPositions = append(trackPositions, ebmlUInt(ElementCueClusterPosition, cuePoint.clusterPosition)...)
+		if cuePoint.relativePosition > 0 {
+			trackPositions = append(trackPositions, ebmlUInt(ElementCueRelativePosition, cuePoint.relativePosition)...)
+		}
+		if cuePoint.duration > 0 {
+			trackPositions = append(trackPositions, ebmlUInt(ElementCueDuration, cuePoint.duration)...)
+		}
+
+		cuePointData := ebmlUInt(ElementCueTime, cuePoint.time)
+		cuePointData = append(cuePointData, ebmlElement(ElementCueTrackPositions, trackPositions)...)
+
+		data = append(data, ebmlElement(ElementCuePoint, cuePointData)...)
+	}
+
+	return data
+}
+
+// parseBlockHeader returns the track number, relative time code and flags of a (Simple)Block
+func parseBlockHeader(block []byte) (uint64, int16, byte, bool) {
+	trackNumber, length := readVariableLengthUIntFromBuffer(block)
+	if length == 0 || len(block) < length+3 {
+		return 0, 0, 0, false
+	}
+
+	return trackNumber, int16(binary.BigEndian.Uint16(block[length:])), block[length+2], true
+}
+
+// seekHeadData returns a SeekHead whose size does not depend on the positions, so it can be overwritten
+func seekHeadData(seekIds []ElementId, seekPositions map[ElementId]int64) []byte {
+	data := []byte{}
+	for _, id := range seekIds {
+		seek := ebmlElement(ElementSeekId, ebmlElementId(id))
+		seek = append(seek, ebmlElement(ElementSeekPosition, ebmlUIntData(uint64(seekPositions[id]), 8))...)
+
+		data = append(data, ebmlElement(ElementSeek, seek)...)
+	}
+
+	return ebmlElement(ElementSeekHead, data)
+}
+
+// subtitleTrackEntryData returns the TrackEntry data of a new subtitle track
+func subtitleTrackEntryData(trackNumber uint64, trackUid uint64, track MatroskaSubtitleTrack) []byte {
+	flagDefault, flagForced := uint64(0), uint64(0)
+	if track.IsDefault {
+		flagDefault = 1
+	}
+	if track.IsForced {
+		flagForced = 1
+	}
+
+	data := ebmlUInt(ElementTrackNumber, trackNumber)
+	data = append(data, ebmlUInt(ElementTrackUid, trackUid)...)
+	data = append(data, ebmlUInt(ElementTrackType, 17)...)
+	data = append(data, ebmlUInt(ElementFlagDefault, flagDefault)...)
+	data = append(data, ebmlUInt(ElementFlagForced, flagForced)...)
+	data = append(data, ebmlUInt(ElementFlagLacing, 0)...)
+	if track.Name != "" {
+		data = append(data, ebmlString(ElementName, track.Name)...)
+	}
+	if track.Language != "" {
+		data = append(data, ebmlString(ElementLanguage, track.Language)...)
+	}
+	data = append(data, ebmlString(ElementCodecId, track.CodecId)...)
+	if len(track.CodecPrivate) > 0 {
+		data = append(data, ebmlElement(ElementCodecPrivate, track.CodecPrivate)...)
+	}
+
+	return data
+}
+
+func (w *writerState) addPending(trackNumber uint64, track MatroskaSubtitleTrack) {
+	for _, subtitle := range track.Subtitles {
+		w.pending = append(w.pending, writerBlock{subtitle: subtitle, timeCode: max(0, w.toTimeCode(float64(subtitle.Start))), trackNumber: trackNumber})
+	}
+}
+
+// appendPending adds the pending subtitles starting before the limit to the cluster data, as long as their
+// time code relative to the cluster fits in a block
+func (w *writerState) appendPending(clusterData []byte, clusterTimeCode int64, limit int64) []byte {
+	for len(w.pending) > 0 && w.pending[0].timeCode < limit && w.pending[0].timeCode-clusterTimeCode <= maxRelativeBlockTimeCode {
+		block := w.pending[0]
+		w.pending = w.pending[1:]
+
+		duration := uint64(max(0, w.toTimeCode(float64(block.subtitle.Duration))))
+		w.duration = max(w.duration, float64(block.timeCode)+float64(duration))
+
+		w.cuePoints = append(w.cuePoints, writerCuePoint{
+			clusterPosition:  uint64(w.position - w.segmentPosition),
+			duration:         duration,
+			relativePosition: uint64(len(clusterData)),
+			time:             uint64(block.timeCode),
+			trackNumber:      block.trackNumber,
+		})
+
+		clusterData = append(clusterData, blockGroupData(block.trackNumber, int16(block.timeCode-clusterTimeCode), block.subtitle.Data, duration)...)
+	}
+
+	return clusterData
+}
+
+// infoData returns the segment information with its duration, in time code units, moved to the end so it can be
+// overwritten once the clusters are written
+func (w *writerState) infoData(data []byte) ([]byte, error) {
+	infoData := []byte{}
+	for offset := 0; offset < len(data); {
+		element, valid := readElementFromBuffer(data, offset)
+		if !valid {
+			return nil, errors.New("invalid info child element")
+		}
+		elementData := data[element.DataPosition:element.EndPosition()]
+		offset = int(element.EndPosition())
+
+		switch element.Id {
+		case ElementCrc32, ElementVoid:
+			//These would be wrong after rewriting the segment information
+		case ElementDuration:
+			switch len(elementData) {
+			case 4:
+				w.duration = max(w.duration, float64(math.Float32frombits(binary.BigEndian.Uint32(elementData))))
+			case 8:
+				w.duration = max(w.duration, math.Float64frombits(binary.BigEndian.Uint64(elementData)))
+			}
+		default:
+			infoData = append(infoData, ebmlElement(element.Id, elementData)...)
+		}
+	}
+
+	return append(infoData, ebmlFloat(ElementDuration, w.duration)...), nil
+}
+
+// tagsData returns the tags without those targeting only removed or replaced tracks
+func (w *writerState) tagsData(data []byte) ([]byte, error) {
+	tagsData := []byte{}
+	for offset := 0; offset < len(data); {
+		tagElement, valid := readElementFromBuffer(data, offset)
+		if !valid {
+			return nil, errors.New("invalid tag element")
+		}
+		tagData := data[tagElement.DataPosition:tagElement.EndPosition()]
+		offset = int(tagElement.EndPosition())
+
+		if tagElement.Id != ElementTag {
+			if tagElement.Id != ElementCrc32 && tagElement.Id != ElementVoid {
+				tagsData = append(tagsData, ebmlElement(tagElement.Id, tagData)...)
+			}
+
+			continue
+		}
+
+		newTagData := []byte{}
+		isDropped := false
+		for childOffset := 0; childOffset < len(tagData); {
+			childElement, valid := readElementFromBuffer(tagData, childOffset)
+			if !valid {
+				return nil, errors.New("invalid tag child element")
+			}
+			childData := tagData[childElement.DataPosition:childElement.EndPosition()]
+			childOffset = int(childElement.EndPosition())
+
+			if childElement.Id == ElementTargets {
+				targetsData, keptTrackUids, droppedTrackUids, targetsErr := w.targetsData(childData)
+				if targetsErr != nil {
+					return nil, targetsErr
+				}
+
+				//Without any track uid left the tag would apply to the whole segment
+				isDropped = droppedTrackUids > 0 && keptTrackUids == 0
+				childData = targetsData
+			}
+
+			newTagData = append(newTagData, ebmlElement(childElement.Id, childData)...)
+		}
+
+		if !isDropped {
+			tagsData = append(tagsData, ebmlElement(ElementTag, newTagData)...)
+		}
+	}
+
+	return tagsData, nil
+}
+
+// targetsData returns the tag targets without the uids of removed or replaced tracks, with the number of
+// track uids kept and dropped
+func (w *writerState) targetsData(data []byte) ([]byte, int, int, error) {
+	targetsData := []byte{}
+	keptTrackUids, droppedTrackUids := 0, 0
+	for offset := 0; offset < len(data); {
+		element, valid := readElementFromBuffer(data, offset)
+		if !valid {
+			return nil, 0, 0, errors.New("invalid targets child element")
+		}
+		elementData := data[element.DataPosition:element.EndPosition()]
+		offset = int(element.EndPosition())
+
+		if element.Id == ElementTagTrackUid {
+			if w.droppedTrackUids[binaryUInt(elementData)] {
+				droppedTrackUids++
+
+				continue
+			}
+
+			keptTrackUids++
+		}
+
+		targetsData = append(targetsData, ebmlElement(element.Id, elementData)...)
+	}
+
+	return targetsData, keptTrackUids, droppedTrackUids, nil
+}
+
+func (w *writerState) toTimeCode(milliseconds float64) int64 {
+	return int64(math.Round(milliseconds * 1000000.0 / w.timeCodeScale))
+}
+
+func (w *writerState) write(data []byte) error {
+	bytesWritten, writeErr := w.output.Write(data)
+	w.position += int64(bytesWritten)
+
+	if writeErr != nil {
+		return errors.Wrap(writeErr, "failed to write to output")
+	}
+
+	return nil
+}
+
+func (w *writerState) writeTracks(tracksData []byte, seekPositions map[ElementId]int64) error {
+	seekPositions[ElementTracks] = w.position - w.segmentPosition
+
+	writeErr := w.write(ebmlElement(ElementTracks, tracksData))
+	if writeErr != nil {
+		return errors.Wrap(writeErr, "failed to write tracks element")
+	}
+
+	return nil
+}
+
+// writeStandaloneClusters writes clusters containing only new subtitles, for those starting before the limit
+func (w *writerState) writeStandaloneClusters(limit int64) error {
+	for len(w.pending) > 0 && w.pending[0].timeCode < limit {
+		clusterTimeCode := w.pending[0].timeCode
+		clusterData := w.appendPending(ebmlUInt(ElementTimecode, uint64(clusterTimeCode)), clusterTimeCode, limit)
+
+		writeErr := w.write(ebmlElement(ElementCluster, clusterData))
+		if writeErr != nil {
+			return errors.Wrap(writeErr, "failed to write subtitle cluster")
+		}
+	}
+
+	return nil
+}
+
+// metadataElements returns the top level elements copied before the clusters, with the segment information
+// and the tags updated for the output
+func (m *MatroskaWriter) metadataElements(state *writerState) ([]writerElement, error) {
+	elements := []writerElement{}
+	for _, element := range state.topLevelElements {
+		switch element.Id {
+		case ElementCluster, ElementCrc32, ElementCues, ElementSeekHead, ElementTracks, ElementVoid:
+			continue
+		}
+
+		data, dataErr := m.source.readElementData(element)
+		if dataErr != nil {
+			return nil, errors.Wrap(dataErr, "failed to read top level element")
+		}
+
+		switch element.Id {
+		case ElementInfo:
+			data, dataErr = state.infoData(data)
+		case ElementTags:
+			data, dataErr = state.tagsData(data)
+		}
+		if dataErr != nil {
+			return nil, errors.Wrapf(dataErr, "failed to update element %x", uint32(element.Id))
+		}
+
+		//Tags left empty are left out
+		if element.Id == ElementTags && len(data) == 0 {
+			continue
+		}
+
+		elements = append(elements, writerElement{data: data, id: element.Id})
+	}
+
+	return elements, nil
+}
+
+func (m *MatroskaWriter) readTopLevelElements() ([]Element, error) {
+	_, seekErr := m.source.file.Seek(m.source.SegmentElement.DataPosition, io.SeekStart)
+	if seekErr != nil {
+		return nil, errors.Wrap(seekErr, "failed to advance to segment")
+	}
+
+	elements := []Element{}
+	for m.source.file.Position() < m.source.SegmentElement.EndPosition() {
+		element, elementErr := m.source.readElement()
+		if elementErr != nil {
+			return nil, errors.Wrap(elementErr, "failed to read top level element")
+		}
+
+		//An element cut off by the end of a truncated file is left out
+		if element == InvalidElement || element.EndPosition() > m.source.file.Size() {
+			break
+		}
+
+		elements = append(elements, element)
+
+		_, seekErr = m.source.file.Seek(element.EndPosition(), io.SeekStart)
+		if seekErr != nil {
+			return nil, errors.Wrap(seekErr, "failed to advance to next top level element")
+		}
+	}
+
+	return elements, nil
+}
+
+// tracksData returns the new Tracks element data and queues the blocks of the new subtitle tracks
+func (m *MatroskaWriter) tracksData(state *writerState) ([]byte, error) {
+	data := []byte{}
+	maxTrackNumber := uint64(0)
+
+	for _, element := range state.topLevelElements {
+		if element.Id != ElementTracks {
+			continue
+		}
+
+		tracksData, tracksDataErr := m.source.readElementData(element)
+		if tracksDataErr != nil {
+			return nil, errors.Wrap(tracksDataErr, "failed to read tracks element")
+		}
+
+		for offset := 0; offset < len(tracksData); {
+			trackEntryElement, valid := readElementFromBuffer(tracksData, offset)
+			if !valid {
+				return nil, errors.New("invalid track entry element")
+			}
+			offset = int(trackEntryElement.EndPosition())
+
+			if trackEntryElement.Id != ElementTrackEntry {
+				continue
+			}
+
+			trackEntryData := tracksData[trackEntryElement.DataPosition:trackEntryElement.EndPosition()]
+			trackNumber, trackUid := uint64(0), uint64(0)
+
+			for childOffset := 0; childOffset < len(trackEntryData); {
+				childElement, valid := readElementFromBuffer(trackEntryData, childOffset)
+				if !valid {
+					return nil, errors.New("invalid track entry child element")
+				}
+				childOffset = int(childElement.EndPosition())
+
+				value := binaryUInt(trackEntryData[childElement.DataPosition:childElement.EndPosition()])
+				switch childElement.Id {
+				case ElementTrackNumber:
+					trackNumber = value
+				case ElementTrackUid:
+					trackUid = value
+				}
+			}
+
+			maxTrackNumber = max(maxTrackNumber, trackNumber)
+
+			if replacement, exists := m.ReplacedSubtitleTracks[trackNumber]; exists {
+				if trackUid == 0 {
+					trackUid = newTrackUid()
+				}
+
+				data = append(data, ebmlElement(ElementTrackEntry, subtitleTrackEntryData(trackNumber, trackUid, replacement))...)
+				state.subtitleTracks[trackNumber] = true
+				state.addPending(trackNumber, replacement)
+			} else if !state.droppedTracks[trackNumber] {
+				data = append(data, ebmlElement(ElementTrackEntry, trackEntryData)...)
+			}
+		}
+	}
+
+	for _, track := range m.AddedSubtitleTracks {
+		maxTrackNumber++
+
+		data = append(data, ebmlElement(ElementTrackEntry, subtitleTrackEntryData(maxTrackNumber, newTrackUid(), track))...)
+		state.subtitleTracks[maxTrackNumber] = true
+		state.addPending(maxTrackNumber, track)
+	}
+
+	return data, nil
+}
+
+func (m *MatroskaWriter) writeClusters(state *writerState, progressCallback func(int64, int64)) error {
+	clusters := []writerCluster{}
+	for _, element := range state.topLevelElements {
+		if element.Id != ElementCluster {
+			continue
+		}
+
+		_, seekErr := m.source.file.Seek(element.DataPosition, io.SeekStart)
+		if seekErr != nil {
+			return errors.Wrap(seekErr, "failed to advance to cluster")
+		}
+
+		clusterTimeCode, _, clusterTimeCodeErr := m.source.readClusterTimeCode(element)
+		if clusterTimeCodeErr != nil {
+			return errors.Wrap(clusterTimeCodeErr, "failed to read cluster time code")
+		}
+
+		clusters = append(clusters, writerCluster{element: element, timeCode: clusterTimeCode})
+	}
+
+	//Subtitles before the first cluster get clusters of their own
+	if len(clusters) > 0 {
+		standaloneClustersErr := state.writeStandaloneClusters(clusters[0].timeCode)
+		if standaloneClustersErr != nil {
+			return standaloneClustersErr
+		}
+	}
+
+	for i, cluster := range clusters {
+		nextClusterTimeCode := int64(math.MaxInt64)
+		if i+1 < len(clusters) {
+			nextClusterTimeCode = clusters[i+1].timeCode
+		}
+
+		sourceData, sourceDataErr := m.source.readElementData(cluster.element)
+		if sourceDataErr != nil {
+			return errors.Wrap(sourceDataErr, "failed to read cluster")
+		}
+
+		clusterData := []byte{}
+		cueTracksInCluster := map[uint64]bool{}
+
+		for offset := 0; offset < len(sourceData); {
+			element, valid := readElementFromBuffer(sourceData, offset)
+			if !valid {
+				return errors.New("invalid cluster child element")
+			}
+			elementData := sourceData[element.DataPosition:element.EndPosition()]
+			offset = int(element.EndPosition())
+
+			block, blockDuration, isKeyFrame := elementData, uint64(0), false
+
+			switch element.Id {
+			case ElementClusterPosition, ElementCrc32, ElementPrevSize, ElementVoid:
+				//These would be wrong after rewriting the cluster
+				continue
+			case ElementSimpleBlock:
+				_, _, flags, valid := parseBlockHeader(block)
+				isKeyFrame = valid && (flags&0x80) == 0x80
+			case ElementBlockGroup:
+				block, isKeyFrame = nil, true
+
+				for childOffset := 0; childOffset < len(elementData); {
+					childElement, valid := readElementFromBuffer(elementData, childOffset)
+					if !valid {
+						return errors.New("invalid block group child element")
+					}
+					childOffset = int(childElement.EndPosition())
+
+					switch childElement.Id {
+					case ElementBlock:
+						block = elementData[childElement.DataPosition:childElement.EndPosition()]
+					case ElementBlockDuration:
+						blockDuration = binaryUInt(elementData[childElement.DataPosition:childElement.EndPosition()])
+					case ElementReferenceBlock:
+						isKeyFrame = false
+					}
+				}
+			default:
+				clusterData = append(clusterData, ebmlElement(element.Id, elementData)...)
+
+				continue
+			}
+
+			trackNumber, relativeTimeCode, _, valid := parseBlockHeader(block)
+			if !valid {
+				return errors.New("invalid block header")
+			}
+
+			if state.droppedTracks[trackNumber] {
+				continue
+			}
+
+			//New subtitles are interleaved in time order with the copied blocks
+			blockTimeCode := cluster.timeCode + int64(relativeTimeCode)
+			clusterData = state.appendPending(clusterData, cluster.timeCode, min(blockTimeCode+1, nextClusterTimeCode))
+
+			if state.subtitleTracks[trackNumber] || (state.cueTracks[trackNumber] && isKeyFrame && !cueTracksInCluster[trackNumber]) {
+				cueTracksInCluster[trackNumber] = true
+
+				state.cuePoints = append(state.cuePoints, writerCuePoint{
+					clusterPosition:  uint64(state.position - state.segmentPosition),
+					duration:         blockDuration,
+					relativePosition: uint64(len(clusterData)),
+					time:             uint64(max(0, blockTimeCode)),
+					trackNumber:      trackNumber,
+				})
+			}
+
+			clusterData = append(clusterData, ebmlElement(element.Id, elementData)...)
+			state.duration = max(state.duration, float64(blockTimeCode)+float64(blockDuration))
+		}
+
+		clusterData = state.appendPending(clusterData, cluster.timeCode, nextClusterTimeCode)
+
+		writeErr := state.write(ebmlElement(ElementCluster, clusterData))
+		if writeErr != nil {
+			return errors.Wrap(writeErr, "failed to write cluster")
+		}
+
+		//Subtitles too far after this cluster for a relative block time code
+		standaloneClustersErr := state.writeStandaloneClusters(nextClusterTimeCode)
+		if standaloneClustersErr != nil {
+			return standaloneClustersErr
+		}
+
+		if progressCallback != nil {
+			progressCallback(cluster.element.EndPosition(), m.source.file.Size())
+		}
+	}
+
+	return state.writeStandaloneClusters(math.MaxInt64)
+}
+
+func (m *MatroskaWriter) writeEbmlHeader(state *writerState) error {
+	_, seekErr := m.source.file.Seek(0, io.SeekStart)
+	if seekErr != nil {
+		return errors.Wrap(seekErr, "failed to advance to EBML header")
+	}
+
+	headerElement, headerElementErr := m.source.readElement()
+	if headerElementErr != nil {
+		return errors.Wrap(headerElementErr, "failed to read EBML header")
+	}
+
+	if headerElement.Id != ElementEbml {
+		return errors.New("source file does not start with an EBML header")
+	}
+
+	headerData, headerDataErr := m.source.readElementData(headerElement)
+	if headerDataErr != nil {
+		return errors.Wrap(headerDataErr, "failed to read EBML header data")
+	}
+
+	return state.write(ebmlElement(ElementEbml, headerData))
+}
+
+func binaryUInt(data []byte) uint64 {
+	result := uint64(0)
+	for _, value := range data {
+		result = result<<8 | uint64(value)
+	}
+
+	return result
+}
+
+func newTrackUid() uint64 {
+	for {
+		if trackUid := rand.Uint64(); trackUid != 0 {
+			return trackUid
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package matroska
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+// seekableBuffer is an in-memory io.WriteSeeker
+type seekableBuffer struct {
+	data     []byte
+	position int
+}
+
+func (s *seekableBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += int64(s.position)
+	case io.SeekEnd:
+		offset += int64(len(s.data))
+	}
+	s.position = int(offset)
+
+	return offset, nil
+}
+
+func (s *seekableBuffer) Write(data []byte) (int, error) {
+	if end := s.position + len(data); end > len(s.data) {
+		s.data = append(s.data, make([]byte, end-len(s.data))...)
+	}
+	s.position += copy(s.data[s.position:], data)
+
+	return len(data), nil
+}
+
+// writerSourceFile returns a file with a video track and a subtitle track in two clusters, and a tag for each
+// track and for the whole segment
+func writerSourceFile() []byte {
+	tag := func(name string, trackUids ...uint64) []byte {
+		targets := [][]byte{}
+		for _, trackUid := range trackUids {
+			targets = append(targets, mkvtest.UInt(uint32(ElementTagTrackUid), trackUid))
+		}
+
+		return mkvtest.Element(uint32(ElementTag),
+			mkvtest.Element(uint32(ElementTargets), targets...),
+			mkvtest.Element(uint32(ElementSimpleTag), mkvtest.String(uint32(ElementTagName), name)),
+		)
+	}
+
+	return mkvtest.File(
+		mkvtest.Element(uint32(ElementInfo),
+			mkvtest.UInt(uint32(ElementTimecodeScale), 1000000),
+			mkvtest.Float(uint32(ElementDuration), 1040),
+		),
+		mkvtest.Element(uint32(ElementTracks),
+			mkvtest.Element(uint32(ElementTrackEntry),
+				mkvtest.UInt(uint32(ElementTrackNumber), 1),
+				mkvtest.UInt(uint32(ElementTrackUid), 11),
+				mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeVideo),
+				mkvtest.String(uint32(ElementCodecId), "V_UNCOMPRESSED"),
+			),
+			mkvtest.Element(uint32(ElementTrackEntry),
+				mkvtest.UInt(uint32(ElementTrackNumber), 2),
+				mkvtest.UInt(uint32(ElementTrackUid), 22),
+				mkvtest.UInt(uint32(ElementTrackType), mkvtest.TrackTypeSubtitle),
+				mkvtest.String(uint32(ElementCodecId), "S_TEXT/UTF8"),
+			),
+		),
+		mkvtest.Element(uint32(ElementCluster),
+			mkvtest.UInt(uint32(ElementTimecode), 0),
+			mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 0, 0x80, []byte("frame 1"))),
+			mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(2, 200, 0x80, []byte("old"))),
+		),
+		mkvtest.Element(uint32(ElementCluster),
+			mkvtest.UInt(uint32(ElementTimecode), 1000),
+			mkvtest.Element(uint32(ElementSimpleBlock), mkvtest.Block(1, 0, 0x80, []byte("frame 2"))),
+		),
+		mkvtest.Element(uint32(ElementTags), tag("SEGMENT"), tag("VIDEO", 11), tag("SUBTITLE", 22), tag("BOTH", 11, 22)),
+	)
+}
+
+// tagNames returns the name of the first simple tag of each tag with the track uids it targets
+func tagNames(t *testing.T, file *MatroskaFile) map[string][]uint64 {
+	t.Helper()
+
+	tags, tagsErr := file.Tags()
+	if tagsErr != nil {
+		t.Fatal(tagsErr)
+	}
+
+	names := map[string][]uint64{}
+	for _, tag := range tags {
+		names[tag.SimpleTags[0].Name] = tag.Targets.TrackUids
+	}
+
+	return names
+}
+
+func TestMatroskaWriterRoundTrip(t *testing.T) {
+	data := writerSourceFile()
+	source, sourceErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if sourceErr != nil {
+		t.Fatal(sourceErr)
+	}
+
+	writer := NewMatroskaWriter(source)
+	writer.ReplacedSubtitleTracks[2] = MatroskaSubtitleTrack{
+		CodecId:   "S_TEXT/UTF8",
+		Language:  "eng",
+		Subtitles: []MatroskaSubtitle{{Data: []byte("new"), Duration: 1000, Start: 500}},
+	}
+	//The second subtitle is too far after the last cluster for a relative block time code
+	writer.AddedSubtitleTracks = []MatroskaSubtitleTrack{{
+		CodecId:   "S_TEXT/UTF8",
+		IsForced:  true,
+		Language:  "fre",
+		Name:      "Forced",
+		Subtitles: []MatroskaSubtitle{{Data: []byte("un"), Duration: 2000, Start: 1500}, {Data: []byte("deux"), Duration: 2000, Start: 40000}},
+	}}
+
+	output := &seekableBuffer{}
+	if writeErr := writer.Write(output, nil); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	written, writtenErr := NewMatroskaFileFromReaderAt(bytes.NewReader(output.data), int64(len(output.data)))
+	if writtenErr != nil {
+		t.Fatal(writtenErr)
+	}
+
+	tracks, tracksErr := written.Tracks(false)
+	if tracksErr != nil {
+		t.Fatal(tracksErr)
+	}
+	if len(tracks) != 3 {
+		t.Fatalf("got %d tracks, expected 3", len(tracks))
+	}
+	if !tracks[0].IsVideo || tracks[1].TrackUid != 22 || tracks[1].Language != "eng" {
+		t.Errorf("got tracks %v and %v, expected the video track and the replaced track keeping its uid", tracks[0], tracks[1])
+	}
+	if added := tracks[2]; added.TrackNumber != 3 || !added.IsSubtitle || !added.IsForced || added.Name != "Forced" || added.Language != "fre" {
+		t.Errorf("got added track %v", added)
+	}
+
+	for _, test := range []struct {
+		trackNumber uint64
+		expected    []MatroskaSubtitle
+	}{
+		{trackNumber: 1, expected: []MatroskaSubtitle{{Data: []byte("frame 1"), Start: 0}, {Data: []byte("frame 2"), Start: 1000}}},
+		{trackNumber: 2, expected: []MatroskaSubtitle{{Data: []byte("new"), Duration: 1000, Start: 500}}},
+		{trackNumber: 3, expected: []MatroskaSubtitle{{Data: []byte("un"), Duration: 2000, Start: 1500}, {Data: []byte("deux"), Duration: 2000, Start: 40000}}},
+	} {
+		subtitles, subtitlesErr := written.Subtitle(test.trackNumber, nil)
+		if subtitlesErr != nil {
+			t.Fatal(subtitlesErr)
+		}
+
+		if !slices.EqualFunc(subtitles, test.expected, func(a, b MatroskaSubtitle) bool {
+			return bytes.Equal(a.Data, b.Data) && a.Duration == b.Duration && a.Start == b.Start
+		}) {
+			t.Errorf("track %d: got %v, expected %v", test.trackNumber, subtitles, test.expected)
+		}
+	}
+
+	//The last added subtitle ends after the last cluster
+	if written.Duration != 42000 {
+		t.Errorf("got duration %v, expected 42000", written.Duration)
+	}
+
+	//The tag of the replaced track is dropped, the track is left out of the tag shared with the video track
+	if names := tagNames(t, written); !maps.EqualFunc(names, map[string][]uint64{"SEGMENT": nil, "VIDEO": {11}, "BOTH": {11}}, slices.Equal) {
+		t.Errorf("got tags %v", names)
+	}
+}
+
+func TestMatroskaWriterKeepsLongerDuration(t *testing.T) {
+	data := writerSourceFile()
+	source, sourceErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if sourceErr != nil {
+		t.Fatal(sourceErr)
+	}
+
+	writer := NewMatroskaWriter(source)
+	writer.AddedSubtitleTracks = []MatroskaSubtitleTrack{{
+		CodecId:   "S_TEXT/UTF8",
+		Subtitles: []MatroskaSubtitle{{Data: []byte("un"), Duration: 20, Start: 1000}},
+	}}
+
+	output := &seekableBuffer{}
+	if writeErr := writer.Write(output, nil); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	written, writtenErr := NewMatroskaFileFromReaderAt(bytes.NewReader(output.data), int64(len(output.data)))
+	if writtenErr != nil {
+		t.Fatal(writtenErr)
+	}
+
+	if _, tracksErr := written.Tracks(false); tracksErr != nil {
+		t.Fatal(tracksErr)
+	}
+	if written.Duration != 1040 {
+		t.Errorf("got duration %v, expected the source duration 1040", written.Duration)
+	}
+}
+
+func TestMatroskaWriterRemovesTracks(t *testing.T) {
+	data := writerSourceFile()
+	source, sourceErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if sourceErr != nil {
+		t.Fatal(sourceErr)
+	}
+
+	writer := NewMatroskaWriter(source)
+	writer.RemovedTracks = []uint64{2}
+
+	output := &seekableBuffer{}
+	if writeErr := writer.Write(output, nil); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	written, writtenErr := NewMatroskaFileFromReaderAt(bytes.NewReader(output.data), int64(len(output.data)))
+	if writtenErr != nil {
+		t.Fatal(writtenErr)
+	}
+
+	tracks, tracksErr := written.Tracks(false)
+	if tracksErr != nil {
+		t.Fatal(tracksErr)
+	}
+	if len(tracks) != 1 || !tracks[0].IsVideo {
+		t.Errorf("got tracks %v, expected only the video track", tracks)
+	}
+
+	subtitles, subtitlesErr := written.Subtitle(2, nil)
+	if subtitlesErr != nil {
+		t.Fatal(subtitlesErr)
+	}
+	if len(subtitles) != 0 {
+		t.Errorf("got %d blocks of the removed track, expected none", len(subtitles))
+	}
+
+	if names := tagNames(t, written); !maps.EqualFunc(names, map[string][]uint64{"SEGMENT": nil, "VIDEO": {11}, "BOTH": {11}}, slices.Equal) {
+		t.Errorf("got tags %v", names)
+	}
+}
+
+func TestMatroskaWriterRejectsUnknownReplacedTrack(t *testing.T) {
+	data := writerSourceFile()
+	source, sourceErr := NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if sourceErr != nil {
+		t.Fatal(sourceErr)
+	}
+
+	writer := NewMatroskaWriter(source)
+	writer.ReplacedSubtitleTracks[5] = MatroskaSubtitleTrack{CodecId: "S_TEXT/UTF8"}
+
+	if writeErr := writer.Write(&seekableBuffer{}, nil); writeErr == nil {
+		t.Errorf("expected an error for a replaced track which does not exist")
+	}
+}
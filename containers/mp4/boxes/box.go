@@ -13,7 +13,7 @@ type Box struct {
 	StartPosition uint64
 }
 
-func (b *Box) initSizeAndName(file *common.FileStream) (bool, error) {
+func (b *Box) initSizeAndName(file common.Stream) (bool, error) {
 	if b.StartPosition == 0 {
 		b.StartPosition = uint64(file.Position()) - 8
 	}
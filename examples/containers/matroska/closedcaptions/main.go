@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ristryder/gse/closedcaptions"
+	"github.com/ristryder/gse/containers/matroska"
+	"github.com/ristryder/gse/subtitles"
+)
+
+func main() {
+	matroskaFile, matroskaFileErr := matroska.NewMatroskaFile("/path/to/video/file.mkv")
+	if matroskaFileErr != nil {
+		fmt.Println("Error opening Matroska file: ", matroskaFileErr)
+
+		return
+	}
+
+	defer matroskaFile.Close()
+
+	//Track number 0 selects the first video track
+	captions, captionsErr := closedcaptions.ReadMatroska(matroskaFile, 0, progressCallback)
+	if captionsErr != nil {
+		fmt.Println("Error reading closed captions: ", captionsErr)
+
+		return
+	}
+
+	fmt.Println(captions)
+
+	timedText := subtitles.NewTimedText(subtitles.TimedTextProfileTtml)
+	fmt.Println(timedText.ToText(captions.Cc1, "CC1"))
+}
+
+func progressCallback(position int64, total int64) {
+	fmt.Printf("Position: %v / %v\n", position, total)
+}
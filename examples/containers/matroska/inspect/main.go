@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ristryder/gse/containers/matroska"
+)
+
+func main() {
+	ebmlReader, ebmlReaderErr := matroska.NewEbmlReader("/path/to/video/file.mkv")
+	if ebmlReaderErr != nil {
+		fmt.Println("Error opening EBML file: ", ebmlReaderErr)
+
+		return
+	}
+
+	defer ebmlReader.Close()
+
+	walkErr := ebmlReader.Walk(func(node matroska.EbmlNode) bool {
+		indentation := strings.Repeat("|  ", node.Depth)
+
+		if node.IsMaster() {
+			fmt.Printf("%s+ %s (size %d)\n", indentation, node.Name(), node.DataSize)
+
+			//Skip the blocks of the clusters to keep the output short
+			return node.Id != matroska.ElementCluster
+		}
+
+		if node.Schema.Type == matroska.ElementTypeBinary {
+			fmt.Printf("%s+ %s: %d bytes\n", indentation, node.Name(), node.DataSize)
+
+			return false
+		}
+
+		value, valueErr := ebmlReader.Value(node)
+		if valueErr != nil {
+			fmt.Printf("%s+ %s: error %v\n", indentation, node.Name(), valueErr)
+
+			return false
+		}
+
+		fmt.Printf("%s+ %s: %v\n", indentation, node.Name(), value)
+
+		return false
+	})
+	if walkErr != nil {
+		fmt.Println("Error walking EBML file: ", walkErr)
+	}
+}
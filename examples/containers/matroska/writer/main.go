@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ristryder/gse/containers/matroska"
+)
+
+func main() {
+	matroskaFile, matroskaFileErr := matroska.NewMatroskaFile("/path/to/video/file.mkv")
+	if matroskaFileErr != nil {
+		fmt.Println("Error opening Matroska file: ", matroskaFileErr)
+
+		return
+	}
+
+	defer matroskaFile.Close()
+
+	subtitleTracks, subtitleTracksErr := matroskaFile.Tracks(true)
+	if subtitleTracksErr != nil {
+		fmt.Println("Error retrieving tracks: ", subtitleTracksErr)
+
+		return
+	}
+
+	writer := matroska.NewMatroskaWriter(matroskaFile)
+
+	//Arbitrarily remove the first subtitle track
+	if len(subtitleTracks) > 0 {
+		writer.RemovedTracks = append(writer.RemovedTracks, uint64(subtitleTracks[0].TrackNumber))
+	}
+
+	//Start and duration are in milliseconds
+	writer.AddedSubtitleTracks = append(writer.AddedSubtitleTracks, matroska.MatroskaSubtitleTrack{
+		CodecId:  "S_TEXT/UTF8",
+		Language: "eng",
+		Name:     "Added",
+		Subtitles: []matroska.MatroskaSubtitle{
+			{Data: []byte("First subtitle"), Duration: 2000, Start: 1000},
+			{Data: []byte("Second subtitle"), Duration: 2500, Start: 4000},
+		},
+	})
+
+	output, outputErr := os.Create("/path/to/video/output.mkv")
+	if outputErr != nil {
+		fmt.Println("Error creating output file: ", outputErr)
+
+		return
+	}
+
+	defer output.Close()
+
+	writeErr := writer.Write(output, progressCallback)
+	if writeErr != nil {
+		fmt.Println("Error writing Matroska file: ", writeErr)
+	}
+}
+
+func progressCallback(position int64, total int64) {
+	fmt.Printf("Position: %v / %v\n", position, total)
+}
@@ -0,0 +1,161 @@
+package formatting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FromAss converts the text of an Advanced SubStation Alpha dialogue to paragraph text. The override tags \b, \i,
+// \u, \c, \1c, \fn and \r become formatting, \N and \n become line breaks and \h a no-break space, while positioning,
+// animation and other override tags are dropped
+func FromAss(text string) string {
+	spans := []Span{}
+	style := Style{}
+
+	for len(text) > 0 {
+		index := strings.IndexAny(text, "{\\")
+		if index < 0 {
+			spans = addSpan(spans, style, text)
+
+			break
+		}
+		spans = addSpan(spans, style, text[:index])
+		text = text[index:]
+
+		if text[0] == '\\' {
+			switch {
+			case strings.HasPrefix(text, `\N`), strings.HasPrefix(text, `\n`):
+				spans = addSpan(spans, style, "\n")
+				text = text[2:]
+			case strings.HasPrefix(text, `\h`):
+				spans = addSpan(spans, style, "\u00A0")
+				text = text[2:]
+			default:
+				spans = addSpan(spans, style, `\`)
+				text = text[1:]
+			}
+
+			continue
+		}
+
+		end := strings.IndexByte(text, '}')
+		if end < 0 {
+			spans = addSpan(spans, style, text)
+
+			break
+		}
+		//Blocks without override tags are comments
+		for _, override := range strings.Split(text[1:end], `\`)[1:] {
+			applyAssOverride(&style, strings.TrimSpace(override))
+		}
+		text = text[end+1:]
+	}
+
+	return Render(spans)
+}
+
+// ToAss converts paragraph text to the text of an Advanced SubStation Alpha dialogue, each change of formatting
+// becomes an override block and line breaks become \N
+func ToAss(text string) string {
+	builder := strings.Builder{}
+	previous := Style{}
+
+	for _, span := range Parse(text) {
+		overrides := ""
+		if span.Style.Bold != previous.Bold {
+			overrides += `\b` + assFlag(span.Style.Bold)
+		}
+		if span.Style.Italic != previous.Italic {
+			overrides += `\i` + assFlag(span.Style.Italic)
+		}
+		if span.Style.Underline != previous.Underline {
+			overrides += `\u` + assFlag(span.Style.Underline)
+		}
+		if !sameColor(span.Style.Color, previous.Color) {
+			//An empty colour resets the colour to the one of the dialogue style
+			overrides += `\c` + assColor(span.Style.Color)
+		}
+		if span.Style.FontName != previous.FontName {
+			overrides += `\fn` + span.Style.FontName
+		}
+		if overrides != "" {
+			builder.WriteString("{" + overrides + "}")
+		}
+
+		builder.WriteString(strings.ReplaceAll(strings.ReplaceAll(span.Text, "\r\n", "\n"), "\n", `\N`))
+		previous = span.Style
+	}
+
+	return builder.String()
+}
+
+func applyAssOverride(style *Style, override string) {
+	switch {
+	case strings.HasPrefix(override, "r"):
+		//A reset to a named dialogue style is a reset to the style of the paragraph
+		*style = Style{}
+	case strings.HasPrefix(override, "fn"):
+		style.FontName = strings.TrimSpace(override[2:])
+	case isAssColor(override, "1c"), isAssColor(override, "c"):
+		style.Color = assColorToHex(override[strings.IndexByte(override, 'c')+1:])
+	case isAssFlag(override, "b"):
+		//Bold is 1 or a font weight such as 700
+		weight, _ := strconv.Atoi(override[1:])
+		style.Bold = weight == 1 || weight >= 700
+	case isAssFlag(override, "i"):
+		style.Italic = override[1:] == "1"
+	case isAssFlag(override, "u"):
+		style.Underline = override[1:] == "1"
+	}
+}
+
+// assColor converts a colour to &HBBGGRR&, colours that are not known become empty
+func assColor(color string) string {
+	red, green, blue, known := Rgb(color)
+	if !known {
+		return ""
+	}
+
+	return fmt.Sprintf("&H%02X%02X%02X&", blue, green, red)
+}
+
+// assColorToHex converts a colour such as &HBBGGRR& to #rrggbb, an empty colour stays empty
+func assColorToHex(color string) string {
+	color = strings.Trim(strings.TrimSpace(color), "&")
+	color = strings.TrimPrefix(strings.TrimPrefix(color, "H"), "h")
+	if color == "" {
+		return ""
+	}
+
+	value, parseErr := strconv.ParseUint(color, 16, 32)
+	if parseErr != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", uint8(value), uint8(value>>8), uint8(value>>16))
+}
+
+func assFlag(isSet bool) string {
+	if isSet {
+		return "1"
+	}
+
+	return "0"
+}
+
+// isAssColor reports whether an override is a name followed by a colour or nothing, which tells \c from \clip
+func isAssColor(override string, name string) bool {
+	return override == name || strings.HasPrefix(override, name+"&")
+}
+
+// isAssFlag reports whether an override is a name followed only by digits, which tells \b from \be and \bord
+func isAssFlag(override string, name string) bool {
+	if !strings.HasPrefix(override, name) {
+		return false
+	}
+
+	_, parseErr := strconv.Atoi(override[len(name):])
+
+	return parseErr == nil
+}
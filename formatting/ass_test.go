@@ -0,0 +1,68 @@
+package formatting
+
+import (
+	"testing"
+)
+
+func TestFromAss(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: `Line one\NLine two\nthree`, expected: "Line one\nLine two\nthree"},
+		{text: `Non\hbreaking`, expected: "Non\u00A0breaking"},
+		{text: `C:\path`, expected: `C:\path`},
+		{text: `{\i1}Italic{\i0} plain`, expected: "<i>Italic</i> plain"},
+		{text: `{\b700}Bold{\b0} {\b1}also{\b400} not`, expected: "<b>Bold</b> <b>also</b> not"},
+		{text: `{\u1}Underline`, expected: "<u>Underline</u>"},
+		{text: `{\be1\bord2}Blur`, expected: "Blur"},
+		{text: `{\c&H0000FF&}Red{\c} default`, expected: `<font color="#ff0000">Red</font> default`},
+		{text: `{\1c&HFF0000&}Blue`, expected: `<font color="#0000ff">Blue</font>`},
+		{text: `{\clip(0,0,10,10)}Clipped`, expected: "Clipped"},
+		{text: `{\pos(10,20)\fad(100,100)}Positioned`, expected: "Positioned"},
+		{text: `{\i1\fnArial}Text{\r}Reset`, expected: `<font face="Arial"><i>Text</i></font>Reset`},
+		{text: `{\i1}Text{\rAlternate}Reset`, expected: "<i>Text</i>Reset"},
+		{text: `{\i1}One\N{\i0}Two`, expected: "<i>One\n</i>Two"},
+		{text: `{A comment}Text`, expected: "Text"},
+		{text: `Unclosed {brace`, expected: "Unclosed {brace"},
+	}
+
+	for _, test := range tests {
+		if text := FromAss(test.text); text != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.text, text, test.expected)
+		}
+	}
+}
+
+func TestToAss(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "Line one\nLine two\r\nthree", expected: `Line one\NLine two\Nthree`},
+		{text: "<i>Italic</i> plain", expected: `{\i1}Italic{\i0} plain`},
+		{text: "<b>Bold <i>both</i></b> none", expected: `{\b1}Bold {\i1}both{\b0\i0} none`},
+		{text: "<u>Underline</u>", expected: `{\u1}Underline`},
+		{text: `<font color="red">Red</font> default`, expected: `{\c&H0000FF&}Red{\c} default`},
+		{text: `<font color="#f00">Red</font><font color="red">same</font>`, expected: `{\c&H0000FF&}Redsame`},
+		{text: `<font face="Arial">Font</font> default`, expected: `{\fnArial}Font{\fn} default`},
+	}
+
+	for _, test := range tests {
+		if text := ToAss(test.text); text != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.text, text, test.expected)
+		}
+	}
+}
+
+func TestAssRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"<i>Italic</i> plain",
+		"<b>Bold</b>\n<u>underline</u>",
+		`<font color="#00ff00" face="Arial">Green</font> text`,
+	} {
+		if roundTrip := FromAss(ToAss(text)); roundTrip != text {
+			t.Errorf("%q: got %q after a round trip", text, roundTrip)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package formatting
+
+import (
+	"strconv"
+	"strings"
+)
+
+// namedColors are the HTML 4 colour names, with the aliases WebVTT and caption formats use
+var namedColors = map[string]string{
+	"aqua":    "00ffff",
+	"black":   "000000",
+	"blue":    "0000ff",
+	"cyan":    "00ffff",
+	"fuchsia": "ff00ff",
+	"gray":    "808080",
+	"green":   "008000",
+	"grey":    "808080",
+	"lime":    "00ff00",
+	"magenta": "ff00ff",
+	"maroon":  "800000",
+	"navy":    "000080",
+	"olive":   "808000",
+	"purple":  "800080",
+	"red":     "ff0000",
+	"silver":  "c0c0c0",
+	"teal":    "008080",
+	"white":   "ffffff",
+	"yellow":  "ffff00",
+}
+
+// Rgb returns the red, green and blue components of an HTML colour name or a hex value with or without #, such as
+// #ff0000, #f00 or #ff0000ff with alpha, and false for other colours
+func Rgb(color string) (uint8, uint8, uint8, bool) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if hex, exists := namedColors[color]; exists {
+		color = hex
+	}
+	color = strings.TrimPrefix(color, "#")
+
+	switch len(color) {
+	case 3:
+		color = string([]byte{color[0], color[0], color[1], color[1], color[2], color[2]})
+	case 8:
+		color = color[:6]
+	}
+	if len(color) != 6 {
+		return 0, 0, 0, false
+	}
+
+	value, parseErr := strconv.ParseUint(color, 16, 32)
+	if parseErr != nil {
+		return 0, 0, 0, false
+	}
+
+	return uint8(value >> 16), uint8(value >> 8), uint8(value), true
+}
+
+// sameColor reports whether two colours are the same, comparing their components when both are known
+func sameColor(a string, b string) bool {
+	aRed, aGreen, aBlue, aKnown := Rgb(a)
+	bRed, bGreen, bBlue, bKnown := Rgb(b)
+	if !aKnown || !bKnown {
+		return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+
+	return aRed == bRed && aGreen == bGreen && aBlue == bBlue
+}
@@ -0,0 +1,264 @@
+package formatting
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var fontAttributeRegex = regexp.MustCompile(`(?i)\b(color|face)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// Style is the formatting of a run of text. Colors are kept as written, an HTML colour name or a hex value such as
+// #ff0000, see Rgb
+type Style struct {
+	Bold      bool
+	Color     string
+	FontName  string
+	Italic    bool
+	Underline bool
+}
+
+func (s *Style) String() string {
+	return fmt.Sprintf("Bold: %v , Italic: %v , Underline: %v , Color: %v , FontName: %v", s.Bold, s.Italic, s.Underline, s.Color, s.FontName)
+}
+
+// Span is a run of text with one style, the text may contain line breaks
+type Span struct {
+	Style Style
+	Text  string
+}
+
+func (s *Span) String() string {
+	return fmt.Sprintf("Text: %q , Style: %v", s.Text, s.Style.String())
+}
+
+// layer is the opening and closing markup of one attribute of a style in a dialect with nested tags
+type layer struct {
+	closing string
+	opening string
+}
+
+// token is a tag including its angle brackets or the text between tags
+type token struct {
+	isTag bool
+	text  string
+}
+
+// Parse splits paragraph text into spans of the same style. Paragraph text is formatted with the tags <b>, <i>, <u>
+// and <font color="" face="">, as in SubRip, the dialect every format converts to and from. Unbalanced closing tags
+// are dropped and other tags are kept as text
+func Parse(text string) []Span {
+	spans := []Span{}
+	openTags := []openTag{}
+
+	for _, t := range tokenize(text) {
+		if !t.isTag {
+			spans = addSpan(spans, styleOf(openTags), t.text)
+
+			continue
+		}
+
+		name, attributes := tagName(t.text)
+		switch name {
+		case "b":
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) { s.Bold = true }})
+		case "font":
+			color, face := fontAttributes(attributes)
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) {
+				if color != "" {
+					s.Color = color
+				}
+				if face != "" {
+					s.FontName = face
+				}
+			}})
+		case "i":
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) { s.Italic = true }})
+		case "u":
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) { s.Underline = true }})
+		case "/b", "/font", "/i", "/u":
+			openTags = closeTag(openTags, name[1:])
+		default:
+			spans = addSpan(spans, styleOf(openTags), t.text)
+		}
+	}
+
+	return spans
+}
+
+// Render writes spans as paragraph text, see Parse
+func Render(spans []Span) string {
+	return renderLayers(spans, func(style Style) []layer {
+		layers := []layer{}
+		if style.Color != "" || style.FontName != "" {
+			opening := "<font"
+			if style.Color != "" {
+				opening += ` color="` + style.Color + `"`
+			}
+			if style.FontName != "" {
+				opening += ` face="` + style.FontName + `"`
+			}
+			layers = append(layers, layer{closing: "</font>", opening: opening + ">"})
+		}
+		if style.Bold {
+			layers = append(layers, layer{closing: "</b>", opening: "<b>"})
+		}
+		if style.Italic {
+			layers = append(layers, layer{closing: "</i>", opening: "<i>"})
+		}
+		if style.Underline {
+			layers = append(layers, layer{closing: "</u>", opening: "<u>"})
+		}
+
+		return layers
+	}, func(text string) string {
+		return text
+	})
+}
+
+// Strip returns paragraph text without its formatting
+func Strip(text string) string {
+	builder := strings.Builder{}
+	for _, span := range Parse(text) {
+		builder.WriteString(span.Text)
+	}
+
+	return builder.String()
+}
+
+type openTag struct {
+	apply func(style *Style)
+	name  string
+}
+
+func addSpan(spans []Span, style Style, text string) []Span {
+	if text == "" {
+		return spans
+	}
+	if len(spans) > 0 && spans[len(spans)-1].Style == style {
+		spans[len(spans)-1].Text += text
+
+		return spans
+	}
+
+	return append(spans, Span{Style: style, Text: text})
+}
+
+// closeTag removes the last open tag with a name, tags opened after it stay open
+func closeTag(openTags []openTag, name string) []openTag {
+	for i := len(openTags) - 1; i >= 0; i-- {
+		if openTags[i].name == name {
+			return append(openTags[:i:i], openTags[i+1:]...)
+		}
+	}
+
+	return openTags
+}
+
+func fontAttributes(attributes string) (string, string) {
+	color, face := "", ""
+	for _, match := range fontAttributeRegex.FindAllStringSubmatch(attributes, -1) {
+		value := match[2] + match[3] + match[4]
+		if strings.EqualFold(match[1], "color") {
+			color = value
+		} else {
+			face = value
+		}
+	}
+
+	return color, face
+}
+
+// renderLayers writes spans in a dialect of nested tags, keeping open the layers the next span shares so that a
+// bold span inside an italic span only opens and closes the bold tag
+func renderLayers(spans []Span, layersOf func(style Style) []layer, escape func(text string) string) string {
+	builder := strings.Builder{}
+	open := []layer{}
+
+	for _, span := range spans {
+		layers := layersOf(span.Style)
+
+		shared := 0
+		for shared < len(open) && slices.Contains(layers, open[shared]) {
+			shared++
+		}
+		for i := len(open) - 1; i >= shared; i-- {
+			builder.WriteString(open[i].closing)
+		}
+
+		open = open[:shared]
+		for _, l := range layers {
+			if !slices.Contains(open, l) {
+				builder.WriteString(l.opening)
+				open = append(open, l)
+			}
+		}
+
+		builder.WriteString(escape(span.Text))
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		builder.WriteString(open[i].closing)
+	}
+
+	return builder.String()
+}
+
+func styleOf(openTags []openTag) Style {
+	style := Style{}
+	for _, tag := range openTags {
+		tag.apply(&style)
+	}
+
+	return style
+}
+
+// tagName returns the lowercase name of a tag, with a leading slash for closing tags, and the rest of the tag
+func tagName(tag string) (string, string) {
+	inner := strings.TrimSpace(tag[1 : len(tag)-1])
+	end := strings.IndexAny(inner, " \t\n.")
+	if end < 0 {
+		end = len(inner)
+	}
+
+	return strings.ToLower(inner[:end]), inner[end:]
+}
+
+// tokenize splits text into tags and the text between them, a < without a closing > is text
+func tokenize(text string) []token {
+	tokens := []token{}
+
+	for len(text) > 0 {
+		start := strings.IndexByte(text, '<')
+		if start < 0 {
+			tokens = append(tokens, token{text: text})
+
+			break
+		}
+
+		end := strings.IndexByte(text[start:], '>')
+		if end < 0 {
+			tokens = append(tokens, token{text: text})
+
+			break
+		}
+		end += start + 1
+
+		//A < followed by another one before the > is text, as in "<3 <i>"
+		if next := strings.IndexByte(text[start+1:end], '<'); next >= 0 {
+			tokens = append(tokens, token{text: text[:start+1+next]})
+			text = text[start+1+next:]
+
+			continue
+		}
+
+		if start > 0 {
+			tokens = append(tokens, token{text: text[:start]})
+		}
+		tokens = append(tokens, token{isTag: true, text: text[start:end]})
+		text = text[end:]
+	}
+
+	return tokens
+}
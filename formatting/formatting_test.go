@@ -0,0 +1,74 @@
+package formatting
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	spans := Parse(`<i>Italic <b>both</i> bold</b> <font color='red' face="Arial">font</font>`)
+
+	expected := []Span{
+		{Style: Style{Italic: true}, Text: "Italic "},
+		{Style: Style{Bold: true, Italic: true}, Text: "both"},
+		{Style: Style{Bold: true}, Text: " bold"},
+		{Style: Style{}, Text: " "},
+		{Style: Style{Color: "red", FontName: "Arial"}, Text: "font"},
+	}
+	if len(spans) != len(expected) {
+		t.Fatalf("got %v, expected %v", spans, expected)
+	}
+	for i := range expected {
+		if spans[i] != expected[i] {
+			t.Errorf("span %d: got %s, expected %s", i, spans[i].String(), expected[i].String())
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "<i>Italic <b>both</i> bold</b>", expected: "<i>Italic <b>both</b></i><b> bold</b>"},
+		{text: "</i>Unbalanced", expected: "Unbalanced"},
+		{text: "<span>Unknown</span>", expected: "<span>Unknown</span>"},
+		{text: "<3 <i>love</i>", expected: "<3 <i>love</i>"},
+		{text: "<I>Upper</I>", expected: "<i>Upper</i>"},
+		{text: `<font color=red>Red <font face=Arial>font</font></font>`, expected: `<font color="red">Red </font><font color="red" face="Arial">font</font>`},
+	}
+
+	for _, test := range tests {
+		if text := Render(Parse(test.text)); text != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.text, text, test.expected)
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	if text := Strip(`<i>Hello</i> <3 <font color="red">there</font>`); text != "Hello <3 there" {
+		t.Errorf("got %q", text)
+	}
+}
+
+func TestRgb(t *testing.T) {
+	tests := []struct {
+		color    string
+		expected [3]uint8
+		known    bool
+	}{
+		{color: "#ff0000", expected: [3]uint8{255, 0, 0}, known: true},
+		{color: "#0F0", expected: [3]uint8{0, 255, 0}, known: true},
+		{color: "0000ff80", expected: [3]uint8{0, 0, 255}, known: true},
+		{color: " Lime ", expected: [3]uint8{0, 255, 0}, known: true},
+		{color: "grey", expected: [3]uint8{128, 128, 128}, known: true},
+		{color: "#12345", known: false},
+		{color: "notacolor", known: false},
+	}
+
+	for _, test := range tests {
+		red, green, blue, known := Rgb(test.color)
+		if known != test.known || (known && [3]uint8{red, green, blue} != test.expected) {
+			t.Errorf("%q: got %d %d %d %v, expected %v %v", test.color, red, green, blue, known, test.expected, test.known)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package formatting
+
+import (
+	"html"
+	"strings"
+)
+
+// webVttColors are the colour classes of WebVTT
+var webVttColors = []string{"black", "blue", "cyan", "lime", "magenta", "red", "white", "yellow"}
+
+// FromWebVtt converts the text of a WebVTT cue to paragraph text. The tags <b>, <i>, <u> and the colour classes of
+// <c> become formatting, character references are decoded, and voice, language, ruby and timestamp tags are dropped
+func FromWebVtt(text string) string {
+	spans := []Span{}
+	openTags := []openTag{}
+
+	for _, t := range tokenize(text) {
+		if !t.isTag {
+			spans = addSpan(spans, styleOf(openTags), html.UnescapeString(t.text))
+
+			continue
+		}
+
+		name, attributes := tagName(t.text)
+		switch name {
+		case "b":
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) { s.Bold = true }})
+		case "c":
+			color := webVttClassColor(attributes)
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) {
+				if color != "" {
+					s.Color = color
+				}
+			}})
+		case "i":
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) { s.Italic = true }})
+		case "u":
+			openTags = append(openTags, openTag{name: name, apply: func(s *Style) { s.Underline = true }})
+		case "/b", "/c", "/i", "/u":
+			openTags = closeTag(openTags, name[1:])
+		}
+	}
+
+	return Render(spans)
+}
+
+// ToWebVtt converts paragraph text to the text of a WebVTT cue. Colours become the colour class with the same colour
+// and are dropped when there is none, as are font names, which need a style sheet
+func ToWebVtt(text string) string {
+	return renderLayers(Parse(text), func(style Style) []layer {
+		layers := []layer{}
+		if class := webVttColorClass(style.Color); class != "" {
+			layers = append(layers, layer{closing: "</c>", opening: "<c." + class + ">"})
+		}
+		if style.Bold {
+			layers = append(layers, layer{closing: "</b>", opening: "<b>"})
+		}
+		if style.Italic {
+			layers = append(layers, layer{closing: "</i>", opening: "<i>"})
+		}
+		if style.Underline {
+			layers = append(layers, layer{closing: "</u>", opening: "<u>"})
+		}
+
+		return layers
+	}, func(text string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(strings.ReplaceAll(text, "\r\n", "\n"))
+	})
+}
+
+// webVttClassColor returns the last colour class of a <c> tag, such as yellow for <c.loud.yellow>
+func webVttClassColor(attributes string) string {
+	classes, _, _ := strings.Cut(strings.TrimLeft(attributes, "."), " ")
+	color := ""
+	for _, class := range strings.Split(classes, ".") {
+		for _, name := range webVttColors {
+			if strings.EqualFold(class, name) {
+				color = name
+			}
+		}
+	}
+
+	return color
+}
+
+func webVttColorClass(color string) string {
+	if color == "" {
+		return ""
+	}
+
+	for _, name := range webVttColors {
+		if sameColor(color, name) {
+			return name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,49 @@
+package formatting
+
+import (
+	"testing"
+)
+
+func TestFromWebVtt(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "<i>Italic</i> <b>bold</b> <u>underline</u>", expected: "<i>Italic</i> <b>bold</b> <u>underline</u>"},
+		{text: "<c.yellow>Yellow</c>", expected: `<font color="yellow">Yellow</font>`},
+		{text: "<c.loud.RED>Red</c>", expected: `<font color="red">Red</font>`},
+		{text: "<c.loud>No colour</c>", expected: "No colour"},
+		{text: "<v Bob>Hello</v> <lang en>there</lang>", expected: "Hello there"},
+		{text: "<00:00:01.000>Karaoke", expected: "Karaoke"},
+		{text: "Fish &amp; chips &lt;3 &nbsp;", expected: "Fish & chips <3 \u00A0"},
+		{text: "<i>Line one\nline two</i>", expected: "<i>Line one\nline two</i>"},
+	}
+
+	for _, test := range tests {
+		if text := FromWebVtt(test.text); text != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.text, text, test.expected)
+		}
+	}
+}
+
+func TestToWebVtt(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "<i>Italic</i> <b>bold</b>", expected: "<i>Italic</i> <b>bold</b>"},
+		{text: `<font color="#ffff00">Yellow</font>`, expected: "<c.yellow>Yellow</c>"},
+		{text: `<font color="#00ff00"><i>Lime</i></font>`, expected: "<c.lime><i>Lime</i></c>"},
+		{text: `<font color="#123456">Unknown</font>`, expected: "Unknown"},
+		{text: `<font face="Arial">Font</font>`, expected: "Font"},
+		{text: "a < b & c", expected: "a &lt; b &amp; c"},
+		{text: "3 > 2", expected: "3 &gt; 2"},
+		{text: "<i>One</i>\r\ntwo", expected: "<i>One</i>\ntwo"},
+	}
+
+	for _, test := range tests {
+		if text := ToWebVtt(test.text); text != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.text, text, test.expected)
+		}
+	}
+}
@@ -6,7 +6,7 @@ require (
 	github.com/andybalholm/crlf v0.0.0-20171020200849-670099aa064f
 	github.com/cockroachdb/errors v1.12.0
 	github.com/edsrzf/mmap-go v1.2.0
-	golang.org/x/sys v0.33.0
+	golang.org/x/text v0.25.0
 )
 
 require (
@@ -18,5 +18,5 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rogpeppe/go-internal v1.9.0 // indirect
-	golang.org/x/text v0.25.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
 )
@@ -0,0 +1,249 @@
+package hearingimpaired
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ristryder/gse/common"
+	"github.com/ristryder/gse/commonerrors"
+)
+
+const musicSymbols = "♪♫#"
+
+var (
+	emptyTagsRegex     = regexp.MustCompile(`<([biu]|font)(?:\s[^<>]*)?>\s*</([biu]|font)>`)
+	multipleSpaceRegex = regexp.MustCompile(`[ \t]{2,}`)
+	//A speaker label starts with a letter, so that times such as 10:30 are not labels
+	speakerLabelRegex = regexp.MustCompile(`^(\p{L}[^:\n]{0,29}?)\s*:(?:\s+|$)`)
+)
+
+// DefaultInterjections are English interjections removed when they are all a line says or start it
+var DefaultInterjections = []string{"Aah", "Ah", "Ahh", "Eh", "Er", "Hm", "Hmm", "Huh", "Mm", "Mmm", "Oh", "Ohh", "Ooh", "Uh", "Uh-huh", "Uh-oh", "Uhh", "Um", "Umm", "Whoa"}
+
+// Remover removes the text for the hearing impaired from subtitles, as found in SDH subtitles. Paragraphs left
+// without text are removed
+type Remover struct {
+	//Brackets are the pairs of opening and closing characters around sound descriptions, such as "[]" and "()"
+	Brackets []string
+	//Interjections are removed when they are all a line says, start it or stand between commas
+	Interjections       []string
+	RemoveInterjections bool
+	//RemoveMusicLines removes lines that start and end with a music symbol, or are only music symbols
+	RemoveMusicLines bool
+	//RemoveSpeakerLabels removes labels such as JOHN: before the text of a line
+	RemoveSpeakerLabels bool
+	//UppercaseLabelsOnly only removes speaker labels without lowercase letters, keeping text such as Note: or Tip:
+	UppercaseLabelsOnly bool
+}
+
+// NewRemover returns a remover of bracketed and parenthesized descriptions, uppercase speaker labels, music lines and
+// English interjections
+func NewRemover() *Remover {
+	return &Remover{
+		Brackets:            []string{"[]", "()"},
+		Interjections:       slices.Clone(DefaultInterjections),
+		RemoveInterjections: true,
+		RemoveMusicLines:    true,
+		RemoveSpeakerLabels: true,
+		UppercaseLabelsOnly: true,
+	}
+}
+
+func (r *Remover) String() string {
+	return fmt.Sprintf("Brackets: %v , RemoveInterjections: %v , RemoveMusicLines: %v , RemoveSpeakerLabels: %v , UppercaseLabelsOnly: %v , Interjections: %v", r.Brackets, r.RemoveInterjections, r.RemoveMusicLines, r.RemoveSpeakerLabels, r.UppercaseLabelsOnly, r.Interjections)
+}
+
+func (r *Remover) Find(subtitle *common.Subtitle) []commonerrors.Fix {
+	interjections := r.interjectionRegexes()
+	fixes := commonerrors.FindTextFixes(subtitle, r.Name(), func(text string) string {
+		return r.removeText(text, interjections)
+	})
+
+	for i := range fixes {
+		fixes[i].IsRemoval = fixes[i].After.Text == ""
+	}
+
+	return fixes
+}
+
+func (r *Remover) Name() string {
+	return "Remove text for hearing impaired"
+}
+
+// RemoveText returns the text of a paragraph without the text for the hearing impaired, which is empty when nothing
+// else is left
+func (r *Remover) RemoveText(text string) string {
+	return r.removeText(text, r.interjectionRegexes())
+}
+
+type dialogLine struct {
+	hasDash   bool
+	isLabeled bool
+	rest      string
+	tags      string
+}
+
+func (d *dialogLine) String() string {
+	if d.hasDash {
+		return d.tags + "- " + d.rest
+	}
+
+	return d.tags + d.rest
+}
+
+// interjectionRegexes match a line that is only interjections, an interjection starting a line and one between
+// commas or before the end of a sentence
+type interjectionRegexes struct {
+	between *regexp.Regexp
+	line    *regexp.Regexp
+	start   *regexp.Regexp
+}
+
+func (r *Remover) interjectionRegexes() *interjectionRegexes {
+	if !r.RemoveInterjections || len(r.Interjections) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(r.Interjections))
+	for i, interjection := range r.Interjections {
+		quoted[i] = regexp.QuoteMeta(interjection)
+	}
+	words := `(?i:` + strings.Join(quoted, "|") + `)`
+
+	return &interjectionRegexes{
+		between: regexp.MustCompile(`,\s*` + words + `\s*([,!?.…])`),
+		line:    regexp.MustCompile(`^(?:` + words + `[,!?.…]*\s*)+$`),
+		start:   regexp.MustCompile(`^` + words + `(?:[,!?.…]+\s+|\s*-\s+)(\S)`),
+	}
+}
+
+func (r *Remover) removeText(text string, interjections *interjectionRegexes) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, pair := range r.Brackets {
+		open, size := utf8.DecodeRuneInString(pair)
+		closing, _ := utf8.DecodeRuneInString(pair[size:])
+		text = removeBetween(text, open, closing)
+	}
+
+	if r.RemoveMusicLines && isMusic(text) {
+		return ""
+	}
+
+	lines := []dialogLine{}
+	labels := 0
+	pendingTags := ""
+	for _, line := range strings.Split(text, "\n") {
+		tags, hasDash, rest := commonerrors.SplitDialogDash(line)
+		tags = pendingTags + tags
+		pendingTags = ""
+		isLabeled := false
+
+		if r.RemoveSpeakerLabels {
+			if label := speakerLabelRegex.FindStringSubmatch(rest); label != nil && (!r.UppercaseLabelsOnly || isUppercase(label[1])) {
+				rest = rest[len(label[0]):]
+				isLabeled = true
+				labels++
+			}
+		}
+		if r.RemoveMusicLines && isMusic(rest) {
+			continue
+		}
+		if interjections != nil {
+			rest = interjections.remove(rest)
+		}
+
+		//Text left by brackets removed at the start of the line, such as the colon of [JOHN]:
+		rest = strings.TrimLeft(strings.TrimSpace(rest), ": ")
+		if strings.TrimSpace(commonerrors.TagRegex.ReplaceAllString(rest, "")) == "" {
+			//The tags of a line without text move to the line before it, or the line after it on the first line
+			if len(lines) > 0 {
+				lines[len(lines)-1].rest += tags + rest
+			} else {
+				pendingTags = tags + rest
+			}
+
+			continue
+		}
+
+		lines = append(lines, dialogLine{hasDash: hasDash, isLabeled: isLabeled, rest: rest, tags: tags})
+	}
+
+	dashes := 0
+	for i := range lines {
+		//Lines that lost a speaker label become dialog lines when several speakers were labeled
+		lines[i].hasDash = lines[i].hasDash || lines[i].isLabeled && labels > 1
+		if lines[i].hasDash {
+			dashes++
+		}
+	}
+	//A dash is left without a dialog when the other speakers were removed
+	if len(lines) == 1 || dashes == 1 && lines[0].hasDash {
+		lines[0].hasDash = false
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.String()
+	}
+
+	text = strings.Join(texts, "\n")
+	text = multipleSpaceRegex.ReplaceAllString(emptyTagsRegex.ReplaceAllString(text, ""), " ")
+	if strings.TrimSpace(commonerrors.TagRegex.ReplaceAllString(text, "")) == "" {
+		return ""
+	}
+
+	return strings.TrimSpace(text)
+}
+
+func (i *interjectionRegexes) remove(text string) string {
+	if i.line.MatchString(commonerrors.TagRegex.ReplaceAllString(text, "")) {
+		return ""
+	}
+
+	text = i.between.ReplaceAllString(text, "$1")
+	if match := i.start.FindStringSubmatchIndex(text); match != nil {
+		next, _ := utf8.DecodeRuneInString(text[match[2]:])
+		text = string(unicode.ToUpper(next)) + text[match[2]+utf8.RuneLen(next):]
+	}
+
+	return text
+}
+
+// isMusic reports whether text starts and ends with a music symbol, or is only music symbols
+func isMusic(text string) bool {
+	text = strings.TrimSpace(commonerrors.TagRegex.ReplaceAllString(text, ""))
+	if text == "" {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(text)
+	last, _ := utf8.DecodeLastRuneInString(text)
+
+	return strings.ContainsRune(musicSymbols, first) && strings.ContainsRune(musicSymbols, last)
+}
+
+func isUppercase(text string) bool {
+	return strings.IndexFunc(text, unicode.IsLower) < 0 && strings.IndexFunc(text, unicode.IsUpper) >= 0
+}
+
+// removeBetween removes the text between pairs of opening and closing characters, including the characters
+func removeBetween(text string, open rune, closing rune) string {
+	for {
+		start := strings.IndexRune(text, open)
+		if start < 0 {
+			return text
+		}
+
+		end := strings.IndexRune(text[start+utf8.RuneLen(open):], closing)
+		if end < 0 {
+			return text
+		}
+		end += start + utf8.RuneLen(open) + utf8.RuneLen(closing)
+
+		text = text[:start] + text[end:]
+	}
+}
@@ -0,0 +1,91 @@
+package hearingimpaired
+
+import (
+	"testing"
+
+	"github.com/ristryder/gse/common"
+	"github.com/ristryder/gse/commonerrors"
+)
+
+func TestRemoveText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "[DOOR OPENS]", expected: ""},
+		{text: "[sighs] I know.", expected: "I know."},
+		{text: "(laughing) <i>Come here.</i>", expected: "<i>Come here.</i>"},
+		{text: "<i>[MUSIC]</i>", expected: ""},
+		{text: "[JOHN]: Hello.", expected: "Hello."},
+		{text: "JOHN: Hello.", expected: "Hello."},
+		{text: "Note: this stays.", expected: "Note: this stays."},
+		{text: "It's 10:30 now.", expected: "It's 10:30 now."},
+		{text: "- JOHN: Hi.\n- MARY: Hello.", expected: "- Hi.\n- Hello."},
+		{text: "JOHN: Hi.\nMARY: Hello.", expected: "- Hi.\n- Hello."},
+		{text: "- [gasps]\n- What was that?", expected: "What was that?"},
+		{text: "- Hello.\n- (laughing)", expected: "Hello."},
+		{text: "<i>- [gasps]</i>\n<i>- Who's there?</i>", expected: "<i>Who's there?</i>"},
+		{text: "- Hello.\n- Hi.", expected: "- Hello.\n- Hi."},
+		{text: "♪ Music playing ♪", expected: ""},
+		{text: "♪ La la ♪\nHe sings.", expected: "He sings."},
+		{text: "Um, I think so.", expected: "I think so."},
+		{text: "Oh!", expected: ""},
+		{text: "Well, uh, I guess.", expected: "Well, I guess."},
+		{text: "- Hmm.\n- You agree?", expected: "You agree?"},
+	}
+
+	remover := NewRemover()
+	for _, test := range tests {
+		if text := remover.RemoveText(test.text); text != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.text, text, test.expected)
+		}
+	}
+}
+
+func TestRemoveTextOptions(t *testing.T) {
+	tests := []struct {
+		remover  *Remover
+		text     string
+		expected string
+	}{
+		{remover: &Remover{RemoveSpeakerLabels: true}, text: "Note: this goes.", expected: "this goes."},
+		{remover: &Remover{Brackets: []string{"{}"}}, text: "{noise} [kept]", expected: "[kept]"},
+		{remover: &Remover{}, text: "♪ La la ♪", expected: "♪ La la ♪"},
+		{remover: &Remover{Interjections: []string{"Oh"}}, text: "Oh, no.", expected: "Oh, no."},
+		{remover: &Remover{Interjections: []string{"Well"}, RemoveInterjections: true}, text: "Well, no. Um, yes.", expected: "No. Um, yes."},
+	}
+
+	for _, test := range tests {
+		if text := test.remover.RemoveText(test.text); text != test.expected {
+			t.Errorf("%s: %q got %q, expected %q", test.remover.String(), test.text, text, test.expected)
+		}
+	}
+}
+
+func TestRemoverFind(t *testing.T) {
+	subtitle := &common.Subtitle{Paragraphs: []common.Paragraph{
+		*common.NewParagraph("[DOOR OPENS]", 1000, 2000),
+		*common.NewParagraph("JOHN: Hello.", 3000, 4000),
+		*common.NewParagraph("Unchanged.", 5000, 6000),
+	}}
+	subtitle.Renumber(1)
+
+	remover := NewRemover()
+	fixes := remover.Find(subtitle)
+	if len(fixes) != 2 {
+		t.Fatalf("got %d fixes, expected 2", len(fixes))
+	}
+	if !fixes[0].IsRemoval || fixes[0].Index != 0 || fixes[0].Fixer != remover.Name() {
+		t.Errorf("got fix %s, expected the first paragraph to be removed", fixes[0].String())
+	}
+	if fixes[1].IsRemoval || fixes[1].Index != 1 || fixes[1].After.Text != "Hello." {
+		t.Errorf("got fix %s, expected the label of the second paragraph to be removed", fixes[1].String())
+	}
+
+	if applied := commonerrors.Apply(subtitle, fixes); applied != 2 {
+		t.Errorf("got %d fixes applied, expected 2", applied)
+	}
+	if len(subtitle.Paragraphs) != 2 || subtitle.Paragraphs[0].Text != "Hello." || subtitle.Paragraphs[1].Number != 2 {
+		t.Errorf("got %v", subtitle.Paragraphs)
+	}
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/ristryder/gse/common"
+
+// SubtitleFormat is a text subtitle format that can be detected, read and written
+type SubtitleFormat interface {
+	Errors() string
+	Extension() string
+	IsMine(lines []string, fileName string) (bool, error)
+	LoadSubtitle(subtitle *common.Subtitle, lines []string, fileName string) error
+	Name() string
+	ToText(subtitle *common.Subtitle, title string) string
+}
@@ -0,0 +1,95 @@
+// Package mkvtest builds small Matroska files in memory for the tests of the packages reading them
+package mkvtest
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+)
+
+// Track types of the Matroska specification
+const (
+	TrackTypeSubtitle = 0x11
+	TrackTypeVideo    = 0x01
+)
+
+// Block returns the data of a Block or SimpleBlock element: the track number, the time code relative to the
+// cluster, the flags and the frame data including any lacing header
+func Block(trackNumber uint64, timeCode int16, flags byte, data []byte) []byte {
+	block := append(size(trackNumber, 1), byte(uint16(timeCode)>>8), byte(timeCode), flags)
+
+	return append(block, data...)
+}
+
+// Element returns an EBML element with the concatenated data, its size is always written in 8 bytes so that the
+// positions of the elements can be computed from their lengths
+func Element(id uint32, data ...[]byte) []byte {
+	element := idBytes(id)
+	content := slices.Concat(data...)
+	element = append(element, size(uint64(len(content)), 8)...)
+
+	return append(element, content...)
+}
+
+// File returns a Matroska file with an EBML header and a segment of the elements
+func File(segmentChildren ...[]byte) []byte {
+	return FileOfDocType("matroska", segmentChildren...)
+}
+
+// FileOfDocType returns a file of an EBML document type, such as webm, with a segment of the elements
+func FileOfDocType(docType string, segmentChildren ...[]byte) []byte {
+	header := Element(0x1A45DFA3, UInt(0x4286, 1), UInt(0x42F7, 1), UInt(0x42F2, 4), UInt(0x42F3, 8), String(0x4282, docType), UInt(0x4287, 4), UInt(0x4285, 2))
+
+	return append(header, Element(0x18538067, segmentChildren...)...)
+}
+
+// Float returns an EBML float element of 8 bytes
+func Float(id uint32, value float64) []byte {
+	return Element(id, binary.BigEndian.AppendUint64(nil, math.Float64bits(value)))
+}
+
+// SegmentDataOffset returns the offset of the data of the segment of a file made by File, to which cue and seek
+// positions are relative
+func SegmentDataOffset() int {
+	return len(File())
+}
+
+// String returns an EBML string element
+func String(id uint32, value string) []byte {
+	return Element(id, []byte(value))
+}
+
+// UInt returns an EBML unsigned integer element in as few bytes as the value needs, as muxers write them
+func UInt(id uint32, value uint64) []byte {
+	data := binary.BigEndian.AppendUint64(nil, value)
+	for len(data) > 1 && data[0] == 0 {
+		data = data[1:]
+	}
+
+	return Element(id, data)
+}
+
+func idBytes(id uint32) []byte {
+	switch {
+	case id > 0xFFFFFF:
+		return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFFFF:
+		return []byte{byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFF:
+		return []byte{byte(id >> 8), byte(id)}
+	}
+
+	return []byte{byte(id)}
+}
+
+// size returns an EBML variable length integer in a number of bytes
+func size(value uint64, length int) []byte {
+	data := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		data[i] = byte(value)
+		value >>= 8
+	}
+	data[0] |= 1 << (8 - length)
+
+	return data
+}
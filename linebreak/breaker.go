@@ -0,0 +1,282 @@
+package linebreak
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ristryder/gse/common"
+	"github.com/ristryder/gse/commonerrors"
+)
+
+const (
+	//Breaking after punctuation is rewarded in the unit of the cost of a line, the squared difference of its length
+	//from the ideal length
+	clauseBonus = 50.0
+	//overflowCost is the cost of each character of a line beyond the maximum line length
+	overflowCost = 10000
+	//pyramidSlope is how much longer than the average, as a fraction of it, each line of a pyramid is than the line
+	//above it
+	pyramidSlope  = 0.2
+	sentenceBonus = 100.0
+)
+
+var inlineDialogRegex = regexp.MustCompile(`([.!?…؟。！？]["'”’»]*(?:</[^<>]+>)*) +((?:<[^<>]+>)*[` + commonerrors.DialogDashes + `] )`)
+
+// Breaker wraps the text of paragraphs to a maximum line length and number of lines, balancing the lengths of the
+// lines and preferring breaks after punctuation. Every speaker of a dialog gets a line of its own
+type Breaker struct {
+	//MaxLineLength counts the characters of a line without formatting tags and direction marks
+	MaxLineLength int
+	MaxLines      int
+	//Pyramid makes each line longer than the one above it instead of making the lines even
+	Pyramid bool
+}
+
+// candidate is a position where a line may break, a break at a space replaces the space
+type candidate struct {
+	at    int
+	bonus float64
+	skip  int
+}
+
+// NewBreaker returns a breaker to the two lines of 42 characters of the Netflix timed text style guide
+func NewBreaker() *Breaker {
+	return &Breaker{
+		MaxLineLength: 42,
+		MaxLines:      2,
+	}
+}
+
+func (b *Breaker) String() string {
+	return fmt.Sprintf("MaxLineLength: %v , MaxLines: %v , Pyramid: %v", b.MaxLineLength, b.MaxLines, b.Pyramid)
+}
+
+// AutoBreak unbreaks text and breaks it again into as few lines as fit the maximum line length, with lines of even
+// or pyramid lengths. Dialogs get a line for each speaker. Text that fits on no number of lines up to the maximum
+// is broken into the maximum number of lines with the least overflow
+func (b *Breaker) AutoBreak(text string) string {
+	text = Unbreak(text)
+	if strings.Contains(text, "\n") || b.MaxLineLength <= 0 {
+		return text
+	}
+
+	candidates := breakCandidates(text)
+	visible := visibleLengths(text)
+	maxLines := max(b.MaxLines, 1)
+
+	best, bestOverflow := text, true
+	for lines := 1; lines <= maxLines && lines <= len(candidates)+1; lines++ {
+		best, bestOverflow = b.breakInto(text, lines, candidates, visible)
+		if !bestOverflow {
+			break
+		}
+	}
+
+	return best
+}
+
+func (b *Breaker) Find(subtitle *common.Subtitle) []commonerrors.Fix {
+	return commonerrors.FindTextFixes(subtitle, b.Name(), func(text string) string {
+		//Only line endings changing is not a fix
+		if broken := b.AutoBreak(text); broken != strings.ReplaceAll(text, "\r\n", "\n") {
+			return broken
+		}
+
+		return text
+	})
+}
+
+func (b *Breaker) Name() string {
+	return "Auto balance lines"
+}
+
+// breakInto breaks text into a number of lines at the candidates with the least total cost, and reports whether a
+// line is longer than the maximum line length
+func (b *Breaker) breakInto(text string, lines int, candidates []candidate, visible []int) (string, bool) {
+	//The start and end of the text are the first and last positions
+	positions := append(append([]candidate{{}}, candidates...), candidate{at: len(text)})
+	mean := float64(visible[len(text)]-(lines-1)) / float64(lines)
+
+	lineLength := func(from candidate, to candidate) int {
+		return visible[to.at] - visible[min(from.at+from.skip, to.at)]
+	}
+	lineCost := func(line int, from candidate, to candidate) float64 {
+		target := mean
+		if b.Pyramid {
+			target += mean * pyramidSlope * (float64(line) - float64(lines-1)/2)
+		}
+
+		length := lineLength(from, to)
+		cost := math.Pow(float64(length)-target, 2) - to.bonus
+		if length > b.MaxLineLength {
+			cost += float64(length-b.MaxLineLength) * overflowCost
+		}
+
+		return cost
+	}
+
+	//costs[line][i] is the least cost of the lines up to one ending at position i, previous[line][i] is where it starts
+	costs := make([][]float64, lines)
+	previous := make([][]int, lines)
+	for line := range costs {
+		costs[line] = make([]float64, len(positions))
+		previous[line] = make([]int, len(positions))
+		for i := range costs[line] {
+			costs[line][i] = math.Inf(1)
+		}
+	}
+
+	for i := 1; i < len(positions); i++ {
+		costs[0][i] = lineCost(0, positions[0], positions[i])
+	}
+	for line := 1; line < lines; line++ {
+		for i := line + 1; i < len(positions); i++ {
+			for j := line; j < i; j++ {
+				if cost := costs[line-1][j] + lineCost(line, positions[j], positions[i]); cost < costs[line][i] {
+					costs[line][i], previous[line][i] = cost, j
+				}
+			}
+		}
+	}
+
+	ends := make([]int, lines)
+	ends[lines-1] = len(positions) - 1
+	for line := lines - 1; line > 0; line-- {
+		ends[line-1] = previous[line][ends[line]]
+	}
+
+	parts := make([]string, lines)
+	overflow := false
+	start := positions[0]
+	for line, end := range ends {
+		parts[line] = text[start.at+start.skip : positions[end].at]
+		overflow = overflow || lineLength(start, positions[end]) > b.MaxLineLength
+		start = positions[end]
+	}
+
+	return strings.Join(parts, "\n"), overflow
+}
+
+// Unbreak joins the lines of text into one line, except for dialogs which keep a line for each speaker. A dialog
+// written on one line, as in "- Hi. - Hello.", is split into its speakers
+func Unbreak(text string) string {
+	lines := []string{}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(lines) == 0 || isDialogLine(line) {
+			lines = append(lines, line)
+
+			continue
+		}
+
+		last := len(lines) - 1
+		if strings.HasSuffix(lines[last], "</i>") && strings.HasPrefix(line, "<i>") {
+			lines[last] = strings.TrimSuffix(lines[last], "</i>") + " " + strings.TrimPrefix(line, "<i>")
+
+			continue
+		}
+
+		previousRune, _ := utf8.DecodeLastRuneInString(commonerrors.TagRegex.ReplaceAllString(lines[last], ""))
+		nextRune, _ := utf8.DecodeRuneInString(commonerrors.TagRegex.ReplaceAllString(line, ""))
+		if isCjk(previousRune) && isCjk(nextRune) {
+			lines[last] += line
+		} else {
+			lines[last] += " " + line
+		}
+	}
+
+	for i, line := range lines {
+		if isDialogLine(line) {
+			lines[i] = inlineDialogRegex.ReplaceAllString(line, "$1\n$2")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// breakCandidates returns the positions outside formatting tags where a line may break: the spaces between words,
+// except before punctuation such as the French « ? », and between the characters of CJK text
+func breakCandidates(text string) []candidate {
+	candidates := []candidate{}
+	inTag := make([]bool, len(text))
+	for _, tag := range commonerrors.TagRegex.FindAllStringIndex(text, -1) {
+		for i := tag[0]; i < tag[1]; i++ {
+			inTag[i] = true
+		}
+	}
+
+	previous := rune(0)
+	for i, r := range text {
+		if inTag[i] || !isVisible(r) {
+			continue
+		}
+
+		next, _ := utf8.DecodeRuneInString(commonerrors.TagRegex.ReplaceAllString(text[i+utf8.RuneLen(r):], ""))
+		switch {
+		case r == ' ':
+			if previous != 0 && previous != ' ' && previous != '«' && next != ' ' && !strings.ContainsRune("!?:;»", next) {
+				candidates = append(candidates, candidate{at: i, bonus: punctuationBonus(previous), skip: 1})
+			}
+		case previous != 0 && previous != ' ' && (isCjk(previous) || isCjk(r)):
+			if !strings.ContainsRune(closingPunctuation, r) && !strings.ContainsRune(openingPunctuation, previous) {
+				candidates = append(candidates, candidate{at: i, bonus: punctuationBonus(previous)})
+			}
+		}
+
+		previous = r
+	}
+
+	return candidates
+}
+
+// isDialogLine reports whether a line starts with a dialog dash after its formatting tags
+func isDialogLine(line string) bool {
+	_, hasDash, _ := commonerrors.SplitDialogDash(line)
+
+	return hasDash
+}
+
+func punctuationBonus(last rune) float64 {
+	switch {
+	case strings.ContainsRune(sentencePunctuation, last):
+		return sentenceBonus
+	case strings.ContainsRune(clausePunctuation, last):
+		return clauseBonus
+	}
+
+	return 0
+}
+
+// visibleLengths returns the number of visible characters before every byte of the text, formatting tags and
+// direction marks are not visible
+func visibleLengths(text string) []int {
+	lengths := make([]int, len(text)+1)
+	inTag := make([]bool, len(text))
+	for _, tag := range commonerrors.TagRegex.FindAllStringIndex(text, -1) {
+		for i := tag[0]; i < tag[1]; i++ {
+			inTag[i] = true
+		}
+	}
+
+	count := 0
+	for i := range text {
+		lengths[i] = count
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !inTag[i] && isVisible(r) {
+			count++
+		}
+		for j := 1; j < size; j++ {
+			lengths[i+j] = count
+		}
+	}
+	lengths[len(text)] = count
+
+	return lengths
+}
@@ -0,0 +1,132 @@
+package linebreak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+func TestUnbreak(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "One line\nbroken in two", expected: "One line broken in two"},
+		{text: " Spaces \r\n\r\naround ", expected: "Spaces around"},
+		{text: "<i>In italics</i>\n<i>on two lines</i>", expected: "<i>In italics on two lines</i>"},
+		{text: "- Hi.\n- Hello,\nthere.", expected: "- Hi.\n- Hello, there."},
+		{text: "<i>- Hi.</i>\n<i>- Hello.</i>", expected: "<i>- Hi.</i>\n<i>- Hello.</i>"},
+		{text: "- Hi. - Hello.", expected: "- Hi.\n- Hello."},
+		{text: "- Are you sure? – Yes!", expected: "- Are you sure?\n– Yes!"},
+		{text: "Not a - dialog", expected: "Not a - dialog"},
+		{text: "日本語の\n字幕です", expected: "日本語の字幕です"},
+		{text: "<i>中文</i>\n字幕", expected: "<i>中文</i>字幕"},
+	}
+
+	for _, test := range tests {
+		if text := Unbreak(test.text); text != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.text, text, test.expected)
+		}
+	}
+}
+
+func TestAutoBreak(t *testing.T) {
+	tests := []struct {
+		breaker  *Breaker
+		text     string
+		expected string
+	}{
+		{breaker: NewBreaker(), text: "Short enough", expected: "Short enough"},
+		{breaker: NewBreaker(), text: "Short\nenough", expected: "Short enough"},
+		{
+			breaker:  NewBreaker(),
+			text:     "This sentence is much too long to fit on a single line of text",
+			expected: "This sentence is much too long\nto fit on a single line of text",
+		},
+		{
+			breaker:  NewBreaker(),
+			text:     "We should go now. It is getting very late here",
+			expected: "We should go now.\nIt is getting very late here",
+		},
+		{
+			breaker:  NewBreaker(),
+			text:     "<i>This sentence in italics is much too long to fit on one line</i>",
+			expected: "<i>This sentence in italics is\nmuch too long to fit on one line</i>",
+		},
+		{breaker: NewBreaker(), text: "- Hi. - Hello, how are you?", expected: "- Hi.\n- Hello, how are you?"},
+		{
+			//Lines do not start with the punctuation of French after its space
+			breaker:  &Breaker{MaxLineLength: 20, MaxLines: 2},
+			text:     "Vraiment ? Je ne le savais pas du tout !",
+			expected: "Vraiment ? Je ne le\nsavais pas du tout !",
+		},
+		{
+			breaker:  &Breaker{MaxLineLength: 15, MaxLines: 2},
+			text:     "これは日本語の長い字幕です。二行に分けます。",
+			expected: "これは日本語の長い字幕です。\n二行に分けます。",
+		},
+		{
+			breaker:  &Breaker{MaxLineLength: 20, MaxLines: 3},
+			text:     "one two three four five six seven eight nine ten eleven twelve",
+			expected: "one two three four\nfive six seven eight\nnine ten eleven twelve",
+		},
+		{
+			//Text too long for the maximum number of lines overflows evenly
+			breaker:  &Breaker{MaxLineLength: 10, MaxLines: 2},
+			text:     "これは日本語の長い字幕です。二行に分けます。",
+			expected: "これは日本語の長い字幕\nです。二行に分けます。",
+		},
+		{breaker: &Breaker{MaxLineLength: 0, MaxLines: 2}, text: "Not\nbroken again", expected: "Not broken again"},
+	}
+
+	for _, test := range tests {
+		if text := test.breaker.AutoBreak(test.text); text != test.expected {
+			t.Errorf("%s: %q got %q, expected %q", test.breaker.String(), test.text, text, test.expected)
+		}
+	}
+}
+
+func TestAutoBreakPyramid(t *testing.T) {
+	text := "one two three four five six seven eight nine ten eleven twelve"
+
+	even := (&Breaker{MaxLineLength: 40, MaxLines: 2}).AutoBreak(text)
+	pyramid := (&Breaker{MaxLineLength: 40, MaxLines: 2, Pyramid: true}).AutoBreak(text)
+
+	evenLines, pyramidLines := strings.Split(even, "\n"), strings.Split(pyramid, "\n")
+	if len(evenLines) != 2 || len(pyramidLines) != 2 {
+		t.Fatalf("got %q and %q, expected two lines each", even, pyramid)
+	}
+	if len(pyramidLines[0]) >= len(pyramidLines[1]) || len(pyramidLines[0]) >= len(evenLines[0]) {
+		t.Errorf("got pyramid %q and even %q, expected a shorter first line in the pyramid", pyramid, even)
+	}
+}
+
+func TestAutoBreakVisibleLength(t *testing.T) {
+	//Direction marks and tags do not count towards the line length
+	text := "‏<i>שלום</i> עולם‏"
+	if broken := (&Breaker{MaxLineLength: 10, MaxLines: 2}).AutoBreak(text); broken != text {
+		t.Errorf("got %q, expected %q", broken, text)
+	}
+}
+
+func TestBreakerFind(t *testing.T) {
+	subtitle := &common.Subtitle{Paragraphs: []common.Paragraph{
+		*common.NewParagraph("This sentence is much too long to fit on a single line of text", 1000, 2000),
+		*common.NewParagraph("- Only line endings\r\n- change", 3000, 4000),
+		*common.NewParagraph("Fits", 5000, 6000),
+		*common.NewParagraph("Two\r\nlines", 7000, 8000),
+	}}
+
+	breaker := &Breaker{MaxLineLength: 42, MaxLines: 2}
+	fixes := breaker.Find(subtitle)
+	if len(fixes) != 2 {
+		t.Fatalf("got %d fixes, expected 2", len(fixes))
+	}
+	if fixes[0].Index != 0 || fixes[0].After.Text != "This sentence is much too long\nto fit on a single line of text" || fixes[0].Fixer != breaker.Name() {
+		t.Errorf("got fix %s", fixes[0].String())
+	}
+	if fixes[1].Index != 3 || fixes[1].After.Text != "Two lines" {
+		t.Errorf("got fix %s", fixes[1].String())
+	}
+}
@@ -0,0 +1,29 @@
+package linebreak
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	//clausePunctuation ends a clause, including the Arabic comma and semicolon and the CJK commas
+	clausePunctuation = ",;:،؛、，；："
+	//closingPunctuation may not start a line of CJK text
+	closingPunctuation = "、。，．！？：；）」』】〉》〕ー…‥ゝゞヽヾ々ぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ!?.,:;)]}\"'”’"
+	//openingPunctuation may not end a line of CJK text
+	openingPunctuation = "（「『【〈《〔([{“‘"
+	//sentencePunctuation ends a sentence, including the Arabic question mark and the CJK full stops
+	sentencePunctuation = ".!?…؟。！？"
+)
+
+// isCjk reports whether a character is written without spaces between words, so that a line may break next to it
+func isCjk(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) ||
+		strings.ContainsRune("、。，．！？：；（）「」『』【】〈〉《》〔〕ー…‥々", r)
+}
+
+// isVisible reports whether a character takes up space on a line, which the direction marks of right to left text
+// do not
+func isVisible(r rune) bool {
+	return !unicode.Is(unicode.Cf, r)
+}
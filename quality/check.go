@@ -0,0 +1,131 @@
+package quality
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ristryder/gse/common"
+)
+
+var tagRegex = regexp.MustCompile(`<[^<>]*>`)
+
+// Check checks the paragraphs of a subtitle against the rules. Shot changes are times in milliseconds, a start
+// must be on a shot change close to it and an end on one or at least the minimum gap before it
+func (r *Rules) Check(subtitle *common.Subtitle, shotChanges []float64) *Report {
+	report := &Report{Issues: []Issue{}}
+	frameDuration := 1000.0 / common.FrameRateOrDefault(r.FrameRate)
+
+	shotChanges = slices.Clone(shotChanges)
+	slices.Sort(shotChanges)
+
+	for i, paragraph := range subtitle.Paragraphs {
+		number := paragraph.Number
+		if number == 0 {
+			number = i + 1
+		}
+
+		lines := strings.Split(strings.ReplaceAll(tagRegex.ReplaceAllString(paragraph.Text, ""), "\r\n", "\n"), "\n")
+		characters := 0
+		for j, line := range lines {
+			count := utf8.RuneCountInString(line)
+			characters += count
+
+			if r.MaxCharactersPerLine > 0 && count > r.MaxCharactersPerLine {
+				report.add(number, j+1, RuleMaxCharactersPerLine, float64(count), float64(r.MaxCharactersPerLine),
+					fmt.Sprintf("line %d has %d characters, more than %d", j+1, count, r.MaxCharactersPerLine))
+			}
+		}
+
+		if r.MaxLines > 0 && len(lines) > r.MaxLines {
+			report.add(number, 0, RuleMaxLines, float64(len(lines)), float64(r.MaxLines),
+				fmt.Sprintf("%d lines, more than %d", len(lines), r.MaxLines))
+		}
+
+		duration := paragraph.Duration().TotalMilliseconds
+		if r.MinDuration > 0 && duration < r.MinDuration {
+			report.add(number, 0, RuleMinDuration, duration, r.MinDuration,
+				fmt.Sprintf("duration of %.0f ms, less than %.0f ms", duration, r.MinDuration))
+		}
+		if r.MaxDuration > 0 && duration > r.MaxDuration {
+			report.add(number, 0, RuleMaxDuration, duration, r.MaxDuration,
+				fmt.Sprintf("duration of %.0f ms, more than %.0f ms", duration, r.MaxDuration))
+		}
+
+		if r.MaxCharactersPerSecond > 0 && duration > 0 {
+			if charactersPerSecond := float64(characters) * 1000 / duration; charactersPerSecond > r.MaxCharactersPerSecond {
+				report.add(number, 0, RuleMaxCharactersPerSecond, charactersPerSecond, r.MaxCharactersPerSecond,
+					fmt.Sprintf("%.1f characters per second, more than %v", charactersPerSecond, r.MaxCharactersPerSecond))
+			}
+		}
+
+		if r.ForbiddenCharacters != "" {
+			found := []rune{}
+			for _, character := range paragraph.Text {
+				if strings.ContainsRune(r.ForbiddenCharacters, character) && !slices.Contains(found, character) {
+					found = append(found, character)
+				}
+			}
+			if len(found) > 0 {
+				report.add(number, 0, RuleForbiddenCharacters, float64(len(found)), 0,
+					fmt.Sprintf("forbidden characters %q", string(found)))
+			}
+		}
+
+		if i+1 < len(subtitle.Paragraphs) {
+			gap := subtitle.Paragraphs[i+1].StartTime.TotalMilliseconds - paragraph.EndTime.TotalMilliseconds
+			minGap := float64(r.MinGapFrames) * frameDuration
+
+			switch {
+			case gap < 0:
+				report.add(number, 0, RuleOverlap, gap, 0, fmt.Sprintf("overlaps the next paragraph by %.0f ms", -gap))
+			case r.MinGapFrames > 0 && gap < minGap-frameDuration/2:
+				report.add(number, 0, RuleMinGap, math.Round(gap/frameDuration), float64(r.MinGapFrames),
+					fmt.Sprintf("gap of %.0f frames to the next paragraph, less than %d", math.Round(gap/frameDuration), r.MinGapFrames))
+			}
+		}
+
+		if r.ShotChangeFrames > 0 && len(shotChanges) > 0 {
+			r.checkShotChange(report, number, "start", paragraph.StartTime.TotalMilliseconds, shotChanges, 0)
+			r.checkShotChange(report, number, "end", paragraph.EndTime.TotalMilliseconds, shotChanges, r.MinGapFrames)
+		}
+	}
+
+	return report
+}
+
+// checkShotChange reports a time close to a shot change that is not on it, or for ends not the allowed number of
+// frames before it
+func (r *Rules) checkShotChange(report *Report, number int, name string, milliseconds float64, shotChanges []float64, allowedFramesBefore int) {
+	frameDuration := 1000.0 / common.FrameRateOrDefault(r.FrameRate)
+
+	index, _ := slices.BinarySearch(shotChanges, milliseconds)
+	nearest := 0
+	isNear := false
+	for _, candidate := range []int{index - 1, index} {
+		if candidate < 0 || candidate >= len(shotChanges) {
+			continue
+		}
+
+		frames := int(math.Round((shotChanges[candidate] - milliseconds) / frameDuration))
+		if frames == 0 || frames == allowedFramesBefore {
+			return
+		}
+		if math.Abs(float64(frames)) <= float64(r.ShotChangeFrames) && (!isNear || math.Abs(float64(frames)) < math.Abs(float64(nearest))) {
+			nearest, isNear = frames, true
+		}
+	}
+
+	if isNear {
+		//Actual is positive before the shot change and negative after it
+		direction := "before"
+		if nearest < 0 {
+			direction = "after"
+		}
+		report.add(number, 0, RuleShotChange, float64(nearest), float64(r.ShotChangeFrames),
+			fmt.Sprintf("%s is %d frames %s a shot change", name, int(math.Abs(float64(nearest))), direction))
+	}
+}
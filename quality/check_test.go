@@ -0,0 +1,184 @@
+package quality
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+type expectedIssue struct {
+	actual float64
+	line   int
+	number int
+	rule   Rule
+}
+
+func newSubtitle(paragraphs ...*common.Paragraph) *common.Subtitle {
+	subtitle := &common.Subtitle{}
+	for _, paragraph := range paragraphs {
+		subtitle.Paragraphs = append(subtitle.Paragraphs, *paragraph)
+	}
+
+	return subtitle
+}
+
+func TestCheck(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		rules       Rules
+		subtitle    *common.Subtitle
+		shotChanges []float64
+		expected    []expectedIssue
+	}{
+		{
+			name:     "max characters per line without tags",
+			rules:    Rules{MaxCharactersPerLine: 10},
+			subtitle: newSubtitle(common.NewParagraph("<i>0123456789</i>\n<b>0123456789</b>0", 0, 2000)),
+			expected: []expectedIssue{{actual: 11, line: 2, number: 1, rule: RuleMaxCharactersPerLine}},
+		},
+		{
+			name:     "max lines",
+			rules:    Rules{MaxLines: 2},
+			subtitle: newSubtitle(common.NewParagraph("One\nTwo", 0, 2000), common.NewParagraph("One\r\nTwo\r\nThree", 3000, 5000)),
+			expected: []expectedIssue{{actual: 3, number: 2, rule: RuleMaxLines}},
+		},
+		{
+			name:  "min and max duration",
+			rules: Rules{MaxDuration: 7000, MinDuration: 833},
+			subtitle: newSubtitle(
+				common.NewParagraph("Short", 0, 832),
+				common.NewParagraph("Shortest allowed", 1000, 1833),
+				common.NewParagraph("Longest allowed", 2000, 9000),
+				common.NewParagraph("Long", 10000, 17001),
+			),
+			expected: []expectedIssue{{actual: 832, number: 1, rule: RuleMinDuration}, {actual: 7001, number: 4, rule: RuleMaxDuration}},
+		},
+		{
+			//Spaces count, tags and line breaks do not
+			name:  "characters per second",
+			rules: Rules{MaxCharactersPerSecond: 10},
+			subtitle: newSubtitle(
+				common.NewParagraph("<b>12345</b>\n12345", 0, 1000),
+				common.NewParagraph("1234 67890\n12345", 2000, 3000),
+			),
+			expected: []expectedIssue{{actual: 15, number: 2, rule: RuleMaxCharactersPerSecond}},
+		},
+		{
+			name:     "overlap",
+			rules:    Rules{},
+			subtitle: newSubtitle(common.NewParagraph("One", 0, 2000), common.NewParagraph("Two", 1500, 3000), common.NewParagraph("Three", 3000, 4000)),
+			expected: []expectedIssue{{actual: -500, number: 1, rule: RuleOverlap}},
+		},
+		{
+			//Two frames of 40 ms, a gap of more than one and a half frames is rounded up to two
+			name:  "min gap at 25 fps",
+			rules: Rules{FrameRate: 25, MinGapFrames: 2},
+			subtitle: newSubtitle(
+				common.NewParagraph("One", 0, 1000),
+				common.NewParagraph("Two", 1060, 2000),
+				common.NewParagraph("Three", 2059, 3000),
+				common.NewParagraph("Four", 3080, 4000),
+			),
+			expected: []expectedIssue{{actual: 1, number: 2, rule: RuleMinGap}},
+		},
+		{
+			//Two frames of 41.708 ms, the half frame threshold is at 62.563 ms
+			name:  "min gap at 23.976 fps",
+			rules: Rules{FrameRate: 23.976, MinGapFrames: 2},
+			subtitle: newSubtitle(
+				common.NewParagraph("One", 0, 1000),
+				common.NewParagraph("Two", 1063, 2000),
+				common.NewParagraph("Three", 2062, 3000),
+				common.NewParagraph("Four", 3000, 4000),
+			),
+			expected: []expectedIssue{{actual: 1, number: 2, rule: RuleMinGap}, {actual: 0, number: 3, rule: RuleMinGap}},
+		},
+		{
+			//Starts belong on the shot change, ends on it or the minimum gap of two frames before it
+			name:  "shot changes",
+			rules: Rules{FrameRate: 25, MinGapFrames: 2, ShotChangeFrames: 12},
+			subtitle: newSubtitle(
+				common.NewParagraph("On the shot change and the allowed gap before it", 1000, 4920),
+				common.NewParagraph("Three frames after, one frame before", 11120, 14960),
+				common.NewParagraph("Three frames before, on the shot change", 20880, 25000),
+				common.NewParagraph("Three frames after the shot change", 31000, 35120),
+				common.NewParagraph("Far from shot changes", 40000, 42000),
+			),
+			shotChanges: []float64{35000, 25000, 21000, 15000, 11000, 5000, 1000, 31000},
+			expected: []expectedIssue{
+				{actual: -3, number: 2, rule: RuleShotChange},
+				{actual: 1, number: 2, rule: RuleShotChange},
+				{actual: 3, number: 3, rule: RuleShotChange},
+				{actual: -3, number: 4, rule: RuleShotChange},
+			},
+		},
+		{
+			name:     "forbidden characters",
+			rules:    Rules{ForbiddenCharacters: "#♪"},
+			subtitle: newSubtitle(common.NewParagraph("Fine", 0, 1000), common.NewParagraph("♪ la # la ♪", 2000, 3000)),
+			expected: []expectedIssue{{actual: 2, number: 2, rule: RuleForbiddenCharacters}},
+		},
+	} {
+		report := test.rules.Check(test.subtitle, test.shotChanges)
+
+		if len(report.Issues) != len(test.expected) {
+			t.Errorf("%s: got %d issues %v, expected %d", test.name, len(report.Issues), report.Issues, len(test.expected))
+			continue
+		}
+		for i, issue := range report.Issues {
+			expected := test.expected[i]
+			if issue.Rule != expected.rule || issue.Number != expected.number || issue.Line != expected.line || math.Abs(issue.Actual-expected.actual) > 0.01 {
+				t.Errorf("%s: got %v, expected %+v", test.name, &issue, expected)
+			}
+		}
+
+		if report.IsValid() != (len(test.expected) == 0) {
+			t.Errorf("%s: IsValid is %v with %d issues", test.name, report.IsValid(), len(report.Issues))
+		}
+	}
+}
+
+func TestCheckForbiddenCharactersMessage(t *testing.T) {
+	report := (&Rules{ForbiddenCharacters: "#♪"}).Check(newSubtitle(common.NewParagraph("♪ la # la ♪", 0, 1000)), nil)
+	if len(report.Issues) != 1 || !strings.Contains(report.Issues[0].Message, `"♪#"`) {
+		t.Errorf("got %v, expected each forbidden character once in the order found", report.Issues)
+	}
+}
+
+func TestReportJson(t *testing.T) {
+	rules := &Rules{MaxCharactersPerLine: 5, MaxLines: 1}
+	report := rules.Check(newSubtitle(common.NewParagraph("One\nTwo three", 0, 1000)), nil)
+
+	data, dataErr := report.Json()
+	if dataErr != nil {
+		t.Fatal(dataErr)
+	}
+
+	var decoded struct {
+		Issues []map[string]any `json:"issues"`
+	}
+	if unmarshalErr := json.Unmarshal(data, &decoded); unmarshalErr != nil {
+		t.Fatal(unmarshalErr)
+	}
+	if len(decoded.Issues) != 2 {
+		t.Fatalf("got %d issues in\n%s", len(decoded.Issues), data)
+	}
+
+	//The line is only set for rules checked per line
+	perLine, perParagraph := decoded.Issues[0], decoded.Issues[1]
+	if perLine["rule"] != string(RuleMaxCharactersPerLine) || perLine["line"] != float64(2) || perLine["actual"] != float64(9) || perLine["limit"] != float64(5) || perLine["number"] != float64(1) {
+		t.Errorf("got %v, expected the second line to be too long", perLine)
+	}
+	if _, hasLine := perParagraph["line"]; hasLine || perParagraph["rule"] != string(RuleMaxLines) || perParagraph["message"] == "" {
+		t.Errorf("got %v, expected the max lines issue without a line", perParagraph)
+	}
+
+	//A valid report has an empty list rather than null
+	data, dataErr = rules.Check(newSubtitle(common.NewParagraph("One", 0, 1000)), nil).Json()
+	if dataErr != nil || !strings.Contains(string(data), `"issues": []`) {
+		t.Errorf("got %s (%v), expected an empty list of issues", data, dataErr)
+	}
+}
@@ -0,0 +1,54 @@
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Rule string
+
+const (
+	RuleForbiddenCharacters    Rule = "forbidden-characters"
+	RuleMaxCharactersPerLine   Rule = "max-characters-per-line"
+	RuleMaxCharactersPerSecond Rule = "max-characters-per-second"
+	RuleMaxDuration            Rule = "max-duration"
+	RuleMaxLines               Rule = "max-lines"
+	RuleMinDuration            Rule = "min-duration"
+	RuleMinGap                 Rule = "min-gap"
+	RuleOverlap                Rule = "overlap"
+	RuleShotChange             Rule = "shot-change"
+)
+
+// Issue is a rule a paragraph breaks, with the value that breaks it and the limit of the rule
+type Issue struct {
+	Actual float64 `json:"actual"`
+	Limit  float64 `json:"limit"`
+	//Line is the line of the paragraph, from 1, for rules checked per line
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	Number  int    `json:"number"`
+	Rule    Rule   `json:"rule"`
+}
+
+func (i *Issue) String() string {
+	return fmt.Sprintf("Number: %v , Line: %v , Rule: %v , Actual: %v , Limit: %v , Message: %v", i.Number, i.Line, i.Rule, i.Actual, i.Limit, i.Message)
+}
+
+// Report is the issues of a subtitle, in the order of its paragraphs
+type Report struct {
+	Issues []Issue `json:"issues"`
+}
+
+// IsValid reports whether the subtitle breaks none of the rules
+func (r *Report) IsValid() bool {
+	return len(r.Issues) == 0
+}
+
+// Json returns the report as JSON
+func (r *Report) Json() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+func (r *Report) add(number int, line int, rule Rule, actual float64, limit float64, message string) {
+	r.Issues = append(r.Issues, Issue{Actual: actual, Limit: limit, Line: line, Message: message, Number: number, Rule: rule})
+}
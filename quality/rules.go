@@ -0,0 +1,42 @@
+package quality
+
+import "fmt"
+
+// Rules are the limits subtitles are checked against, rules with a zero limit are not checked
+type Rules struct {
+	//ForbiddenCharacters are characters that may not appear in the text
+	ForbiddenCharacters string
+	//FrameRate converts the frame limits to times, see common.FrameRateOrDefault
+	FrameRate float64
+	//MaxCharactersPerLine counts the characters of each line without formatting tags
+	MaxCharactersPerLine int
+	//MaxCharactersPerSecond is the reading speed, counting the characters of all lines including spaces
+	MaxCharactersPerSecond float64
+	//MaxDuration is in milliseconds
+	MaxDuration float64
+	MaxLines    int
+	//MinDuration is in milliseconds
+	MinDuration float64
+	//MinGapFrames is the number of frames between the end of a paragraph and the start of the next one
+	MinGapFrames int
+	//ShotChangeFrames is how close to a shot change, in frames, a start or end must be snapped to it
+	ShotChangeFrames int
+}
+
+// NewNetflixRules returns the rules of the Netflix timed text style guide for adult programs in English
+func NewNetflixRules(frameRate float64) *Rules {
+	return &Rules{
+		FrameRate:              frameRate,
+		MaxCharactersPerLine:   42,
+		MaxCharactersPerSecond: 20,
+		MaxDuration:            7000,
+		MaxLines:               2,
+		MinDuration:            833,
+		MinGapFrames:           2,
+		ShotChangeFrames:       12,
+	}
+}
+
+func (r *Rules) String() string {
+	return fmt.Sprintf("FrameRate: %v , MaxCharactersPerLine: %v , MaxCharactersPerSecond: %v , MaxDuration: %v , MaxLines: %v , MinDuration: %v , MinGapFrames: %v , ShotChangeFrames: %v , ForbiddenCharacters: %v", r.FrameRate, r.MaxCharactersPerLine, r.MaxCharactersPerSecond, r.MaxDuration, r.MaxLines, r.MinDuration, r.MinGapFrames, r.ShotChangeFrames, r.ForbiddenCharacters)
+}
@@ -0,0 +1,235 @@
+package subtitles
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ristryder/gse/common"
+)
+
+// EbuStl reads and writes EBU STL (EBU Tech 3264) files. As a binary format it is read from the file named in
+// LoadSubtitle or from bytes, and written with ToBytes
+type EbuStl struct {
+	//Header is the GSI block of the file read last, and is written by ToBytes when set
+	Header *EbuStlGsi
+	//SubtractStartOfProgramme makes times relative to the start-of-programme time code, which is often 10:00:00:00
+	SubtractStartOfProgramme bool
+	//TeletextDoubleHeight writes teletext rows in double height, reading a file sets it to what the file uses
+	TeletextDoubleHeight bool
+
+	errors []string
+}
+
+func NewEbuStl() *EbuStl {
+	return &EbuStl{TeletextDoubleHeight: true}
+}
+
+func (e *EbuStl) Errors() string {
+	return strings.Join(e.errors, "\n")
+}
+
+func (e *EbuStl) Extension() string {
+	return ".stl"
+}
+
+func (e *EbuStl) IsMine(lines []string, fileName string) (bool, error) {
+	data, readErr := os.ReadFile(fileName)
+	if readErr != nil || len(data) < ebuStlGsiBlockSize+ebuStlTtiBlockSize || !ebuStlDiskFormatCodeRegex.Match(data[3:11]) {
+		return false, nil
+	}
+
+	subtitle := &common.Subtitle{}
+	loadErr := e.LoadSubtitleFromBytes(subtitle, data)
+	if loadErr != nil {
+		return false, loadErr
+	}
+
+	return len(subtitle.Paragraphs) > 0, nil
+}
+
+func (e *EbuStl) LoadSubtitle(subtitle *common.Subtitle, lines []string, fileName string) error {
+	data, readErr := os.ReadFile(fileName)
+	if readErr != nil {
+		return errors.Wrapf(readErr, "failed to read EBU STL file %s", fileName)
+	}
+
+	return e.LoadSubtitleFromBytes(subtitle, data)
+}
+
+func (e *EbuStl) LoadSubtitleFromBytes(subtitle *common.Subtitle, data []byte) error {
+	e.errors = nil
+
+	header, headerErr := parseEbuStlGsi(data)
+	if headerErr != nil {
+		return errors.Wrap(headerErr, "failed to read EBU STL GSI block")
+	}
+	e.Header = header
+	e.TeletextDoubleHeight = false
+
+	frameRate := header.FrameRate()
+	offset := 0.0
+	if e.SubtractStartOfProgramme {
+		offset = header.StartOfProgramme()
+	}
+
+	rows := header.MaximumNumberOfDisplayableRows
+	if rows <= 0 {
+		rows = 23
+	}
+
+	var first *EbuStlTti
+	textField := []byte{}
+
+	flush := func() {
+		text, isDoubleHeight := decodeEbuStlText(textField, header.CharacterCodeTable, header.IsTeletext())
+		if isDoubleHeight {
+			e.TeletextDoubleHeight = true
+		}
+
+		if text != "" {
+			paragraph := common.NewParagraph(text, first.TimeCodeIn.Milliseconds(frameRate)-offset, first.TimeCodeOut.Milliseconds(frameRate)-offset)
+			paragraph.Language = header.Language()
+			if int(first.VerticalPosition) < rows/2 {
+				paragraph.Region = "top"
+			}
+
+			subtitle.Paragraphs = append(subtitle.Paragraphs, *paragraph)
+		}
+
+		first = nil
+		textField = textField[:0]
+	}
+
+	for position := ebuStlGsiBlockSize; position+ebuStlTtiBlockSize <= len(data); position += ebuStlTtiBlockSize {
+		block, blockErr := parseEbuStlTti(data[position : position+ebuStlTtiBlockSize])
+		if blockErr != nil {
+			return errors.Wrap(blockErr, "failed to read EBU STL TTI block")
+		}
+
+		//User data and comments are not subtitles
+		if block.ExtensionBlockNumber == EbuStlExtensionBlockUserData || block.CommentFlag != 0 {
+			continue
+		}
+
+		if first != nil && first.SubtitleNumber != block.SubtitleNumber {
+			e.errors = append(e.errors, fmt.Sprintf("subtitle %d lacks its last extension block", first.SubtitleNumber))
+			flush()
+		}
+		if first == nil {
+			first = block
+		}
+
+		textField = append(textField, block.TextField...)
+		if block.ExtensionBlockNumber == EbuStlExtensionBlockLast {
+			flush()
+		}
+	}
+
+	if first != nil {
+		e.errors = append(e.errors, fmt.Sprintf("subtitle %d lacks its last extension block", first.SubtitleNumber))
+		flush()
+	}
+
+	subtitle.Renumber(1)
+
+	return nil
+}
+
+func (e *EbuStl) Name() string {
+	return "EBU STL"
+}
+
+// ToBytes returns the subtitle as an EBU STL file, using Header for the GSI block if it is set
+func (e *EbuStl) ToBytes(subtitle *common.Subtitle, title string) ([]byte, error) {
+	header := NewEbuStlGsi()
+	if e.Header != nil {
+		header = new(EbuStlGsi)
+		*header = *e.Header
+	}
+	if title != "" {
+		header.OriginalProgrammeTitle = title
+	}
+	if len(subtitle.Paragraphs) > 0 && subtitle.Paragraphs[0].Language != "" {
+		header.SetLanguage(subtitle.Paragraphs[0].Language)
+	}
+
+	frameRate := header.FrameRate()
+	offset := 0.0
+	if e.SubtractStartOfProgramme {
+		offset = header.StartOfProgramme()
+	}
+
+	rows := header.MaximumNumberOfDisplayableRows
+	if rows <= 0 {
+		rows = 23
+	}
+
+	isTeletext := header.IsTeletext()
+	rowsPerLine := 1
+	if isTeletext && e.TeletextDoubleHeight {
+		rowsPerLine = 2
+	}
+
+	blocks := []byte{}
+	blockCount := 0
+	for i, paragraph := range subtitle.Paragraphs {
+		if i > 0xFFFF {
+			return nil, errors.Newf("EBU STL files hold at most %d subtitles", 0x10000)
+		}
+
+		textField := encodeEbuStlText(paragraph.Text, header.CharacterCodeTable, isTeletext, e.TeletextDoubleHeight)
+		if len(textField) > (EbuStlExtensionBlockUserData-1)*ebuStlTextFieldSize {
+			return nil, errors.Newf("text of subtitle %d does not fit in the EBU STL extension blocks", paragraph.Number)
+		}
+
+		//Subtitles are shown at the bottom, with the last row on the last displayable row
+		verticalPosition := rows + 1 - (strings.Count(paragraph.Text, "\n")+1)*rowsPerLine
+		if !isTeletext {
+			verticalPosition--
+		}
+		if paragraph.Region == "top" {
+			verticalPosition = 1
+			if !isTeletext {
+				verticalPosition = 0
+			}
+		}
+
+		block := EbuStlTti{
+			JustificationCode: EbuStlJustificationCentered,
+			SubtitleNumber:    uint16(i),
+			TimeCodeIn:        newEbuStlTimeCode(paragraph.StartTime.TotalMilliseconds+offset, frameRate),
+			TimeCodeOut:       newEbuStlTimeCode(paragraph.EndTime.TotalMilliseconds+offset, frameRate),
+			VerticalPosition:  byte(max(0, verticalPosition)),
+		}
+
+		if i == 0 {
+			header.TimeCodeFirstInCue = fmt.Sprintf("%02d%02d%02d%02d", block.TimeCodeIn.Hours, block.TimeCodeIn.Minutes, block.TimeCodeIn.Seconds, block.TimeCodeIn.Frames)
+		}
+
+		//Text that does not fit in one block continues in extension blocks numbered from 0
+		for extension := 0; len(textField) > 0 || extension == 0; extension++ {
+			block.ExtensionBlockNumber = byte(extension)
+			if len(textField) <= ebuStlTextFieldSize {
+				block.ExtensionBlockNumber = EbuStlExtensionBlockLast
+			}
+
+			block.TextField = textField[:min(len(textField), ebuStlTextFieldSize)]
+			textField = textField[len(block.TextField):]
+
+			blocks = append(blocks, block.Bytes()...)
+			blockCount++
+		}
+	}
+
+	header.TotalNumberOfSubtitles = len(subtitle.Paragraphs)
+	header.TotalNumberOfTtiBlocks = blockCount
+
+	return append(header.Bytes(), blocks...), nil
+}
+
+// ToText returns an empty string as EBU STL is a binary format, use ToBytes instead
+func (e *EbuStl) ToText(subtitle *common.Subtitle, title string) string {
+	return ""
+}
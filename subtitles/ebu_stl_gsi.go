@@ -0,0 +1,311 @@
+package subtitles
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	EbuStlDisplayStandardUndefined      = " "
+	EbuStlDisplayStandardOpenSubtitling = "0"
+	EbuStlDisplayStandardLevel1Teletext = "1"
+	EbuStlDisplayStandardLevel2Teletext = "2"
+)
+
+const (
+	EbuStlCharacterCodeTableLatin        = "00"
+	EbuStlCharacterCodeTableLatinCyrilic = "01"
+	EbuStlCharacterCodeTableLatinArabic  = "02"
+	EbuStlCharacterCodeTableLatinGreek   = "03"
+	EbuStlCharacterCodeTableLatinHebrew  = "04"
+)
+
+const (
+	ebuStlGsiBlockSize = 1024
+	ebuStlTtiBlockSize = 128
+)
+
+var ebuStlDiskFormatCodeRegex = regexp.MustCompile(`^STL(\d\d)\.01$`)
+
+// ebuStlLanguages maps the language codes of EBU Tech 3264 appendix 3 to BCP 47 language tags
+var ebuStlLanguages = map[string]string{
+	"01": "sq", "02": "br", "03": "ca", "04": "hr", "05": "cy", "06": "cs", "07": "da", "08": "de", "09": "en",
+	"0A": "es", "0B": "eo", "0C": "et", "0D": "eu", "0E": "fo", "0F": "fr", "10": "fy", "11": "ga", "12": "gd",
+	"13": "gl", "14": "is", "15": "it", "16": "se", "17": "la", "18": "lv", "19": "lb", "1A": "lt", "1B": "hu",
+	"1C": "mt", "1D": "nl", "1E": "no", "1F": "oc", "20": "pl", "21": "pt", "22": "ro", "23": "rm", "24": "sr",
+	"25": "sk", "26": "sl", "27": "fi", "28": "sv", "29": "tr", "2A": "nl-BE", "2B": "wa",
+	"45": "zu", "46": "vi", "47": "uz", "48": "ur", "49": "uk", "4A": "th", "4B": "te", "4C": "tt", "4D": "ta",
+	"4E": "tg", "4F": "sw", "50": "srn", "51": "so", "52": "si", "53": "sn", "54": "sh", "55": "rue", "56": "ru",
+	"57": "qu", "58": "ps", "59": "pa", "5A": "fa", "5B": "pap", "5C": "or", "5D": "ne", "5E": "nd", "5F": "mr",
+	"60": "ro-MD", "61": "ms", "62": "mg", "63": "mk", "64": "lo", "65": "ko", "66": "km", "67": "kk", "68": "kn",
+	"69": "ja", "6A": "id", "6B": "hi", "6C": "he", "6D": "ha", "6E": "gn", "6F": "gu", "70": "el", "71": "ka",
+	"72": "ff", "73": "prs", "74": "cv", "75": "zh", "76": "my", "77": "bg", "78": "bn", "79": "be", "7A": "bm",
+	"7B": "az", "7C": "as", "7D": "hy", "7E": "ar", "7F": "am",
+}
+
+// EbuStlGsi is the General Subtitle Information block at the start of an EBU STL file. Numbers that are
+// stored as text are kept as text, except for the counts that are needed to read and write the file
+type EbuStlGsi struct {
+	CharacterCodeTable                               string
+	CodePageNumber                                   string
+	CountryOfOrigin                                  string
+	CreationDate                                     string
+	DiskFormatCode                                   string
+	DiskSequenceNumber                               string
+	DisplayStandardCode                              string
+	EditorsContactDetails                            string
+	EditorsName                                      string
+	LanguageCode                                     string
+	MaximumNumberOfDisplayableCharactersInAnyTextRow int
+	MaximumNumberOfDisplayableRows                   int
+	OriginalEpisodeTitle                             string
+	OriginalProgrammeTitle                           string
+	Publisher                                        string
+	RevisionDate                                     string
+	RevisionNumber                                   string
+	SubtitleListReferenceCode                        string
+	TimeCodeFirstInCue                               string
+	TimeCodeStartOfProgramme                         string
+	TimeCodeStatus                                   string
+	TotalNumberOfDisks                               string
+	TotalNumberOfSubtitleGroups                      int
+	TotalNumberOfSubtitles                           int
+	TotalNumberOfTtiBlocks                           int
+	TranslatedEpisodeTitle                           string
+	TranslatedProgrammeTitle                         string
+	TranslatorsContactDetails                        string
+	TranslatorsName                                  string
+	UserDefinedArea                                  string
+}
+
+// NewEbuStlGsi returns the header written when none is given: 25 fps Level-1 teletext in the Latin character
+// code table
+func NewEbuStlGsi() *EbuStlGsi {
+	today := time.Now().Format("060102")
+
+	return &EbuStlGsi{
+		CharacterCodeTable:  EbuStlCharacterCodeTableLatin,
+		CodePageNumber:      "850",
+		CreationDate:        today,
+		DiskFormatCode:      "STL25.01",
+		DiskSequenceNumber:  "1",
+		DisplayStandardCode: EbuStlDisplayStandardLevel1Teletext,
+		LanguageCode:        "09",
+		MaximumNumberOfDisplayableCharactersInAnyTextRow: 40,
+		MaximumNumberOfDisplayableRows:                   23,
+		RevisionDate:                                     today,
+		RevisionNumber:                                   "01",
+		TimeCodeFirstInCue:                               "00000000",
+		TimeCodeStartOfProgramme:                         "00000000",
+		TimeCodeStatus:                                   "1",
+		TotalNumberOfDisks:                               "1",
+		TotalNumberOfSubtitleGroups:                      1,
+	}
+}
+
+func parseEbuStlGsi(buffer []byte) (*EbuStlGsi, error) {
+	if len(buffer) < ebuStlGsiBlockSize {
+		return nil, errors.Newf("EBU STL GSI block is %d bytes instead of %d", len(buffer), ebuStlGsiBlockSize)
+	}
+
+	codePage := ebuStlCodePage(string(buffer[0:3]))
+	field := func(start int, length int) string {
+		builder := strings.Builder{}
+		for _, b := range buffer[start : start+length] {
+			builder.WriteRune(codePage.DecodeByte(b))
+		}
+
+		return strings.TrimRight(builder.String(), " \x00")
+	}
+	number := func(start int, length int) int {
+		value, _ := strconv.Atoi(strings.TrimSpace(field(start, length)))
+
+		return value
+	}
+
+	gsi := &EbuStlGsi{
+		CharacterCodeTable:    field(12, 2),
+		CodePageNumber:        field(0, 3),
+		CountryOfOrigin:       field(274, 3),
+		CreationDate:          field(224, 6),
+		DiskFormatCode:        field(3, 8),
+		DiskSequenceNumber:    field(273, 1),
+		DisplayStandardCode:   string(buffer[11]),
+		EditorsContactDetails: field(341, 32),
+		EditorsName:           field(309, 32),
+		LanguageCode:          field(14, 2),
+		MaximumNumberOfDisplayableCharactersInAnyTextRow: number(251, 2),
+		MaximumNumberOfDisplayableRows:                   number(253, 2),
+		OriginalEpisodeTitle:                             field(48, 32),
+		OriginalProgrammeTitle:                           field(16, 32),
+		Publisher:                                        field(277, 32),
+		RevisionDate:                                     field(230, 6),
+		RevisionNumber:                                   field(236, 2),
+		SubtitleListReferenceCode:                        field(208, 16),
+		TimeCodeFirstInCue:                               field(264, 8),
+		TimeCodeStartOfProgramme:                         field(256, 8),
+		TimeCodeStatus:                                   field(255, 1),
+		TotalNumberOfDisks:                               field(272, 1),
+		TotalNumberOfSubtitleGroups:                      number(248, 3),
+		TotalNumberOfSubtitles:                           number(243, 5),
+		TotalNumberOfTtiBlocks:                           number(238, 5),
+		TranslatedEpisodeTitle:                           field(112, 32),
+		TranslatedProgrammeTitle:                         field(80, 32),
+		TranslatorsContactDetails:                        field(176, 32),
+		TranslatorsName:                                  field(144, 32),
+		UserDefinedArea:                                  field(448, 576),
+	}
+
+	if !ebuStlDiskFormatCodeRegex.MatchString(gsi.DiskFormatCode) {
+		return nil, errors.Newf("unsupported EBU STL disk format code %q", gsi.DiskFormatCode)
+	}
+
+	return gsi, nil
+}
+
+// Bytes returns the GSI block, text that does not fit in a field is cut off
+func (e *EbuStlGsi) Bytes() []byte {
+	buffer := make([]byte, 0, ebuStlGsiBlockSize)
+
+	codePage := ebuStlCodePage(e.CodePageNumber)
+	field := func(value string, length int) {
+		encoded := make([]byte, 0, length)
+		for _, r := range value {
+			if b, ok := codePage.EncodeRune(r); ok {
+				encoded = append(encoded, b)
+			} else {
+				encoded = append(encoded, '?')
+			}
+		}
+
+		if len(encoded) > length {
+			encoded = encoded[:length]
+		}
+		buffer = append(buffer, encoded...)
+
+		for range length - len(encoded) {
+			buffer = append(buffer, ' ')
+		}
+	}
+	number := func(value int, length int) {
+		field(fmt.Sprintf("%0*d", length, value), length)
+	}
+
+	field(e.CodePageNumber, 3)
+	field(e.DiskFormatCode, 8)
+	field(e.DisplayStandardCode, 1)
+	field(e.CharacterCodeTable, 2)
+	field(e.LanguageCode, 2)
+	field(e.OriginalProgrammeTitle, 32)
+	field(e.OriginalEpisodeTitle, 32)
+	field(e.TranslatedProgrammeTitle, 32)
+	field(e.TranslatedEpisodeTitle, 32)
+	field(e.TranslatorsName, 32)
+	field(e.TranslatorsContactDetails, 32)
+	field(e.SubtitleListReferenceCode, 16)
+	field(e.CreationDate, 6)
+	field(e.RevisionDate, 6)
+	field(e.RevisionNumber, 2)
+	number(e.TotalNumberOfTtiBlocks, 5)
+	number(e.TotalNumberOfSubtitles, 5)
+	number(e.TotalNumberOfSubtitleGroups, 3)
+	number(e.MaximumNumberOfDisplayableCharactersInAnyTextRow, 2)
+	number(e.MaximumNumberOfDisplayableRows, 2)
+	field(e.TimeCodeStatus, 1)
+	field(e.TimeCodeStartOfProgramme, 8)
+	field(e.TimeCodeFirstInCue, 8)
+	field(e.TotalNumberOfDisks, 1)
+	field(e.DiskSequenceNumber, 1)
+	field(e.CountryOfOrigin, 3)
+	field(e.Publisher, 32)
+	field(e.EditorsName, 32)
+	field(e.EditorsContactDetails, 32)
+	field("", 75)
+	field(e.UserDefinedArea, 576)
+
+	return buffer
+}
+
+// FrameRate returns the frame rate of the disk format code. The standard only defines STL25.01 and STL30.01, the
+// other rates are written by some tools with 23 and 29 meaning the NTSC rates
+func (e *EbuStlGsi) FrameRate() float64 {
+	match := ebuStlDiskFormatCodeRegex.FindStringSubmatch(e.DiskFormatCode)
+	if match == nil {
+		return 25
+	}
+
+	switch match[1] {
+	case "23":
+		return 24000.0 / 1001.0
+	case "29":
+		return 30000.0 / 1001.0
+	}
+
+	frameRate, _ := strconv.Atoi(match[1])
+
+	return float64(frameRate)
+}
+
+// IsTeletext reports whether the subtitles are teletext subtitles rather than open subtitles
+func (e *EbuStlGsi) IsTeletext() bool {
+	return e.DisplayStandardCode == EbuStlDisplayStandardLevel1Teletext || e.DisplayStandardCode == EbuStlDisplayStandardLevel2Teletext
+}
+
+// Language returns the BCP 47 tag of the language code, or an empty string if it is unknown
+func (e *EbuStlGsi) Language() string {
+	return ebuStlLanguages[strings.ToUpper(e.LanguageCode)]
+}
+
+// SetLanguage sets the language code from a BCP 47 tag, keeping the current code if the language has none
+func (e *EbuStlGsi) SetLanguage(language string) {
+	language = strings.ToLower(language)
+
+	for code, tag := range ebuStlLanguages {
+		if strings.ToLower(tag) == language {
+			e.LanguageCode = code
+
+			return
+		}
+	}
+
+	//Fall back to the primary language subtag, "en-GB" is written as English
+	if primary, _, hasSubtags := strings.Cut(language, "-"); hasSubtags {
+		e.SetLanguage(primary)
+	}
+}
+
+func (e *EbuStlGsi) String() string {
+	return fmt.Sprintf("CodePageNumber: %v , DiskFormatCode: %v , DisplayStandardCode: %v , CharacterCodeTable: %v , LanguageCode: %v , OriginalProgrammeTitle: %v , TotalNumberOfTtiBlocks: %v , TotalNumberOfSubtitles: %v", e.CodePageNumber, e.DiskFormatCode, e.DisplayStandardCode, e.CharacterCodeTable, e.LanguageCode, e.OriginalProgrammeTitle, e.TotalNumberOfTtiBlocks, e.TotalNumberOfSubtitles)
+}
+
+// StartOfProgramme returns the start-of-programme time code in milliseconds
+func (e *EbuStlGsi) StartOfProgramme() float64 {
+	timeCode, parseErr := parseEbuStlTimeCode(e.TimeCodeStartOfProgramme)
+	if parseErr != nil {
+		return 0
+	}
+
+	return timeCode.Milliseconds(e.FrameRate())
+}
+
+// ebuStlCodePage returns the code page of the GSI block, the multilingual code page 850 if it is unknown
+func ebuStlCodePage(codePageNumber string) *charmap.Charmap {
+	switch codePageNumber {
+	case "437":
+		return charmap.CodePage437
+	case "860":
+		return charmap.CodePage860
+	case "863":
+		return charmap.CodePage863
+	case "865":
+		return charmap.CodePage865
+	}
+
+	return charmap.CodePage850
+}
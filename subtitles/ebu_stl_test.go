@@ -0,0 +1,209 @@
+package subtitles
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+func TestDecodeEbuStlText(t *testing.T) {
+	tests := []struct {
+		name                   string
+		textField              []byte
+		characterCodeTable     string
+		isTeletext             bool
+		expected               string
+		expectedIsDoubleHeight bool
+	}{
+		{
+			name:                   "teletext double height rows with a colour",
+			textField:              []byte("\x0d\x0b\x0bHello\x0a\x0a\x8a\x8a\x0d\x0b\x0b\x01Red\x07text\x0a\x0a"),
+			isTeletext:             true,
+			expected:               "Hello\n<font color=\"red\">Red</font> text",
+			expectedIsDoubleHeight: true,
+		},
+		{
+			name:      "open subtitles with italics and underline",
+			textField: []byte("\x80Italic\x81 and \x82under\x83\x8aNext"),
+			expected:  "<i>Italic</i> and <u>under</u>\nNext",
+		},
+		{
+			name:      "unclosed italics",
+			textField: []byte("\x80Italic"),
+			expected:  "<i>Italic</i>",
+		},
+		{
+			name:      "ISO 6937 diacritics and symbols",
+			textField: []byte("Caf\xc2e \xc8uber \xd5 \xe9"),
+			expected:  "Café über ♪ Ø",
+		},
+		{
+			name:               "ISO 8859-5 table",
+			textField:          []byte{0xBF, 0xE0, 0xD8, 0xD2, 0xD5, 0xE2},
+			characterCodeTable: EbuStlCharacterCodeTableLatinCyrilic,
+			expected:           "Привет",
+		},
+		{
+			name:      "unused space padding",
+			textField: []byte("Text\x8f\x8f\x8f"),
+			expected:  "Text",
+		},
+	}
+
+	for _, test := range tests {
+		text, isDoubleHeight := decodeEbuStlText(test.textField, test.characterCodeTable, test.isTeletext)
+		if text != test.expected || isDoubleHeight != test.expectedIsDoubleHeight {
+			t.Errorf("%s: got %q (double height %v), expected %q (double height %v)", test.name, text, isDoubleHeight, test.expected, test.expectedIsDoubleHeight)
+		}
+	}
+}
+
+func TestEncodeEbuStlText(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		isTeletext     bool
+		isDoubleHeight bool
+		expected       []byte
+	}{
+		{name: "open subtitles", text: "<i>Café</i>\nline", expected: []byte("\x80Caf\xc2e\x81\x8aline")},
+		{name: "teletext", text: "A\nB", isTeletext: true, expected: []byte("\x0b\x0bA\x0a\x0a\x8a\x0b\x0bB\x0a\x0a")},
+		{name: "double height teletext", text: "A\nB", isTeletext: true, isDoubleHeight: true, expected: []byte("\x0d\x0b\x0bA\x0a\x0a\x8a\x8a\x0d\x0b\x0bB\x0a\x0a")},
+		{name: "colour spanning lines", text: `<font color="#00FF00">A` + "\nB</font>", isTeletext: true, expected: []byte("\x0b\x0b\x02A\x0a\x0a\x8a\x0b\x0b\x02B\x07\x0a\x0a")},
+	}
+
+	for _, test := range tests {
+		textField := encodeEbuStlText(test.text, EbuStlCharacterCodeTableLatin, test.isTeletext, test.isDoubleHeight)
+		if !bytes.Equal(textField, test.expected) {
+			t.Errorf("%s: got %q, expected %q", test.name, textField, test.expected)
+		}
+	}
+}
+
+func TestEbuStlColorCode(t *testing.T) {
+	for color, expected := range map[string]byte{"red": 1, "lime": 2, "Aqua": 6, "#FFFF00": 3, "8080ff": 7, "#000080": 4, "bogus": ebuStlAlphaWhite} {
+		if code := ebuStlColorCode(color); code != expected {
+			t.Errorf("%s: got %d, expected %d", color, code, expected)
+		}
+	}
+}
+
+func TestEbuStlTimeCode(t *testing.T) {
+	timeCode := newEbuStlTimeCode(3723480, 25)
+	if timeCode.String() != "01:02:03:12" {
+		t.Errorf("got %s, expected 01:02:03:12", timeCode.String())
+	}
+
+	//The last frames of a second are not rounded up to the next second
+	timeCode = newEbuStlTimeCode(1999, 25)
+	if timeCode.String() != "00:00:01:24" {
+		t.Errorf("got %s, expected 00:00:01:24", timeCode.String())
+	}
+
+	timeCode, timeCodeErr := parseEbuStlTimeCode("10000012")
+	if timeCodeErr != nil || timeCode.Milliseconds(25) != 36000480 {
+		t.Errorf("got %v (%v), expected 10:00:00:12", timeCode, timeCodeErr)
+	}
+
+	if _, timeCodeErr = parseEbuStlTimeCode("1000001"); timeCodeErr == nil {
+		t.Errorf("expected an error for a short time code")
+	}
+}
+
+func TestEbuStlGsi(t *testing.T) {
+	for diskFormatCode, expected := range map[string]float64{"STL25.01": 25, "STL30.01": 30, "STL23.01": 24000.0 / 1001.0, "STL29.01": 30000.0 / 1001.0} {
+		header := &EbuStlGsi{DiskFormatCode: diskFormatCode}
+		if frameRate := header.FrameRate(); frameRate != expected {
+			t.Errorf("%s: got %v, expected %v", diskFormatCode, frameRate, expected)
+		}
+	}
+
+	header := NewEbuStlGsi()
+	header.SetLanguage("fr-CA")
+	if header.LanguageCode != "0F" || header.Language() != "fr" {
+		t.Errorf("got language code %s, expected 0F", header.LanguageCode)
+	}
+	header.OriginalProgrammeTitle = "Title"
+	header.TotalNumberOfTtiBlocks = 12
+
+	parsed, parsedErr := parseEbuStlGsi(header.Bytes())
+	if parsedErr != nil {
+		t.Fatal(parsedErr)
+	}
+	if *parsed != *header {
+		t.Errorf("got %v, expected %v", parsed, header)
+	}
+}
+
+func TestEbuStlRoundTrip(t *testing.T) {
+	longText := strings.Repeat("Long subtitle text ", 8) + "\nsecond line"
+	original := &common.Subtitle{Paragraphs: []common.Paragraph{
+		*common.NewParagraph("<i>First</i>", 1000, 2000),
+		*common.NewParagraph(longText, 3000, 6000),
+		*common.NewParagraph("Top", 7000, 8000),
+	}}
+	original.Paragraphs[0].Language = "de"
+	original.Paragraphs[2].Region = "top"
+
+	for _, displayStandardCode := range []string{EbuStlDisplayStandardLevel1Teletext, EbuStlDisplayStandardOpenSubtitling} {
+		header := NewEbuStlGsi()
+		header.DisplayStandardCode = displayStandardCode
+		header.TimeCodeStartOfProgramme = "10000000"
+
+		writer := &EbuStl{Header: header, SubtractStartOfProgramme: true, TeletextDoubleHeight: true}
+		data, dataErr := writer.ToBytes(original, "Title")
+		if dataErr != nil {
+			t.Fatal(dataErr)
+		}
+
+		reader := &EbuStl{SubtractStartOfProgramme: true}
+		subtitle := &common.Subtitle{}
+		if loadErr := reader.LoadSubtitleFromBytes(subtitle, data); loadErr != nil {
+			t.Fatal(loadErr)
+		}
+
+		checkParagraphs(t, "display standard "+displayStandardCode, subtitle.Paragraphs, []expectedParagraph{
+			{start: 1000, end: 2000, text: "<i>First</i>"},
+			{start: 3000, end: 6000, text: strings.TrimSpace(longText[:strings.Index(longText, "\n")]) + "\nsecond line"},
+			{start: 7000, end: 8000, text: "Top"},
+		})
+
+		if reader.Header.OriginalProgrammeTitle != "Title" || reader.Header.Language() != "de" || reader.Header.TotalNumberOfSubtitles != 3 {
+			t.Errorf("got header %v", reader.Header)
+		}
+		//The long subtitle takes an extension block
+		if reader.Header.TotalNumberOfTtiBlocks != 4 || (len(data)-ebuStlGsiBlockSize)/ebuStlTtiBlockSize != 4 {
+			t.Errorf("got %d TTI blocks, expected 4", reader.Header.TotalNumberOfTtiBlocks)
+		}
+		if len(subtitle.Paragraphs) == 3 && (subtitle.Paragraphs[2].Region != "top" || subtitle.Paragraphs[0].Region != "") {
+			t.Errorf("got regions %q and %q, expected top and bottom", subtitle.Paragraphs[2].Region, subtitle.Paragraphs[0].Region)
+		}
+		if reader.TeletextDoubleHeight != (displayStandardCode == EbuStlDisplayStandardLevel1Teletext) {
+			t.Errorf("display standard %s: got double height %v", displayStandardCode, reader.TeletextDoubleHeight)
+		}
+	}
+}
+
+func TestEbuStlIsMine(t *testing.T) {
+	data, dataErr := NewEbuStl().ToBytes(&common.Subtitle{Paragraphs: []common.Paragraph{*common.NewParagraph("Text", 0, 1000)}}, "")
+	if dataErr != nil {
+		t.Fatal(dataErr)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "test.stl")
+	if writeErr := os.WriteFile(fileName, data, 0o600); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	isMine, isMineErr := NewEbuStl().IsMine(nil, fileName)
+	if isMineErr != nil || !isMine {
+		t.Errorf("got %v (%v), expected the file to be recognized", isMine, isMineErr)
+	}
+
+	subtitle := loadSubtitle(t, NewEbuStl(), "", fileName)
+	checkParagraphs(t, "EBU STL file", subtitle.Paragraphs, []expectedParagraph{{start: 0, end: 1000, text: "Text"}})
+}
@@ -0,0 +1,331 @@
+package subtitles
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Control codes of the text field, teletext colour codes 0x00-0x07 are spacing attributes shown as a space
+const (
+	ebuStlAlphaBlack     = 0x00
+	ebuStlAlphaWhite     = 0x07
+	ebuStlEndBox         = 0x0A
+	ebuStlStartBox       = 0x0B
+	ebuStlNormalHeight   = 0x0C
+	ebuStlDoubleHeight   = 0x0D
+	ebuStlItalicsOn      = 0x80
+	ebuStlItalicsOff     = 0x81
+	ebuStlUnderlineOn    = 0x82
+	ebuStlUnderlineOff   = 0x83
+	ebuStlBoxingOn       = 0x84
+	ebuStlBoxingOff      = 0x85
+	ebuStlCarriageFeed   = 0x8A
+	ebuStlUnusedSpace    = 0x8F
+	ebuStlFirstDiacritic = 0xC1
+	ebuStlLastDiacritic  = 0xCF
+)
+
+// ebuStlColors are the teletext alpha colours in the order of their control codes
+var ebuStlColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ebuStlDiacritics maps the non-spacing diacritical marks of ISO 6937, which precede the letter they apply to, to
+// Unicode combining characters
+var ebuStlDiacritics = map[byte]rune{
+	0xC1: '\u0300', 0xC2: '\u0301', 0xC3: '\u0302', 0xC4: '\u0303', 0xC5: '\u0304', 0xC6: '\u0306', 0xC7: '\u0307',
+	0xC8: '\u0308', 0xCA: '\u030A', 0xCB: '\u0327', 0xCD: '\u030B', 0xCE: '\u0328', 0xCF: '\u030C',
+}
+
+// ebuStlIso6937 holds the spacing characters of the upper half of ISO 6937, the lower half is ASCII
+var ebuStlIso6937 = map[byte]rune{
+	0xA0: '\u00A0', 0xA1: '¡', 0xA2: '¢', 0xA3: '£', 0xA4: '$', 0xA5: '¥', 0xA6: '#', 0xA7: '§', 0xA8: '¤',
+	0xA9: '‘', 0xAA: '“', 0xAB: '«', 0xAC: '←', 0xAD: '↑', 0xAE: '→', 0xAF: '↓',
+	0xB0: '°', 0xB1: '±', 0xB2: '²', 0xB3: '³', 0xB4: '×', 0xB5: 'µ', 0xB6: '¶', 0xB7: '·', 0xB8: '÷',
+	0xB9: '’', 0xBA: '”', 0xBB: '»', 0xBC: '¼', 0xBD: '½', 0xBE: '¾', 0xBF: '¿',
+	0xD0: '―', 0xD1: '¹', 0xD2: '®', 0xD3: '©', 0xD4: '™', 0xD5: '♪', 0xD6: '¬', 0xD7: '¦',
+	0xDC: '⅛', 0xDD: '⅜', 0xDE: '⅝', 0xDF: '⅞',
+	0xE0: 'Ω', 0xE1: 'Æ', 0xE2: 'Đ', 0xE3: 'ª', 0xE4: 'Ħ', 0xE6: 'Ĳ', 0xE7: 'Ŀ', 0xE8: 'Ł', 0xE9: 'Ø',
+	0xEA: 'Œ', 0xEB: 'º', 0xEC: 'Þ', 0xED: 'Ŧ', 0xEE: 'Ŋ', 0xEF: 'ŉ',
+	0xF0: 'ĸ', 0xF1: 'æ', 0xF2: 'đ', 0xF3: 'ð', 0xF4: 'ħ', 0xF5: 'ı', 0xF6: 'ĳ', 0xF7: 'ŀ', 0xF8: 'ł', 0xF9: 'ø',
+	0xFA: 'œ', 0xFB: 'ß', 0xFC: 'þ', 0xFD: 'ŧ', 0xFE: 'ŋ', 0xFF: '\u00AD',
+}
+
+var (
+	ebuStlFontColorRegex = regexp.MustCompile(`(?i)^<font\s[^>]*color\s*=\s*["']?#?([^"' >]+)`)
+	ebuStlTagRegex       = regexp.MustCompile(`(?i)^</?(i|u|font)(\s[^>]*)?>`)
+)
+
+// ebuStlCharacterTable returns the ISO 8859 part of a character code table, or nil for the Latin table that is
+// ISO 6937
+func ebuStlCharacterTable(characterCodeTable string) *charmap.Charmap {
+	switch characterCodeTable {
+	case EbuStlCharacterCodeTableLatinCyrilic:
+		return charmap.ISO8859_5
+	case EbuStlCharacterCodeTableLatinArabic:
+		return charmap.ISO8859_6
+	case EbuStlCharacterCodeTableLatinGreek:
+		return charmap.ISO8859_7
+	case EbuStlCharacterCodeTableLatinHebrew:
+		return charmap.ISO8859_8
+	}
+
+	return nil
+}
+
+// ebuStlColorCode returns the teletext colour closest to an HTML colour name or hex value
+func ebuStlColorCode(color string) byte {
+	color = strings.ToLower(strings.TrimPrefix(color, "#"))
+	switch color {
+	case "lime":
+		color = "green"
+	case "fuchsia":
+		color = "magenta"
+	case "aqua":
+		color = "cyan"
+	}
+
+	for i, name := range ebuStlColors {
+		if name == color {
+			return byte(i)
+		}
+	}
+
+	//Each of the red, green and blue bits of the code is set if the component is bright
+	value, parseErr := strconv.ParseUint(color, 16, 32)
+	if parseErr != nil || len(color) != 6 {
+		return ebuStlAlphaWhite
+	}
+
+	code := byte(0)
+	if value>>16&0xFF >= 0x80 {
+		code |= 1
+	}
+	if value>>8&0xFF >= 0x80 {
+		code |= 2
+	}
+	if value&0xFF >= 0x80 {
+		code |= 4
+	}
+
+	return code
+}
+
+// decodeEbuStlText converts the text field of a subtitle, which may span several TTI blocks, to text with
+// formatting tags
+func decodeEbuStlText(textField []byte, characterCodeTable string, isTeletext bool) (string, bool) {
+	characterTable := ebuStlCharacterTable(characterCodeTable)
+
+	builder := strings.Builder{}
+	isDoubleHeight := false
+	isItalic, isUnderlined := false, false
+	color, isFontOpen := byte(ebuStlAlphaWhite), false
+	hasLineContent, hasPendingSpace := false, false
+
+	closeFont := func() {
+		if isFontOpen {
+			builder.WriteString("</font>")
+			isFontOpen = false
+		}
+	}
+	writePendingSpace := func() {
+		if hasPendingSpace && hasLineContent {
+			builder.WriteString(" ")
+		}
+		hasPendingSpace = false
+	}
+	writeText := func(text string) {
+		//Spaces are collapsed with the spaces taken by control codes
+		if text == " " {
+			hasPendingSpace = true
+
+			return
+		}
+
+		writePendingSpace()
+		if !isFontOpen && color != ebuStlAlphaWhite {
+			builder.WriteString(`<font color="` + ebuStlColors[color] + `">`)
+			isFontOpen = true
+		}
+
+		builder.WriteString(text)
+		hasLineContent = true
+	}
+
+	for i := 0; i < len(textField); i++ {
+		b := textField[i]
+
+		switch {
+		case b <= ebuStlAlphaWhite && isTeletext:
+			closeFont()
+			color = b
+			hasPendingSpace = true
+		case b == ebuStlDoubleHeight:
+			isDoubleHeight = true
+			hasPendingSpace = true
+		case b < 0x20:
+			//Other teletext attributes such as boxing and flashing take a space but have no formatting tag
+			hasPendingSpace = true
+		case b == ebuStlItalicsOn && !isItalic:
+			writePendingSpace()
+			builder.WriteString("<i>")
+			isItalic = true
+		case b == ebuStlItalicsOff && isItalic:
+			builder.WriteString("</i>")
+			isItalic = false
+		case b == ebuStlUnderlineOn && !isUnderlined:
+			writePendingSpace()
+			builder.WriteString("<u>")
+			isUnderlined = true
+		case b == ebuStlUnderlineOff && isUnderlined:
+			builder.WriteString("</u>")
+			isUnderlined = false
+		case b == ebuStlCarriageFeed:
+			//Teletext colours end with the row, and double height rows are separated by two line breaks
+			closeFont()
+			color = ebuStlAlphaWhite
+			if hasLineContent {
+				builder.WriteString("\n")
+			}
+			hasLineContent, hasPendingSpace = false, false
+		case b >= 0x80 && b < 0xA0 || b == 0x7F:
+		case characterTable != nil:
+			writeText(string(characterTable.DecodeByte(b)))
+		case b < 0x80:
+			writeText(string(rune(b)))
+		case b >= ebuStlFirstDiacritic && b <= ebuStlLastDiacritic:
+			diacritic, isDiacritic := ebuStlDiacritics[b]
+			if !isDiacritic || i+1 >= len(textField) || textField[i+1] < 0x20 || textField[i+1] >= 0x80 {
+				continue
+			}
+
+			i++
+			writeText(norm.NFC.String(string([]rune{rune(textField[i]), diacritic})))
+		default:
+			if character, exists := ebuStlIso6937[b]; exists {
+				writeText(string(character))
+			}
+		}
+	}
+
+	closeFont()
+	if isUnderlined {
+		builder.WriteString("</u>")
+	}
+	if isItalic {
+		builder.WriteString("</i>")
+	}
+
+	lines := strings.Split(builder.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), isDoubleHeight
+}
+
+// encodeEbuStlCharacter returns the bytes of a character, nil if the character code table cannot encode it
+func encodeEbuStlCharacter(character rune, characterTable *charmap.Charmap) []byte {
+	if characterTable != nil {
+		if b, ok := characterTable.EncodeRune(character); ok {
+			return []byte{b}
+		}
+
+		return nil
+	}
+
+	if character >= 0x20 && character < 0x7F {
+		return []byte{byte(character)}
+	}
+
+	for b, tableCharacter := range ebuStlIso6937 {
+		if tableCharacter == character {
+			return []byte{b}
+		}
+	}
+
+	//Accented letters are a diacritical mark followed by the letter
+	decomposed := []rune(norm.NFD.String(string(character)))
+	if len(decomposed) == 2 && decomposed[0] >= 0x20 && decomposed[0] < 0x7F {
+		for b, diacritic := range ebuStlDiacritics {
+			if diacritic == decomposed[1] {
+				return []byte{b, byte(decomposed[0])}
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodeEbuStlText converts text with formatting tags to a text field. Teletext rows start with the double
+// height and start box codes and end with the end box codes, as expected by teletext decoders
+func encodeEbuStlText(text string, characterCodeTable string, isTeletext bool, isDoubleHeight bool) []byte {
+	characterTable := ebuStlCharacterTable(characterCodeTable)
+	textField := []byte{}
+
+	color := byte(ebuStlAlphaWhite)
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i > 0 {
+			textField = append(textField, ebuStlCarriageFeed)
+			if isTeletext && isDoubleHeight {
+				textField = append(textField, ebuStlCarriageFeed)
+			}
+		}
+
+		if isTeletext {
+			if isDoubleHeight {
+				textField = append(textField, ebuStlDoubleHeight)
+			}
+			textField = append(textField, ebuStlStartBox, ebuStlStartBox)
+
+			//A colour that spans several lines has to be repeated on each row
+			if color != ebuStlAlphaWhite {
+				textField = append(textField, color)
+			}
+		}
+
+		for len(line) > 0 {
+			if tag := ebuStlTagRegex.FindString(line); tag != "" {
+				line = line[len(tag):]
+
+				lowerTag := strings.ToLower(tag)
+				switch {
+				case lowerTag == "<i>":
+					textField = append(textField, ebuStlItalicsOn)
+				case lowerTag == "</i>":
+					textField = append(textField, ebuStlItalicsOff)
+				case lowerTag == "<u>":
+					textField = append(textField, ebuStlUnderlineOn)
+				case lowerTag == "</u>":
+					textField = append(textField, ebuStlUnderlineOff)
+				case lowerTag == "</font>" && isTeletext:
+					color = ebuStlAlphaWhite
+					textField = append(textField, color)
+				case isTeletext:
+					if match := ebuStlFontColorRegex.FindStringSubmatch(tag); match != nil {
+						color = ebuStlColorCode(match[1])
+						textField = append(textField, color)
+					}
+				}
+
+				continue
+			}
+
+			character, size := utf8.DecodeRuneInString(line)
+			line = line[size:]
+
+			if encoded := encodeEbuStlCharacter(character, characterTable); encoded != nil {
+				textField = append(textField, encoded...)
+			}
+		}
+
+		if isTeletext {
+			textField = append(textField, ebuStlEndBox, ebuStlEndBox)
+		}
+	}
+
+	return textField
+}
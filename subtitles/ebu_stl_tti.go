@@ -0,0 +1,130 @@
+package subtitles
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	//EbuStlExtensionBlockLast marks the last (or only) block of a subtitle, lower numbers are followed by more blocks
+	EbuStlExtensionBlockLast     = 0xFF
+	EbuStlExtensionBlockUserData = 0xFE
+)
+
+const (
+	EbuStlJustificationUnchanged = 0
+	EbuStlJustificationLeft      = 1
+	EbuStlJustificationCentered  = 2
+	EbuStlJustificationRight     = 3
+)
+
+const ebuStlTextFieldSize = 112
+
+// EbuStlTimeCode is a time code of an EBU STL file, the frame rate comes from the disk format code
+type EbuStlTimeCode struct {
+	Frames  int
+	Hours   int
+	Minutes int
+	Seconds int
+}
+
+func newEbuStlTimeCode(milliseconds float64, frameRate float64) EbuStlTimeCode {
+	milliseconds = math.Max(0, milliseconds)
+	seconds := int(milliseconds / 1000)
+
+	//Rounding up to the next second would need a carry, so the last frame of the second is used instead
+	frames := min(int(math.Round((milliseconds-float64(seconds)*1000)*frameRate/1000)), int(math.Ceil(frameRate))-1)
+
+	return EbuStlTimeCode{Frames: frames, Hours: seconds / 3600, Minutes: seconds / 60 % 60, Seconds: seconds % 60}
+}
+
+// parseEbuStlTimeCode parses a time code stored as text, such as 10000000 for 10:00:00:00
+func parseEbuStlTimeCode(text string) (EbuStlTimeCode, error) {
+	if len(text) != 8 {
+		return EbuStlTimeCode{}, errors.Newf("invalid EBU STL time code %q", text)
+	}
+
+	parts := [4]int{}
+	for i := range parts {
+		value, parseErr := strconv.Atoi(text[i*2 : i*2+2])
+		if parseErr != nil {
+			return EbuStlTimeCode{}, errors.Wrapf(parseErr, "invalid EBU STL time code %q", text)
+		}
+
+		parts[i] = value
+	}
+
+	return EbuStlTimeCode{Frames: parts[3], Hours: parts[0], Minutes: parts[1], Seconds: parts[2]}, nil
+}
+
+func (e EbuStlTimeCode) Milliseconds(frameRate float64) float64 {
+	return float64((e.Hours*60+e.Minutes)*60+e.Seconds)*1000 + float64(e.Frames)*1000/frameRate
+}
+
+func (e EbuStlTimeCode) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", e.Hours, e.Minutes, e.Seconds, e.Frames)
+}
+
+// EbuStlTti is a Text and Timing Information block. A subtitle that does not fit in the text field of one block
+// continues in extension blocks with the same subtitle number
+type EbuStlTti struct {
+	CommentFlag          byte
+	CumulativeStatus     byte
+	ExtensionBlockNumber byte
+	JustificationCode    byte
+	SubtitleGroupNumber  byte
+	SubtitleNumber       uint16
+	TextField            []byte
+	TimeCodeIn           EbuStlTimeCode
+	TimeCodeOut          EbuStlTimeCode
+	VerticalPosition     byte
+}
+
+func parseEbuStlTti(buffer []byte) (*EbuStlTti, error) {
+	if len(buffer) < ebuStlTtiBlockSize {
+		return nil, errors.Newf("EBU STL TTI block is %d bytes instead of %d", len(buffer), ebuStlTtiBlockSize)
+	}
+
+	return &EbuStlTti{
+		CommentFlag:          buffer[15],
+		CumulativeStatus:     buffer[4],
+		ExtensionBlockNumber: buffer[3],
+		JustificationCode:    buffer[14],
+		SubtitleGroupNumber:  buffer[0],
+		SubtitleNumber:       binary.LittleEndian.Uint16(buffer[1:3]),
+		TextField:            buffer[16:ebuStlTtiBlockSize],
+		TimeCodeIn:           EbuStlTimeCode{Frames: int(buffer[8]), Hours: int(buffer[5]), Minutes: int(buffer[6]), Seconds: int(buffer[7])},
+		TimeCodeOut:          EbuStlTimeCode{Frames: int(buffer[12]), Hours: int(buffer[9]), Minutes: int(buffer[10]), Seconds: int(buffer[11])},
+		VerticalPosition:     buffer[13],
+	}, nil
+}
+
+// Bytes returns the TTI block, the text field is padded with unused space codes
+func (e *EbuStlTti) Bytes() []byte {
+	buffer := make([]byte, 16, ebuStlTtiBlockSize)
+
+	buffer[0] = e.SubtitleGroupNumber
+	binary.LittleEndian.PutUint16(buffer[1:3], e.SubtitleNumber)
+	buffer[3] = e.ExtensionBlockNumber
+	buffer[4] = e.CumulativeStatus
+	buffer[5], buffer[6], buffer[7], buffer[8] = byte(e.TimeCodeIn.Hours), byte(e.TimeCodeIn.Minutes), byte(e.TimeCodeIn.Seconds), byte(e.TimeCodeIn.Frames)
+	buffer[9], buffer[10], buffer[11], buffer[12] = byte(e.TimeCodeOut.Hours), byte(e.TimeCodeOut.Minutes), byte(e.TimeCodeOut.Seconds), byte(e.TimeCodeOut.Frames)
+	buffer[13] = e.VerticalPosition
+	buffer[14] = e.JustificationCode
+	buffer[15] = e.CommentFlag
+
+	buffer = append(buffer, e.TextField[:min(len(e.TextField), ebuStlTextFieldSize)]...)
+	for len(buffer) < ebuStlTtiBlockSize {
+		buffer = append(buffer, ebuStlUnusedSpace)
+	}
+
+	return buffer
+}
+
+func (e *EbuStlTti) String() string {
+	return fmt.Sprintf("SubtitleNumber: %v , ExtensionBlockNumber: %v , TimeCodeIn: %v , TimeCodeOut: %v , VerticalPosition: %v , JustificationCode: %v , CommentFlag: %v", e.SubtitleNumber, e.ExtensionBlockNumber, e.TimeCodeIn.String(), e.TimeCodeOut.String(), e.VerticalPosition, e.JustificationCode, e.CommentFlag)
+}
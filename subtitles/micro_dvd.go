@@ -0,0 +1,306 @@
+package subtitles
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ristryder/gse/common"
+)
+
+// microDvdDefaultDuration is the duration in seconds of a last paragraph without an end frame
+const microDvdDefaultDuration = 2
+
+var (
+	microDvdColorTagRegex   = regexp.MustCompile(`(?i)^<font\s+color\s*=\s*["']?#?([0-9a-f]{6})["']?\s*>(.*)</font>$`)
+	microDvdControlRegex    = regexp.MustCompile(`\{([a-zA-Z]):([^{}]*)\}`)
+	microDvdLineRegex       = regexp.MustCompile(`^\{(\d+)\}\{(\d*)\}(.*)$`)
+	microDvdStyleCodes      = []string{"i", "b", "u", "s"}
+	microDvdTagRegex        = regexp.MustCompile(`<[^<>]*>`)
+	microDvdWrappedTagRegex = regexp.MustCompile(`(?i)^<([ibus])>(.*)</([ibus])>$`)
+)
+
+// MicroDvd reads and writes MicroDVD subtitles, which give the start and end of each paragraph as frame numbers
+type MicroDvd struct {
+	//FrameRate converts frame numbers to times, a frame rate line at the start of a file replaces it when the
+	//file is loaded. The frame rate of a MatroskaFile can be used as is, see common.FrameRateOrDefault
+	FrameRate float64
+
+	errors []string
+}
+
+// microDvdLine is a line of text with the styles and colour of its control codes
+type microDvdLine struct {
+	color  string
+	styles []string
+	text   string
+}
+
+func NewMicroDvd(frameRate float64) *MicroDvd {
+	return &MicroDvd{FrameRate: frameRate}
+}
+
+func (m *MicroDvd) Errors() string {
+	return strings.Join(m.errors, "\n")
+}
+
+func (m *MicroDvd) Extension() string {
+	return ".sub"
+}
+
+func (m *MicroDvd) IsMine(lines []string, fileName string) (bool, error) {
+	if strings.HasSuffix(strings.ToLower(fileName), ".mpl") {
+		return false, nil
+	}
+
+	matches, nonEmpty := 0, 0
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		nonEmpty++
+		if microDvdLineRegex.MatchString(line) {
+			matches++
+		}
+	}
+
+	return matches > 0 && matches*2 > nonEmpty, nil
+}
+
+func (m *MicroDvd) LoadSubtitle(subtitle *common.Subtitle, lines []string, fileName string) error {
+	m.errors = nil
+
+	type frames struct {
+		end   int
+		start int
+		text  string
+	}
+	paragraphs := []frames{}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\uFEFF"))
+		if line == "" {
+			continue
+		}
+
+		match := microDvdLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			m.errors = append(m.errors, fmt.Sprintf("line %d is not a MicroDVD line: %s", i+1, line))
+
+			continue
+		}
+
+		start, startErr := strconv.Atoi(match[1])
+		end, endErr := strconv.Atoi(match[2])
+		if startErr != nil || (endErr != nil && match[2] != "") {
+			m.errors = append(m.errors, fmt.Sprintf("line %d has an invalid frame number: %s", i+1, line))
+
+			continue
+		}
+		if match[2] == "" {
+			end = -1
+		}
+
+		//A first line on frame 0 or 1 holding a number gives the frame rate
+		if len(paragraphs) == 0 && start <= 1 && end <= 1 {
+			if frameRate, parseErr := strconv.ParseFloat(strings.TrimSpace(match[3]), 64); parseErr == nil && frameRate > 0 {
+				m.FrameRate = frameRate
+
+				continue
+			}
+		}
+
+		paragraphs = append(paragraphs, frames{end: end, start: start, text: match[3]})
+	}
+
+	frameRate := common.FrameRateOrDefault(m.FrameRate)
+	for i, frames := range paragraphs {
+		//Paragraphs without an end frame last until the next one
+		end := float64(frames.end)
+		if frames.end < 0 {
+			end = float64(frames.start) + microDvdDefaultDuration*frameRate
+			if i+1 < len(paragraphs) {
+				end = float64(paragraphs[i+1].start)
+			}
+		}
+
+		paragraph := common.Paragraph{
+			EndTime:   *common.NewTimeCodeFromFrames(end, frameRate),
+			StartTime: *common.NewTimeCodeFromFrames(float64(frames.start), frameRate),
+			Text:      microDvdDecodeText(frames.text),
+		}
+		subtitle.Paragraphs = append(subtitle.Paragraphs, paragraph)
+	}
+
+	subtitle.Renumber(1)
+
+	return nil
+}
+
+func (m *MicroDvd) Name() string {
+	return "MicroDVD"
+}
+
+// ToText writes the paragraphs after a frame rate line. Formatting of whole lines becomes control codes, which
+// apply to the whole paragraph when all lines share them, and other formatting is removed
+func (m *MicroDvd) ToText(subtitle *common.Subtitle, title string) string {
+	frameRate := common.FrameRateOrDefault(m.FrameRate)
+
+	builder := strings.Builder{}
+	builder.WriteString("{1}{1}" + strconv.FormatFloat(frameRate, 'f', -1, 64) + "\n")
+
+	for _, paragraph := range subtitle.Paragraphs {
+		start := paragraph.StartTime.TotalFrames(frameRate)
+		end := max(start, paragraph.EndTime.TotalFrames(frameRate))
+
+		builder.WriteString(fmt.Sprintf("{%d}{%d}%s\n", start, end, microDvdEncodeText(paragraph.Text)))
+	}
+
+	return builder.String()
+}
+
+// microDvdColor converts a $BBGGRR colour to an HTML colour
+func microDvdColor(value string) string {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "$")
+	if len(value) != 6 {
+		return ""
+	}
+	if _, parseErr := strconv.ParseUint(value, 16, 32); parseErr != nil {
+		return ""
+	}
+
+	return "#" + strings.ToLower(value[4:6]+value[2:4]+value[0:2])
+}
+
+// microDvdDecodeText converts the lines of a paragraph, separated by pipes, to text with formatting tags. Control
+// codes in upper case apply to every line, codes in lower case to their own line and a leading slash is italics
+func microDvdDecodeText(text string) string {
+	paragraphLine := microDvdLine{}
+	lines := []microDvdLine{}
+
+	for _, text := range strings.Split(text, "|") {
+		line := microDvdLine{}
+		if strings.HasPrefix(text, "/") {
+			line.styles = append(line.styles, "i")
+			text = text[1:]
+		}
+
+		for _, match := range microDvdControlRegex.FindAllStringSubmatch(text, -1) {
+			target := &line
+			if match[1] == strings.ToUpper(match[1]) {
+				target = &paragraphLine
+			}
+
+			switch strings.ToLower(match[1]) {
+			case "y":
+				for _, style := range strings.Split(strings.ToLower(match[2]), ",") {
+					if style = strings.TrimSpace(style); len(style) == 1 && strings.Contains("ibus", style) {
+						target.styles = append(target.styles, style)
+					}
+				}
+			case "c":
+				target.color = microDvdColor(match[2])
+			}
+		}
+		line.text = strings.TrimSpace(microDvdControlRegex.ReplaceAllString(text, ""))
+
+		lines = append(lines, line)
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		styles := microDvdSortStyles(append(append([]string{}, paragraphLine.styles...), line.styles...))
+		color := paragraphLine.color
+		if line.color != "" {
+			color = line.color
+		}
+
+		text := line.text
+		if color != "" {
+			text = `<font color="` + color + `">` + text + "</font>"
+		}
+		for j := len(styles) - 1; j >= 0; j-- {
+			text = "<" + styles[j] + ">" + text + "</" + styles[j] + ">"
+		}
+		texts[i] = text
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// microDvdEncodeText converts text with formatting tags to lines separated by pipes with control codes
+func microDvdEncodeText(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+
+	lines := []microDvdLine{}
+	for _, text := range strings.Split(text, "\n") {
+		line := microDvdLine{text: strings.TrimSpace(text)}
+
+		//Only tags around a whole line can be kept
+		for {
+			if match := microDvdWrappedTagRegex.FindStringSubmatch(line.text); match != nil &&
+				strings.EqualFold(match[1], match[3]) && !strings.Contains(strings.ToLower(match[2]), "<"+strings.ToLower(match[1])+">") {
+				line.styles = append(line.styles, strings.ToLower(match[1]))
+				line.text = match[2]
+
+				continue
+			}
+			if match := microDvdColorTagRegex.FindStringSubmatch(line.text); match != nil && !strings.Contains(strings.ToLower(match[2]), "<font") {
+				value := strings.ToUpper(match[1])
+				line.color = "$" + value[4:6] + value[2:4] + value[0:2]
+				line.text = match[2]
+
+				continue
+			}
+
+			break
+		}
+		line.text = microDvdTagRegex.ReplaceAllString(line.text, "")
+
+		lines = append(lines, line)
+	}
+
+	//Codes shared by all lines are written once in upper case
+	isShared := len(lines) > 1
+	for _, line := range lines[1:] {
+		isShared = isShared && line.color == lines[0].color &&
+			slices.Equal(microDvdSortStyles(line.styles), microDvdSortStyles(lines[0].styles))
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		codes := ""
+		if styles := microDvdSortStyles(line.styles); len(styles) > 0 && (!isShared || i == 0) {
+			codes += fmt.Sprintf("{%s:%s}", microDvdCodeCase("y", isShared), strings.Join(styles, ","))
+		}
+		if line.color != "" && (!isShared || i == 0) {
+			codes += fmt.Sprintf("{%s:%s}", microDvdCodeCase("c", isShared), line.color)
+		}
+		texts[i] = codes + line.text
+	}
+
+	return strings.Join(texts, "|")
+}
+
+func microDvdCodeCase(code string, isShared bool) string {
+	if isShared {
+		return strings.ToUpper(code)
+	}
+
+	return code
+}
+
+// microDvdSortStyles returns the styles without duplicates, in the order of microDvdStyleCodes
+func microDvdSortStyles(styles []string) []string {
+	sorted := []string{}
+	for _, style := range microDvdStyleCodes {
+		if slices.Contains(styles, style) {
+			sorted = append(sorted, style)
+		}
+	}
+
+	return sorted
+}
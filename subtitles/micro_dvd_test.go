@@ -0,0 +1,114 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+func TestMicroDvdLoadSubtitle(t *testing.T) {
+	tests := []struct {
+		name              string
+		frameRate         float64
+		text              string
+		expected          []expectedParagraph
+		expectedFrameRate float64
+	}{
+		{
+			name:              "frame rate line",
+			frameRate:         25,
+			text:              "{1}{1}23.976\n{24}{48}One second\n{48}{72}Two|lines",
+			expected:          []expectedParagraph{{start: 1001, end: 2002, text: "One second"}, {start: 2002, end: 3003, text: "Two\nlines"}},
+			expectedFrameRate: 23.976,
+		},
+		{
+			name:              "frame rate of the format",
+			frameRate:         25,
+			text:              "{25}{50}Text",
+			expected:          []expectedParagraph{{start: 1000, end: 2000, text: "Text"}},
+			expectedFrameRate: 25,
+		},
+		{
+			//The second paragraph on frame 1 is text, as it does not start the file
+			name:              "missing end frames",
+			frameRate:         25,
+			text:              "{0}{25}First\n{50}{}Until the next\n{75}{}Two seconds",
+			expected:          []expectedParagraph{{start: 0, end: 1000, text: "First"}, {start: 2000, end: 3000, text: "Until the next"}, {start: 3000, end: 5000, text: "Two seconds"}},
+			expectedFrameRate: 25,
+		},
+		{
+			name:      "control codes",
+			frameRate: 25,
+			text:      "{0}{25}{Y:i}Both|lines\n{25}{50}{y:b,u}Bold|plain\n{50}{75}/Slash|{c:$0000FF}Red\n{75}{100}{C:$00FF00}{y:i}Green italic|Green",
+			expected: []expectedParagraph{
+				{start: 0, end: 1000, text: "<i>Both</i>\n<i>lines</i>"},
+				{start: 1000, end: 2000, text: "<b><u>Bold</u></b>\nplain"},
+				{start: 2000, end: 3000, text: "<i>Slash</i>\n<font color=\"#ff0000\">Red</font>"},
+				{start: 3000, end: 4000, text: "<i><font color=\"#00ff00\">Green italic</font></i>\n<font color=\"#00ff00\">Green</font>"},
+			},
+			expectedFrameRate: 25,
+		},
+	}
+
+	for _, test := range tests {
+		format := NewMicroDvd(test.frameRate)
+		subtitle := loadSubtitle(t, format, test.text, "test.sub")
+
+		checkParagraphs(t, test.name, subtitle.Paragraphs, test.expected)
+		if format.FrameRate != test.expectedFrameRate {
+			t.Errorf("%s: got frame rate %v, expected %v", test.name, format.FrameRate, test.expectedFrameRate)
+		}
+	}
+}
+
+func TestMicroDvdLoadSubtitleErrors(t *testing.T) {
+	format := NewMicroDvd(25)
+	subtitle := loadSubtitle(t, format, "{25}{50}Valid\nNot a MicroDVD line\n{99999999999999999999}{1}Too big", "test.sub")
+
+	checkParagraphs(t, "errors", subtitle.Paragraphs, []expectedParagraph{{start: 1000, end: 2000, text: "Valid"}})
+	if errors := format.Errors(); !strings.Contains(errors, "line 2") || !strings.Contains(errors, "line 3") {
+		t.Errorf("got errors %q, expected lines 2 and 3", errors)
+	}
+}
+
+func TestMicroDvdToText(t *testing.T) {
+	subtitle := &common.Subtitle{Paragraphs: []common.Paragraph{
+		*common.NewParagraph("<i>Both</i>\n<i>lines</i>", 1001, 2002),
+		*common.NewParagraph("<b>Bold</b>\n<font color=\"#ff0000\">Red</font>", 2002, 3003),
+		*common.NewParagraph("Partly <i>italic</i>", 3003, 4004),
+	}}
+
+	text := NewMicroDvd(24000.0/1001.0).ToText(subtitle, "")
+	expected := "{1}{1}23.976023976023978\n{24}{48}{Y:i}Both|lines\n{48}{72}{y:b}Bold|{c:$0000FF}Red\n{72}{96}Partly italic\n"
+	if text != expected {
+		t.Errorf("got %q, expected %q", text, expected)
+	}
+
+	//The frame rate line is read back
+	format := NewMicroDvd(25)
+	loaded := loadSubtitle(t, format, strings.TrimSpace(text), "test.sub")
+	checkParagraphs(t, "round trip", loaded.Paragraphs, []expectedParagraph{
+		{start: 1001, end: 2002, text: "<i>Both</i>\n<i>lines</i>"},
+		{start: 2002, end: 3003, text: "<b>Bold</b>\n<font color=\"#ff0000\">Red</font>"},
+		{start: 3003, end: 4004, text: "Partly italic"},
+	})
+}
+
+func TestMicroDvdIsMine(t *testing.T) {
+	tests := []struct {
+		lines    []string
+		fileName string
+		expected bool
+	}{
+		{lines: []string{"{1}{1}23.976", "{24}{48}Text"}, fileName: "test.sub", expected: true},
+		{lines: []string{"{24}{48}Text"}, fileName: "test.mpl", expected: false},
+		{lines: []string{"1", "00:00:01,000 --> 00:00:02,000", "Text"}, fileName: "test.srt", expected: false},
+	}
+
+	for _, test := range tests {
+		if isMine, _ := NewMicroDvd(25).IsMine(test.lines, test.fileName); isMine != test.expected {
+			t.Errorf("%v in %s: got %v, expected %v", test.lines, test.fileName, isMine, test.expected)
+		}
+	}
+}
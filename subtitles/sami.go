@@ -0,0 +1,336 @@
+package subtitles
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ristryder/gse/common"
+)
+
+const (
+	// SamiDefaultClass is the class written for a single language, the class of English closed captions
+	SamiDefaultClass = "ENUSCC"
+	// samiDefaultDuration is the duration in milliseconds of a last paragraph that is never cleared
+	samiDefaultDuration = 3000
+)
+
+var (
+	samiBodyEndRegex   = regexp.MustCompile(`(?i)</body\s*>`)
+	samiClassRegex     = regexp.MustCompile(`(?is)\.([a-z0-9_-]+)\s*\{([^{}]*)\}`)
+	samiParagraphRegex = regexp.MustCompile(`(?i)<p(\s[^>]*)?>`)
+	samiPClassRegex    = regexp.MustCompile(`(?i)\bclass\s*=\s*["']?([a-z0-9_-]+)`)
+	samiPropertyRegex  = regexp.MustCompile(`(?i)([a-z-]+)\s*:\s*([^;]*)`)
+	samiStartRegex     = regexp.MustCompile(`(?i)\bstart\s*=\s*["']?(\d+)`)
+	samiStyleRegex     = regexp.MustCompile(`(?is)<style[^>]*>(.*?)</style\s*>`)
+	samiSyncRegex      = regexp.MustCompile(`(?i)<sync(\s[^>]*)?>`)
+	samiTagRegex       = regexp.MustCompile(`<(/?)([a-zA-Z]+)([^<>]*)>`)
+)
+
+// Sami reads and writes SAMI files, where each language is a class of the STYLE section and paragraphs are shown
+// from their SYNC until the next SYNC of the same class
+type Sami struct {
+	//Language selects the class to load by its name, such as ENUSCC, or by its lang property, such as en-US.
+	//When empty the first class is loaded
+	Language string
+
+	errors []string
+}
+
+// SamiLanguage is a language class of the STYLE section
+type SamiLanguage struct {
+	Class    string
+	Language string
+	Name     string
+}
+
+func (s *SamiLanguage) String() string {
+	return fmt.Sprintf("Class: %v , Language: %v , Name: %v", s.Class, s.Language, s.Name)
+}
+
+// SamiTrack is the subtitle of one language written to a SAMI file with several languages
+type SamiTrack struct {
+	Language SamiLanguage
+	Subtitle *common.Subtitle
+}
+
+// samiCue is the text of a class at a SYNC, empty text clears the class
+type samiCue struct {
+	class string
+	start float64
+	text  string
+}
+
+func NewSami(language string) *Sami {
+	return &Sami{Language: language}
+}
+
+func (s *Sami) Errors() string {
+	return strings.Join(s.errors, "\n")
+}
+
+func (s *Sami) Extension() string {
+	return ".smi"
+}
+
+func (s *Sami) IsMine(lines []string, fileName string) (bool, error) {
+	text := strings.ToLower(strings.Join(lines, "\n"))
+
+	return strings.Contains(text, "<sami") && strings.Contains(text, "<sync"), nil
+}
+
+// Languages returns the language classes of the STYLE section of a SAMI file
+func (s *Sami) Languages(lines []string) []SamiLanguage {
+	languages := []SamiLanguage{}
+
+	style := samiStyleRegex.FindStringSubmatch(strings.Join(lines, "\n"))
+	if style == nil {
+		return languages
+	}
+
+	for _, match := range samiClassRegex.FindAllStringSubmatch(style[1], -1) {
+		language := SamiLanguage{Class: match[1]}
+		for _, property := range samiPropertyRegex.FindAllStringSubmatch(match[2], -1) {
+			switch strings.ToLower(property[1]) {
+			case "lang":
+				language.Language = strings.TrimSpace(property[2])
+			case "name":
+				language.Name = strings.TrimSpace(property[2])
+			}
+		}
+
+		languages = append(languages, language)
+	}
+
+	return languages
+}
+
+func (s *Sami) LoadSubtitle(subtitle *common.Subtitle, lines []string, fileName string) error {
+	s.errors = nil
+
+	//The class to load, paragraphs without a class belong to every class
+	language := SamiLanguage{}
+	languages := s.Languages(lines)
+	if len(languages) > 0 {
+		language = languages[0]
+	}
+	if s.Language != "" {
+		index := slices.IndexFunc(languages, func(l SamiLanguage) bool {
+			return strings.EqualFold(l.Class, s.Language) || strings.EqualFold(l.Language, s.Language)
+		})
+		if index < 0 {
+			return errors.Newf("SAMI language %s is not a class of the STYLE section", s.Language)
+		}
+		language = languages[index]
+	}
+
+	cues := []samiCue{}
+	for _, cue := range parseSamiCues(strings.Join(lines, "\n")) {
+		if cue.class == "" || strings.EqualFold(cue.class, language.Class) {
+			cues = append(cues, cue)
+		}
+	}
+
+	for i, cue := range cues {
+		if cue.text == "" {
+			continue
+		}
+
+		end := cue.start + samiDefaultDuration
+		if i+1 < len(cues) {
+			end = cues[i+1].start
+		}
+
+		paragraph := common.NewParagraph(cue.text, cue.start, end)
+		paragraph.Language = language.Language
+		subtitle.Paragraphs = append(subtitle.Paragraphs, *paragraph)
+	}
+
+	subtitle.Renumber(1)
+
+	return nil
+}
+
+func (s *Sami) Name() string {
+	return "SAMI"
+}
+
+// ToText writes the paragraphs as the class of the Language, or as English closed captions. The lang property is
+// the language of the first paragraph and is left out when it has none
+func (s *Sami) ToText(subtitle *common.Subtitle, title string) string {
+	language := SamiLanguage{Class: SamiDefaultClass, Language: "en-US", Name: "English"}
+	if s.Language != "" {
+		language = SamiLanguage{Class: s.Language, Name: s.Language}
+		if len(subtitle.Paragraphs) > 0 {
+			language.Language = subtitle.Paragraphs[0].Language
+		}
+	}
+
+	return s.ToTextTracks([]SamiTrack{{Language: language, Subtitle: subtitle}}, title)
+}
+
+// ToTextTracks writes the subtitles of several languages to one file, with a class for each language and a SYNC
+// for every time any of them changes
+func (s *Sami) ToTextTracks(tracks []SamiTrack, title string) string {
+	builder := strings.Builder{}
+	builder.WriteString("<SAMI>\n<HEAD>\n<TITLE>" + samiEscape(title) + "</TITLE>\n")
+	builder.WriteString("<SAMIParam>\n  Metrics {time:ms;}\n  Spec {MSFT:1.0;}\n</SAMIParam>\n")
+	builder.WriteString("<STYLE TYPE=\"text/css\">\n<!--\n")
+	builder.WriteString("P { font-family: Arial; font-weight: normal; color: white; background-color: black; text-align: center; }\n")
+	for _, track := range tracks {
+		lang := ""
+		if track.Language.Language != "" {
+			lang = " lang: " + track.Language.Language + ";"
+		}
+		builder.WriteString(fmt.Sprintf(".%s { Name: %s;%s SAMIType: CC; }\n", track.Language.Class, track.Language.Name, lang))
+	}
+	builder.WriteString("-->\n</STYLE>\n</HEAD>\n<BODY>\n")
+
+	//The text of each class at each time, a paragraph is cleared at its end unless the next one starts there
+	times := []int{}
+	texts := map[int]map[string]string{}
+	addCue := func(milliseconds int, class string, text string) {
+		if _, exists := texts[milliseconds]; !exists {
+			texts[milliseconds] = map[string]string{}
+			times = append(times, milliseconds)
+		}
+		texts[milliseconds][class] = text
+	}
+	for _, track := range tracks {
+		for i, paragraph := range track.Subtitle.Paragraphs {
+			start := int(paragraph.StartTime.TotalMilliseconds + 0.5)
+			end := int(paragraph.EndTime.TotalMilliseconds + 0.5)
+
+			addCue(start, track.Language.Class, samiEncodeText(paragraph.Text))
+			if i+1 >= len(track.Subtitle.Paragraphs) || int(track.Subtitle.Paragraphs[i+1].StartTime.TotalMilliseconds+0.5) > end {
+				addCue(end, track.Language.Class, "&nbsp;")
+			}
+		}
+	}
+	slices.Sort(times)
+
+	for _, milliseconds := range times {
+		builder.WriteString(fmt.Sprintf("<SYNC Start=%d>", milliseconds))
+		for _, track := range tracks {
+			if text, exists := texts[milliseconds][track.Language.Class]; exists {
+				builder.WriteString(fmt.Sprintf("<P Class=%s>%s</P>", track.Language.Class, text))
+			}
+		}
+		builder.WriteString("</SYNC>\n")
+	}
+
+	builder.WriteString("</BODY>\n</SAMI>\n")
+
+	return builder.String()
+}
+
+// parseSamiCues returns the text of every class at every SYNC of the body, in the order of the SYNCs
+func parseSamiCues(text string) []samiCue {
+	cues := []samiCue{}
+
+	if end := samiBodyEndRegex.FindStringIndex(text); end != nil {
+		text = text[:end[0]]
+	}
+
+	syncs := samiSyncRegex.FindAllStringSubmatchIndex(text, -1)
+	for i, sync := range syncs {
+		content := text[sync[1]:]
+		if i+1 < len(syncs) {
+			content = text[sync[1]:syncs[i+1][0]]
+		}
+
+		attributes := ""
+		if sync[2] >= 0 {
+			attributes = text[sync[2]:sync[3]]
+		}
+		start := samiStartRegex.FindStringSubmatch(attributes)
+		if start == nil {
+			continue
+		}
+		milliseconds, _ := strconv.ParseFloat(start[1], 64)
+
+		paragraphs := samiParagraphRegex.FindAllStringSubmatchIndex(content, -1)
+		if len(paragraphs) == 0 {
+			cues = append(cues, samiCue{start: milliseconds, text: samiDecodeText(content)})
+
+			continue
+		}
+		for j, paragraph := range paragraphs {
+			paragraphContent := content[paragraph[1]:]
+			if j+1 < len(paragraphs) {
+				paragraphContent = content[paragraph[1]:paragraphs[j+1][0]]
+			}
+
+			class := ""
+			if paragraph[2] >= 0 {
+				if match := samiPClassRegex.FindStringSubmatch(content[paragraph[2]:paragraph[3]]); match != nil {
+					class = match[1]
+				}
+			}
+
+			cues = append(cues, samiCue{class: class, start: milliseconds, text: samiDecodeText(paragraphContent)})
+		}
+	}
+
+	return cues
+}
+
+// samiDecodeText converts the HTML of a paragraph to text, keeping the italic, bold, underline and font tags
+func samiDecodeText(text string) string {
+	text = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+
+	text = samiTagRegex.ReplaceAllStringFunc(text, func(tag string) string {
+		match := samiTagRegex.FindStringSubmatch(tag)
+		switch name := strings.ToLower(match[2]); name {
+		case "br":
+			return "\n"
+		case "i", "b", "u":
+			return "<" + match[1] + name + ">"
+		case "font":
+			return "<" + match[1] + name + match[3] + ">"
+		}
+
+		return ""
+	})
+	text = html.UnescapeString(text)
+
+	//A non-breaking space on its own clears the class
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.ReplaceAll(line, " ", " "))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// samiEncodeText escapes text for HTML, keeping formatting tags, with a line break for each new line
+func samiEncodeText(text string) string {
+	builder := strings.Builder{}
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+
+	for len(text) > 0 {
+		index := samiTagRegex.FindStringIndex(text)
+		if index == nil {
+			builder.WriteString(samiEscape(text))
+
+			break
+		}
+
+		builder.WriteString(samiEscape(text[:index[0]]))
+		builder.WriteString(text[index[0]:index[1]])
+		text = text[index[1]:]
+	}
+
+	return strings.ReplaceAll(builder.String(), "\n", "<br>")
+}
+
+func samiEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
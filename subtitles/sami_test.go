@@ -0,0 +1,170 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+const samiTestFile = `<SAMI>
+<HEAD>
+<STYLE TYPE="text/css">
+<!--
+P { font-family: Arial; }
+.ENUSCC { Name: English; lang: en-US; SAMIType: CC; }
+.FRFRCC { Name: French; lang: fr-FR; SAMIType: CC; }
+-->
+</STYLE>
+</HEAD>
+<BODY>
+<SYNC Start=1000><P Class=ENUSCC>Hello<br><i>world</i> &amp; more</P><P Class=FRFRCC>Bonjour</P>
+<SYNC Start=2500><P Class=ENUSCC>&nbsp;</P>
+<SYNC Start=3000><P Class=ENUSCC>Second</P><P Class=FRFRCC>&nbsp;</P>
+<SYNC Start=4000><P>Both</P>
+</BODY>
+</SAMI>`
+
+func TestSamiLanguages(t *testing.T) {
+	languages := NewSami("").Languages(strings.Split(samiTestFile, "\n"))
+
+	expected := []SamiLanguage{{Class: "ENUSCC", Language: "en-US", Name: "English"}, {Class: "FRFRCC", Language: "fr-FR", Name: "French"}}
+	if len(languages) != len(expected) {
+		t.Fatalf("got %d languages, expected %d", len(languages), len(expected))
+	}
+	for i := range expected {
+		if languages[i] != expected[i] {
+			t.Errorf("language %d: got %s, expected %s", i, languages[i].String(), expected[i].String())
+		}
+	}
+}
+
+func TestSamiLoadSubtitle(t *testing.T) {
+	tests := []struct {
+		language string
+		expected []expectedParagraph
+	}{
+		{
+			language: "",
+			expected: []expectedParagraph{
+				{start: 1000, end: 2500, text: "Hello\n<i>world</i> & more"},
+				{start: 3000, end: 4000, text: "Second"},
+				{start: 4000, end: 7000, text: "Both"},
+			},
+		},
+		{
+			language: "fr-FR",
+			expected: []expectedParagraph{
+				{start: 1000, end: 3000, text: "Bonjour"},
+				{start: 4000, end: 7000, text: "Both"},
+			},
+		},
+		{
+			language: "frfrcc",
+			expected: []expectedParagraph{
+				{start: 1000, end: 3000, text: "Bonjour"},
+				{start: 4000, end: 7000, text: "Both"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		subtitle := loadSubtitle(t, NewSami(test.language), samiTestFile, "test.smi")
+		checkParagraphs(t, "language "+test.language, subtitle.Paragraphs, test.expected)
+	}
+}
+
+func TestSamiLoadSubtitleUnknownLanguage(t *testing.T) {
+	subtitle := &common.Subtitle{}
+	if loadErr := NewSami("de-DE").LoadSubtitle(subtitle, strings.Split(samiTestFile, "\n"), "test.smi"); loadErr == nil {
+		t.Error("expected an error for a language that is not a class")
+	}
+	if len(subtitle.Paragraphs) != 0 {
+		t.Errorf("got %d paragraphs, expected none", len(subtitle.Paragraphs))
+	}
+}
+
+func TestSamiToText(t *testing.T) {
+	subtitle := &common.Subtitle{Paragraphs: []common.Paragraph{
+		*common.NewParagraph("<i>One</i> & a < b\nlines", 1000, 2000),
+		*common.NewParagraph("Back to back", 2000, 3000),
+		*common.NewParagraph("Last", 4000, 5000),
+	}}
+
+	text := NewSami("").ToText(subtitle, "Title")
+	for _, expected := range []string{
+		"<TITLE>Title</TITLE>",
+		".ENUSCC { Name: English; lang: en-US; SAMIType: CC; }",
+		"<SYNC Start=1000><P Class=ENUSCC><i>One</i> &amp; a &lt; b<br>lines</P></SYNC>\n<SYNC Start=2000><P Class=ENUSCC>Back to back</P></SYNC>\n<SYNC Start=3000><P Class=ENUSCC>&nbsp;</P></SYNC>\n",
+		"<SYNC Start=5000><P Class=ENUSCC>&nbsp;</P></SYNC>",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("%q does not contain %q", text, expected)
+		}
+	}
+
+	loaded := loadSubtitle(t, NewSami(""), text, "test.smi")
+	checkParagraphs(t, "round trip", loaded.Paragraphs, []expectedParagraph{
+		{start: 1000, end: 2000, text: "<i>One</i> & a < b\nlines"},
+		{start: 2000, end: 3000, text: "Back to back"},
+		{start: 4000, end: 5000, text: "Last"},
+	})
+}
+
+func TestSamiToTextLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		expected string
+	}{
+		{language: "", expected: ".FRFRCC { Name: FRFRCC; SAMIType: CC; }"},
+		{language: "fr-FR", expected: ".FRFRCC { Name: FRFRCC; lang: fr-FR; SAMIType: CC; }"},
+	}
+
+	for _, test := range tests {
+		paragraph := common.NewParagraph("Bonjour", 1000, 2000)
+		paragraph.Language = test.language
+
+		text := NewSami("FRFRCC").ToText(&common.Subtitle{Paragraphs: []common.Paragraph{*paragraph}}, "")
+		if !strings.Contains(text, test.expected) {
+			t.Errorf("%q does not contain %q", text, test.expected)
+		}
+	}
+}
+
+func TestSamiToTextTracks(t *testing.T) {
+	tracks := []SamiTrack{
+		{
+			Language: SamiLanguage{Class: "ENUSCC", Language: "en-US", Name: "English"},
+			Subtitle: &common.Subtitle{Paragraphs: []common.Paragraph{*common.NewParagraph("Hello", 1000, 2000)}},
+		},
+		{
+			Language: SamiLanguage{Class: "FRFRCC", Language: "fr-FR", Name: "French"},
+			Subtitle: &common.Subtitle{Paragraphs: []common.Paragraph{*common.NewParagraph("Bonjour", 1000, 3000)}},
+		},
+	}
+
+	text := NewSami("").ToTextTracks(tracks, "")
+	expected := "<SYNC Start=1000><P Class=ENUSCC>Hello</P><P Class=FRFRCC>Bonjour</P></SYNC>\n" +
+		"<SYNC Start=2000><P Class=ENUSCC>&nbsp;</P></SYNC>\n" +
+		"<SYNC Start=3000><P Class=FRFRCC>&nbsp;</P></SYNC>\n"
+	if !strings.Contains(text, expected) {
+		t.Errorf("%q does not contain %q", text, expected)
+	}
+
+	for i, track := range tracks {
+		loaded := loadSubtitle(t, NewSami(track.Language.Language), text, "test.smi")
+		if len(loaded.Paragraphs) != 1 || loaded.Paragraphs[0].Text != track.Subtitle.Paragraphs[0].Text ||
+			loaded.Paragraphs[0].Language != track.Language.Language {
+			t.Errorf("track %d: got %v", i, loaded.Paragraphs)
+		}
+	}
+}
+
+func TestSamiIsMine(t *testing.T) {
+	if isMine, _ := NewSami("").IsMine(strings.Split(samiTestFile, "\n"), "test.smi"); !isMine {
+		t.Error("expected a SAMI file to be recognised")
+	}
+	if isMine, _ := NewSami("").IsMine([]string{"<html>", "<body>Text</body>", "</html>"}, "test.html"); isMine {
+		t.Error("expected an HTML file not to be recognised")
+	}
+}
@@ -0,0 +1,206 @@
+package subtitles
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ristryder/gse/cea608"
+	"github.com/ristryder/gse/common"
+)
+
+const (
+	sccFrameRate      = 30000.0 / 1001.0
+	sccHeader         = "Scenarist_SCC V1.0"
+	sccFramesPer10Min = 17982
+	sccFramesPerMin   = 1798
+)
+
+var (
+	sccLineRegex = regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2})([:;.,])(\d{2})\s+(.*)$`)
+	sccWordRegex = regexp.MustCompile(`^[0-9a-fA-F]{4}$`)
+)
+
+// Scc reads and writes Scenarist Closed Caption files, which hold the CEA-608 byte pairs of data channel 1 with
+// 29.97 fps time codes. Captions are written as pop-on captions
+type Scc struct {
+	errors []string
+}
+
+func (s *Scc) Errors() string {
+	return strings.Join(s.errors, "\n")
+}
+
+func (s *Scc) Extension() string {
+	return ".scc"
+}
+
+func (s *Scc) IsMine(lines []string, fileName string) (bool, error) {
+	for _, line := range lines {
+		if line = strings.TrimSpace(strings.TrimPrefix(line, "\uFEFF")); line == "" {
+			continue
+		} else if !strings.HasPrefix(line, sccHeader) {
+			return false, nil
+		}
+
+		break
+	}
+
+	subtitle := &common.Subtitle{}
+	loadErr := s.LoadSubtitle(subtitle, lines, fileName)
+	if loadErr != nil {
+		return false, loadErr
+	}
+
+	return len(subtitle.Paragraphs) > 0, nil
+}
+
+func (s *Scc) LoadSubtitle(subtitle *common.Subtitle, lines []string, fileName string) error {
+	s.errors = nil
+
+	decoder := cea608.NewDecoder(1)
+	frame := 0
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, sccHeader) || strings.HasPrefix(line, "\uFEFF"+sccHeader) {
+			continue
+		}
+
+		match := sccLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			s.errors = append(s.errors, fmt.Sprintf("line %d is not a time code followed by byte pairs: %s", i+1, line))
+
+			continue
+		}
+
+		hours, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		seconds, _ := strconv.Atoi(match[3])
+		frames, _ := strconv.Atoi(match[5])
+		frame = sccFrameNumber(hours, minutes, seconds, frames, match[4] != ":")
+
+		//Each byte pair takes one frame
+		for _, word := range strings.Fields(match[6]) {
+			if !sccWordRegex.MatchString(word) {
+				s.errors = append(s.errors, fmt.Sprintf("line %d has an invalid byte pair: %s", i+1, word))
+
+				continue
+			}
+
+			value, _ := strconv.ParseUint(word, 16, 16)
+			decoder.Decode(byte(value>>8), byte(value), sccMilliseconds(frame))
+			frame++
+		}
+	}
+
+	for _, caption := range decoder.Flush(sccMilliseconds(frame)) {
+		paragraph := common.NewParagraph(caption.Text, caption.Start, caption.End)
+		if caption.Row <= cea608.Rows/2 {
+			paragraph.Region = "top"
+		}
+
+		subtitle.Paragraphs = append(subtitle.Paragraphs, *paragraph)
+	}
+
+	subtitle.Renumber(1)
+
+	return nil
+}
+
+func (s *Scc) Name() string {
+	return "Scenarist Closed Captions"
+}
+
+// ToText writes the paragraphs as pop-on captions. Loading a caption takes a frame per byte pair, so it starts
+// before the paragraph for the end of caption code to show it on time
+func (s *Scc) ToText(subtitle *common.Subtitle, title string) string {
+	builder := strings.Builder{}
+	builder.WriteString(sccHeader + "\n\n")
+
+	nextFrame := 0
+	writeCodes := func(frame int, pairs [][2]byte) {
+		frame = max(frame, nextFrame)
+
+		words := make([]string, len(pairs))
+		for i, pair := range pairs {
+			words[i] = fmt.Sprintf("%02x%02x", pair[0], pair[1])
+		}
+
+		builder.WriteString(sccTimeCode(frame) + "\t" + strings.Join(words, " ") + "\n\n")
+		nextFrame = frame + len(pairs)
+	}
+
+	erasePairs := cea608.EncodeEraseDisplayedMemory(1)
+	for i, paragraph := range subtitle.Paragraphs {
+		pairs := sccPopOn(paragraph)
+		writeCodes(sccLoadFrame(paragraph, pairs), pairs)
+
+		//The caption stays on screen until the next one replaces it unless it can be erased before the next one
+		//is loaded
+		endFrame := sccFrames(paragraph.EndTime.TotalMilliseconds)
+		if i+1 < len(subtitle.Paragraphs) {
+			nextLoadFrame := sccLoadFrame(subtitle.Paragraphs[i+1], sccPopOn(subtitle.Paragraphs[i+1]))
+			if endFrame >= nextLoadFrame {
+				continue
+			}
+
+			endFrame = min(endFrame, nextLoadFrame-len(erasePairs))
+		}
+		if endFrame >= nextFrame {
+			writeCodes(endFrame, erasePairs)
+		}
+	}
+
+	return builder.String()
+}
+
+// sccFrameNumber converts a time code to a frame number, drop frame time codes skip frames 0 and 1 of each minute
+// except every tenth minute
+func sccFrameNumber(hours int, minutes int, seconds int, frames int, isDropFrame bool) int {
+	frameNumber := ((hours*60+minutes)*60+seconds)*30 + frames
+	if isDropFrame {
+		totalMinutes := hours*60 + minutes
+		frameNumber -= 2 * (totalMinutes - totalMinutes/10)
+	}
+
+	return frameNumber
+}
+
+func sccFrames(milliseconds float64) int {
+	return int(math.Round(math.Max(0, milliseconds) * sccFrameRate / 1000))
+}
+
+// sccLoadFrame returns the frame to start loading a pop-on caption at. Control codes are sent twice and act on
+// the first one, so the first of the two end of caption codes is on the frame of the paragraph start
+func sccLoadFrame(paragraph common.Paragraph, pairs [][2]byte) int {
+	return sccFrames(paragraph.StartTime.TotalMilliseconds) - len(pairs) + 2
+}
+
+func sccMilliseconds(frameNumber int) float64 {
+	return float64(frameNumber) * 1000 / sccFrameRate
+}
+
+// sccPopOn returns the byte pairs of a paragraph as a pop-on caption, at the top or with its last line on the
+// bottom row
+func sccPopOn(paragraph common.Paragraph) [][2]byte {
+	row := cea608.Rows - strings.Count(paragraph.Text, "\n")
+	if paragraph.Region == "top" {
+		row = 1
+	}
+
+	return cea608.EncodePopOn(paragraph.Text, row, 1)
+}
+
+// sccTimeCode returns the drop frame time code of a frame number
+func sccTimeCode(frameNumber int) string {
+	tenMinutes, remainder := frameNumber/sccFramesPer10Min, frameNumber%sccFramesPer10Min
+	frameNumber += 18 * tenMinutes
+	if remainder > 1 {
+		frameNumber += 2 * ((remainder - 2) / sccFramesPerMin)
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d;%02d", frameNumber/108000, frameNumber/1800%60, frameNumber/30%60, frameNumber%30)
+}
@@ -0,0 +1,133 @@
+package subtitles
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+func TestSccTimeCodes(t *testing.T) {
+	tests := []struct {
+		frameNumber int
+		timeCode    string
+	}{
+		{frameNumber: 0, timeCode: "00:00:00;00"},
+		{frameNumber: 1799, timeCode: "00:00:59;29"},
+		//Frames 0 and 1 of each minute are dropped, except every tenth minute
+		{frameNumber: 1800, timeCode: "00:01:00;02"},
+		{frameNumber: 17981, timeCode: "00:09:59;29"},
+		{frameNumber: 17982, timeCode: "00:10:00;00"},
+		{frameNumber: 17982 + 1800, timeCode: "00:11:00;02"},
+		{frameNumber: 107892, timeCode: "01:00:00;00"},
+	}
+
+	for _, test := range tests {
+		if timeCode := sccTimeCode(test.frameNumber); timeCode != test.timeCode {
+			t.Errorf("frame %d: got %s, expected %s", test.frameNumber, timeCode, test.timeCode)
+		}
+
+		var hours, minutes, seconds, frames int
+		_, _ = fmt.Sscanf(test.timeCode, "%d:%d:%d;%d", &hours, &minutes, &seconds, &frames)
+		if frameNumber := sccFrameNumber(hours, minutes, seconds, frames, true); frameNumber != test.frameNumber {
+			t.Errorf("%s: got frame %d, expected %d", test.timeCode, frameNumber, test.frameNumber)
+		}
+	}
+
+	for frameNumber := 0; frameNumber < 2*sccFramesPer10Min; frameNumber += 7 {
+		var hours, minutes, seconds, frames int
+		_, _ = fmt.Sscanf(sccTimeCode(frameNumber), "%d:%d:%d;%d", &hours, &minutes, &seconds, &frames)
+		if roundTrip := sccFrameNumber(hours, minutes, seconds, frames, true); roundTrip != frameNumber {
+			t.Fatalf("frame %d: time code %s reads back as frame %d", frameNumber, sccTimeCode(frameNumber), roundTrip)
+		}
+	}
+
+	if frameNumber := sccFrameNumber(0, 1, 0, 2, false); frameNumber != 1802 {
+		t.Errorf("non drop frame: got frame %d, expected 1802", frameNumber)
+	}
+}
+
+func TestSccRoundTrip(t *testing.T) {
+	//Times read back within a frame of what was written
+	tests := []struct {
+		name       string
+		paragraphs []common.Paragraph
+		expected   []expectedParagraph
+	}{
+		{
+			name:       "back to back",
+			paragraphs: []common.Paragraph{*common.NewParagraph("First", 1000, 3000), *common.NewParagraph("Second", 3000, 5000)},
+			expected:   []expectedParagraph{{start: 1000, end: 3000, text: "First"}, {start: 3000, end: 5000, text: "Second"}},
+		},
+		{
+			name:       "with a gap",
+			paragraphs: []common.Paragraph{*common.NewParagraph("First", 1000, 2000), *common.NewParagraph("Second", 4000, 5000)},
+			expected:   []expectedParagraph{{start: 1000, end: 2000, text: "First"}, {start: 4000, end: 5000, text: "Second"}},
+		},
+		{
+			//Loading the long second caption starts before the first one ends, which stays until it is replaced
+			name:       "next caption loaded before the end",
+			paragraphs: []common.Paragraph{*common.NewParagraph("First", 1000, 2000), *common.NewParagraph("<i>A much longer caption</i>\n<u>on two lines</u>", 2500, 4000)},
+			expected:   []expectedParagraph{{start: 1000, end: 2500, text: "First"}, {start: 2500, end: 4000, text: "<i>A much longer caption</i>\n<u>on two lines</u>"}},
+		},
+		{
+			name:       "formatting",
+			paragraphs: []common.Paragraph{*common.NewParagraph(`<font color="yellow">Yellow</font> and plain`, 10000, 12000)},
+			expected:   []expectedParagraph{{start: 10000, end: 12000, text: `<font color="yellow">Yellow</font> and plain`}},
+		},
+	}
+
+	for _, test := range tests {
+		format := &Scc{}
+		text := format.ToText(&common.Subtitle{Paragraphs: test.paragraphs}, "")
+
+		subtitle := loadSubtitle(t, format, text, "test.scc")
+		if len(subtitle.Paragraphs) != len(test.expected) {
+			t.Errorf("%s: got %v, expected %d paragraphs", test.name, subtitle.Paragraphs, len(test.expected))
+
+			continue
+		}
+
+		for i, paragraph := range subtitle.Paragraphs {
+			expected := test.expected[i]
+			if paragraph.Text != expected.text || !isWithinFrame(paragraph.StartTime.TotalMilliseconds, expected.start) || !isWithinFrame(paragraph.EndTime.TotalMilliseconds, expected.end) {
+				t.Errorf("%s: paragraph %d is %q from %v to %v, expected %q from %v to %v", test.name, i, paragraph.Text, paragraph.StartTime.TotalMilliseconds, paragraph.EndTime.TotalMilliseconds, expected.text, expected.start, expected.end)
+			}
+		}
+	}
+}
+
+func TestSccLoadSubtitle(t *testing.T) {
+	text := strings.Join([]string{
+		sccHeader,
+		"",
+		"00:00:01:00\t9420 9420 94ae 94ae 9452 9452 97a2 97a2 c8e9 942f 942f",
+		"",
+		"00:00:03:00\t942c 942c",
+		"",
+		"00:00:04:00\t9420 zzzz",
+	}, "\n")
+
+	format := &Scc{}
+	isMine, isMineErr := format.IsMine(strings.Split(text, "\n"), "test.scc")
+	if isMineErr != nil || !isMine {
+		t.Errorf("got %v (%v), expected the file to be recognized", isMine, isMineErr)
+	}
+
+	subtitle := loadSubtitle(t, format, text, "test.scc")
+	//Non drop frame time codes count 30 frames a second at 29.97 fps, the caption shows on the first end of caption
+	//code at frame 39
+	checkParagraphs(t, "SCC", subtitle.Paragraphs, []expectedParagraph{{start: 39 * 1001.0 / 30, end: 90 * 1001.0 / 30, text: "Hi"}})
+	if !strings.Contains(format.Errors(), "zzzz") {
+		t.Errorf("got errors %q, expected the invalid byte pair", format.Errors())
+	}
+
+	if isMine, _ = format.IsMine([]string{"1", "00:00:01,000 --> 00:00:02,000", "Text"}, "test.srt"); isMine {
+		t.Errorf("SubRip is recognized as SCC")
+	}
+}
+
+func isWithinFrame(milliseconds float64, expected float64) bool {
+	return milliseconds > expected-1000/sccFrameRate && milliseconds < expected+1000/sccFrameRate
+}
@@ -0,0 +1,44 @@
+package subtitles
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+	"github.com/ristryder/gse/interfaces"
+)
+
+// expectedParagraph is a paragraph as the tests expect it, times are compared to the millisecond
+type expectedParagraph struct {
+	end   float64
+	start float64
+	text  string
+}
+
+func checkParagraphs(t *testing.T, name string, paragraphs []common.Paragraph, expected []expectedParagraph) {
+	t.Helper()
+
+	if len(paragraphs) != len(expected) {
+		t.Errorf("%s: got %d paragraphs %v, expected %d", name, len(paragraphs), paragraphs, len(expected))
+
+		return
+	}
+
+	for i, paragraph := range paragraphs {
+		if paragraph.Text != expected[i].text || math.Abs(paragraph.StartTime.TotalMilliseconds-expected[i].start) >= 1 || math.Abs(paragraph.EndTime.TotalMilliseconds-expected[i].end) >= 1 {
+			t.Errorf("%s: paragraph %d is %q from %v to %v, expected %q from %v to %v", name, i, paragraph.Text, paragraph.StartTime.TotalMilliseconds, paragraph.EndTime.TotalMilliseconds, expected[i].text, expected[i].start, expected[i].end)
+		}
+	}
+}
+
+func loadSubtitle(t *testing.T, format interfaces.SubtitleFormat, text string, fileName string) *common.Subtitle {
+	t.Helper()
+
+	subtitle := &common.Subtitle{}
+	if loadErr := format.LoadSubtitle(subtitle, strings.Split(text, "\n"), fileName); loadErr != nil {
+		t.Fatalf("failed to load %s: %v", fileName, loadErr)
+	}
+
+	return subtitle
+}
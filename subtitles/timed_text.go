@@ -0,0 +1,500 @@
+package subtitles
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ristryder/gse/common"
+)
+
+type TimedTextProfile int
+
+const (
+	TimedTextProfileTtml TimedTextProfile = iota
+	TimedTextProfileImsc1Text
+	TimedTextProfileNetflixDfxp
+)
+
+const (
+	imsc1TextProfile   = "http://www.w3.org/ns/ttml/profile/imsc1/text"
+	netflixDfxpProfile = "http://www.netflix.com/ns/ttml/profile/dfxp-ls-sdh"
+	netflixTickRate    = 10000000
+)
+
+var timedTextWhitespaceRegex = regexp.MustCompile(`[ \t\r\n]+`)
+
+// TimedText reads and writes the TTML family of formats: TTML1, DFXP and the IMSC1 Text and Netflix DFXP
+// profiles. All profiles read any TTML document, the profile decides how documents are written and detected
+type TimedText struct {
+	//FrameRate is written in the header of Netflix DFXP documents, where it defaults to 23.976
+	FrameRate float64
+	Profile   TimedTextProfile
+
+	errors []string
+}
+
+func NewTimedText(profile TimedTextProfile) *TimedText {
+	return &TimedText{Profile: profile}
+}
+
+func (t *TimedText) Errors() string {
+	return strings.Join(t.errors, "\n")
+}
+
+func (t *TimedText) Extension() string {
+	switch t.Profile {
+	case TimedTextProfileImsc1Text:
+		return ".ttml"
+	case TimedTextProfileNetflixDfxp:
+		return ".dfxp"
+	}
+
+	return ".xml"
+}
+
+func (t *TimedText) IsMine(lines []string, fileName string) (bool, error) {
+	text := strings.Join(lines, "\n")
+	if !strings.Contains(text, "<tt") || !strings.Contains(text, "ttml") && !strings.Contains(text, "ttaf1") {
+		return false, nil
+	}
+
+	root, rootErr := parseTimedTextDocument(text)
+	if rootErr != nil {
+		return false, nil
+	}
+
+	profile, _ := root.attribute("ttp:profile")
+	switch t.Profile {
+	case TimedTextProfileImsc1Text:
+		if !strings.Contains(profile, "imsc1") {
+			return false, nil
+		}
+	case TimedTextProfileNetflixDfxp:
+		if !strings.Contains(profile, "netflix") && !strings.HasSuffix(strings.ToLower(fileName), ".dfxp") {
+			return false, nil
+		}
+	}
+
+	subtitle := &common.Subtitle{}
+	loadErr := t.LoadSubtitle(subtitle, lines, fileName)
+	if loadErr != nil {
+		return false, loadErr
+	}
+
+	return len(subtitle.Paragraphs) > 0, nil
+}
+
+func (t *TimedText) LoadSubtitle(subtitle *common.Subtitle, lines []string, fileName string) error {
+	t.errors = nil
+
+	text := strings.Join(lines, "\n")
+
+	root, rootErr := parseTimedTextDocument(text)
+	if rootErr != nil {
+		return errors.Wrapf(rootErr, "failed to load TTML subtitle %s", fileName)
+	}
+
+	body := root.child("body")
+	if body == nil {
+		return errors.Newf("TTML subtitle %s has no body", fileName)
+	}
+
+	loader := &timedTextLoader{errors: &t.errors, styles: newTimedTextStyles(root), subtitle: subtitle, timing: newTimedTextTiming(root)}
+	loader.loadTimeContainer(body, 0, -1)
+
+	//The whole document is kept so that its styles and regions can be written back
+	subtitle.Header = text
+	subtitle.SortByStartTime()
+	subtitle.Renumber(1)
+
+	return nil
+}
+
+func (t *TimedText) Name() string {
+	switch t.Profile {
+	case TimedTextProfileImsc1Text:
+		return "IMSC1 Text"
+	case TimedTextProfileNetflixDfxp:
+		return "Netflix DFXP"
+	}
+
+	return "Timed Text 1.0"
+}
+
+func (t *TimedText) ToText(subtitle *common.Subtitle, title string) string {
+	language := "en"
+	if len(subtitle.Paragraphs) > 0 && subtitle.Paragraphs[0].Language != "" {
+		language = subtitle.Paragraphs[0].Language
+	}
+
+	head, headNamespaces, isOriginalHead := timedTextOriginalHead(subtitle.Header)
+	if !isOriginalHead {
+		head = timedTextDefaultHead(title)
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	builder.WriteString(`<tt xmlns="` + timedTextNamespace + `" xmlns:ttp="` + timedTextParameterNamespace + `" xmlns:tts="` + timedTextStylingNamespace + `" xmlns:ttm="` + timedTextMetadataNamespace + `"`)
+
+	//The original head may use prefixes declared on the original root, such as itts or ebutts
+	declaredPrefixes := []string{"ttm", "ttp", "tts", "xml"}
+	if t.Profile == TimedTextProfileImsc1Text {
+		declaredPrefixes = append(declaredPrefixes, "ittp", "itts")
+	}
+	for _, namespace := range headNamespaces {
+		if !slices.Contains(declaredPrefixes, namespace.Name.Local) {
+			builder.WriteString(` xmlns:` + namespace.Name.Local + `="` + timedTextEscape(namespace.Value) + `"`)
+		}
+	}
+
+	switch t.Profile {
+	case TimedTextProfileImsc1Text:
+		builder.WriteString(` xmlns:ittp="http://www.w3.org/ns/ttml/profile/imsc1#parameter" xmlns:itts="http://www.w3.org/ns/ttml/profile/imsc1#styling" ttp:profile="` + imsc1TextProfile + `"`)
+	case TimedTextProfileNetflixDfxp:
+		frameRate, frameRateMultiplier := t.netflixFrameRate()
+		builder.WriteString(` ttp:profile="` + netflixDfxpProfile + `" ttp:frameRate="` + frameRate + `"`)
+		if frameRateMultiplier != "" {
+			builder.WriteString(` ttp:frameRateMultiplier="` + frameRateMultiplier + `"`)
+		}
+		builder.WriteString(fmt.Sprintf(` ttp:tickRate="%d"`, netflixTickRate))
+	}
+
+	builder.WriteString(` ttp:timeBase="media" xml:lang="` + timedTextEscape(language) + `">` + "\n")
+
+	builder.WriteString(head + "\n")
+
+	if isOriginalHead {
+		builder.WriteString("  <body>\n    <div>\n")
+	} else {
+		builder.WriteString("  <body style=\"s0\">\n    <div>\n")
+	}
+
+	for i, paragraph := range subtitle.Paragraphs {
+		begin, end := timedTextClockTime(paragraph.StartTime.TotalMilliseconds), timedTextClockTime(paragraph.EndTime.TotalMilliseconds)
+		if t.Profile == TimedTextProfileNetflixDfxp {
+			begin, end = timedTextTicks(paragraph.StartTime.TotalMilliseconds, netflixTickRate), timedTextTicks(paragraph.EndTime.TotalMilliseconds, netflixTickRate)
+		}
+
+		//The default head only has the top and bottom regions
+		region := paragraph.Region
+		if !isOriginalHead && region != "top" {
+			region = "bottom"
+		}
+
+		builder.WriteString(fmt.Sprintf(`      <p xml:id="p%d" begin="%s" end="%s"`, i+1, begin, end))
+		if region != "" {
+			builder.WriteString(` region="` + timedTextEscape(region) + `"`)
+		}
+		if paragraph.Style != "" && isOriginalHead {
+			builder.WriteString(` style="` + timedTextEscape(paragraph.Style) + `"`)
+		}
+		builder.WriteString(">" + timedTextContent(paragraph.Text) + "</p>\n")
+	}
+
+	builder.WriteString("    </div>\n  </body>\n</tt>\n")
+
+	return builder.String()
+}
+
+// netflixFrameRate returns the ttp:frameRate and ttp:frameRateMultiplier of the frame rate, NTSC rates such as
+// 23.976 are written as 24 with a 1000/1001 multiplier
+func (t *TimedText) netflixFrameRate() (string, string) {
+	frameRate := t.FrameRate
+	if frameRate <= 0 {
+		frameRate = 24000.0 / 1001.0
+	}
+
+	nominalFrameRate := math.Round(frameRate)
+	if math.Abs(frameRate-nominalFrameRate) < 0.001 {
+		return fmt.Sprintf("%d", int(nominalFrameRate)), ""
+	}
+
+	if math.Abs(frameRate-nominalFrameRate*1000/1001) < 0.01 {
+		return fmt.Sprintf("%d", int(nominalFrameRate)), "1000 1001"
+	}
+
+	return fmt.Sprintf("%d", int(nominalFrameRate)), ""
+}
+
+type timedTextLoader struct {
+	errors   *[]string
+	styles   *timedTextStyles
+	subtitle *common.Subtitle
+	timing   timedTextTiming
+}
+
+// interval returns the begin and end of a timed element, relative to the begin of its parent as in a par
+// time container. An unknown end is -1
+func (t *timedTextLoader) interval(node *timedTextNode, parentBegin float64, parentEnd float64) (float64, float64, bool) {
+	begin, end := parentBegin, parentEnd
+	isValid := true
+
+	if beginExpression, exists := node.attribute("begin"); exists {
+		offset, offsetErr := t.timing.milliseconds(beginExpression)
+		if offsetErr != nil {
+			*t.errors = append(*t.errors, offsetErr.Error())
+			isValid = false
+		}
+
+		begin = parentBegin + offset
+	}
+
+	if endExpression, exists := node.attribute("end"); exists {
+		offset, offsetErr := t.timing.milliseconds(endExpression)
+		if offsetErr != nil {
+			*t.errors = append(*t.errors, offsetErr.Error())
+			isValid = false
+		}
+
+		end = parentBegin + offset
+	} else if durationExpression, exists := node.attribute("dur"); exists {
+		duration, durationErr := t.timing.milliseconds(durationExpression)
+		if durationErr != nil {
+			*t.errors = append(*t.errors, durationErr.Error())
+			isValid = false
+		}
+
+		end = begin + duration
+	}
+
+	return begin, end, isValid
+}
+
+func (t *timedTextLoader) loadParagraph(node *timedTextNode, parentBegin float64, parentEnd float64) {
+	begin, end, isValid := t.interval(node, parentBegin, parentEnd)
+	if !isValid {
+		return
+	}
+
+	//Paragraphs may be timed by their spans only
+	_, hasBegin := node.attribute("begin")
+	_, hasEnd := node.attribute("end")
+	_, hasDuration := node.attribute("dur")
+	if !hasBegin && !hasEnd && !hasDuration {
+		spanBegin, spanEnd := math.MaxFloat64, float64(-1)
+		for _, span := range node.descendants("span") {
+			if _, spanHasBegin := span.attribute("begin"); !spanHasBegin {
+				continue
+			}
+
+			childBegin, childEnd, childIsValid := t.interval(span, begin, end)
+			if childIsValid {
+				spanBegin = min(spanBegin, childBegin)
+				spanEnd = max(spanEnd, childEnd)
+			}
+		}
+
+		if spanBegin != math.MaxFloat64 {
+			begin, end = spanBegin, spanEnd
+		}
+	}
+
+	if end < 0 {
+		*t.errors = append(*t.errors, fmt.Sprintf("paragraph at %s has no end", timedTextClockTime(begin)))
+
+		return
+	}
+
+	openingTags, closingTags := timedTextFormattingTags(t.styles.style(node), map[string]string{})
+	text := openingTags + t.text(node, node.inheritedAttribute("xml:space") == "preserve") + closingTags
+
+	//Whitespace around line breaks is not part of the text
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	text = strings.TrimSpace(strings.Join(lines, "\n"))
+
+	paragraph := common.NewParagraph(text, begin, end)
+	paragraph.Language = node.inheritedAttribute("xml:lang")
+	paragraph.Region = node.inheritedAttribute("region")
+	if styleIds, hasStyle := node.attribute("style"); hasStyle && len(strings.Fields(styleIds)) > 0 {
+		paragraph.Style = strings.Fields(styleIds)[0]
+	}
+
+	t.subtitle.Paragraphs = append(t.subtitle.Paragraphs, *paragraph)
+}
+
+func (t *timedTextLoader) loadTimeContainer(node *timedTextNode, parentBegin float64, parentEnd float64) {
+	begin, end, isValid := t.interval(node, parentBegin, parentEnd)
+	if !isValid {
+		return
+	}
+
+	for _, child := range node.children {
+		switch child.name {
+		case "div":
+			t.loadTimeContainer(child, begin, end)
+		case "p":
+			t.loadParagraph(child, begin, end)
+		}
+	}
+}
+
+// text returns the text of the content of an element, with spans converted to formatting tags
+func (t *timedTextLoader) text(node *timedTextNode, preserveSpace bool) string {
+	builder := strings.Builder{}
+
+	for _, child := range node.children {
+		switch child.name {
+		case "":
+			if preserveSpace {
+				builder.WriteString(child.text)
+			} else {
+				builder.WriteString(timedTextWhitespaceRegex.ReplaceAllString(child.text, " "))
+			}
+		case "br":
+			builder.WriteString("\n")
+		case "span":
+			openingTags, closingTags := timedTextFormattingTags(t.styles.style(child), t.styles.style(node))
+			builder.WriteString(openingTags)
+			builder.WriteString(t.text(child, preserveSpace || child.inheritedAttribute("xml:space") == "preserve"))
+			builder.WriteString(closingTags)
+		}
+	}
+
+	return builder.String()
+}
+
+// timedTextContent converts the text of a paragraph to TTML content, formatting tags become styled spans
+func timedTextContent(text string) string {
+	builder := strings.Builder{}
+	openSpans := []string{}
+
+	for len(text) > 0 {
+		tagStart := strings.IndexByte(text, '<')
+		if tagStart < 0 {
+			builder.WriteString(timedTextEscapeLines(text))
+
+			break
+		}
+
+		builder.WriteString(timedTextEscapeLines(text[:tagStart]))
+		text = text[tagStart:]
+
+		tagEnd := strings.IndexByte(text, '>')
+		if tagEnd < 0 {
+			builder.WriteString(timedTextEscapeLines(text))
+
+			break
+		}
+
+		tag := strings.ToLower(text[1:tagEnd])
+		rawTag := text[1:tagEnd]
+		text = text[tagEnd+1:]
+
+		switch {
+		case tag == "i":
+			builder.WriteString(`<span tts:fontStyle="italic">`)
+			openSpans = append(openSpans, "i")
+		case tag == "b":
+			builder.WriteString(`<span tts:fontWeight="bold">`)
+			openSpans = append(openSpans, "b")
+		case tag == "u":
+			builder.WriteString(`<span tts:textDecoration="underline">`)
+			openSpans = append(openSpans, "u")
+		case strings.HasPrefix(tag, "font "):
+			if color := timedTextFontColor(rawTag); color != "" {
+				builder.WriteString(`<span tts:color="` + timedTextEscape(color) + `">`)
+				openSpans = append(openSpans, "font")
+			}
+		case tag == "/i" || tag == "/b" || tag == "/u" || tag == "/font":
+			//Only close what was opened, unbalanced closing tags are dropped
+			if len(openSpans) > 0 && openSpans[len(openSpans)-1] == tag[1:] {
+				builder.WriteString("</span>")
+				openSpans = openSpans[:len(openSpans)-1]
+			}
+		}
+	}
+
+	for range openSpans {
+		builder.WriteString("</span>")
+	}
+
+	return builder.String()
+}
+
+func timedTextDefaultHead(title string) string {
+	return `  <head>
+    <metadata>
+      <ttm:title>` + timedTextEscape(title) + `</ttm:title>
+    </metadata>
+    <styling>
+      <style xml:id="s0" tts:fontFamily="proportionalSansSerif" tts:fontSize="100%" tts:textAlign="center" tts:color="white"/>
+    </styling>
+    <layout>
+      <region xml:id="bottom" tts:origin="10% 10%" tts:extent="80% 80%" tts:displayAlign="after" tts:textAlign="center"/>
+      <region xml:id="top" tts:origin="10% 10%" tts:extent="80% 80%" tts:displayAlign="before" tts:textAlign="center"/>
+    </layout>
+  </head>`
+}
+
+func timedTextEscape(text string) string {
+	builder := strings.Builder{}
+	xml.EscapeText(&builder, []byte(text))
+
+	return builder.String()
+}
+
+func timedTextEscapeLines(text string) string {
+	return strings.ReplaceAll(timedTextEscape(text), "&#xA;", "<br/>")
+}
+
+var timedTextFontColorRegex = regexp.MustCompile(`(?i)color\s*=\s*["']?([^"' ]+)`)
+
+func timedTextFontColor(tag string) string {
+	match := timedTextFontColorRegex.FindStringSubmatch(tag)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+// timedTextOriginalHead returns the head of a TTML document read earlier, as long as it uses the prefixes that
+// are declared when writing so that its styles and regions can be reused as they are. The namespaces declared on
+// the original root are returned as well, since the head may use any of them
+func timedTextOriginalHead(header string) (string, []xml.Attr, bool) {
+	if !strings.Contains(header, `xmlns="`+timedTextNamespace+`"`) || !strings.Contains(header, `xmlns:tts="`+timedTextStylingNamespace+`"`) {
+		return "", nil, false
+	}
+
+	headStart := strings.Index(header, "<head>")
+	if headStart < 0 {
+		headStart = strings.Index(header, "<head ")
+	}
+	headEnd := strings.Index(header, "</head>")
+	if headStart < 0 || headEnd < headStart {
+		return "", nil, false
+	}
+
+	namespaces := []xml.Attr{}
+	decoder := xml.NewDecoder(strings.NewReader(header))
+	for {
+		token, tokenErr := decoder.RawToken()
+		if tokenErr != nil {
+			return "", nil, false
+		}
+
+		if startElement, isStartElement := token.(xml.StartElement); isStartElement {
+			if startElement.Name.Space != "" || startElement.Name.Local != "tt" {
+				return "", nil, false
+			}
+
+			for _, attribute := range startElement.Attr {
+				if attribute.Name.Space == "xmlns" {
+					namespaces = append(namespaces, attribute)
+				}
+			}
+
+			break
+		}
+	}
+
+	return "  " + header[headStart:headEnd+len("</head>")], namespaces, true
+}
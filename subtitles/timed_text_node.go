@@ -0,0 +1,167 @@
+package subtitles
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	timedTextNamespace          = "http://www.w3.org/ns/ttml"
+	timedTextParameterNamespace = "http://www.w3.org/ns/ttml#parameter"
+	timedTextStylingNamespace   = "http://www.w3.org/ns/ttml#styling"
+	timedTextMetadataNamespace  = "http://www.w3.org/ns/ttml#metadata"
+	xmlNamespace                = "http://www.w3.org/XML/1998/namespace"
+)
+
+// timedTextNamespacePrefixes maps the namespaces of TTML, of the DFXP drafts and of the IMSC extensions to the
+// usual prefixes, the prefixes themselves are included for documents that do not declare them
+var timedTextNamespacePrefixes = map[string]string{
+	timedTextMetadataNamespace:                          "ttm",
+	timedTextParameterNamespace:                         "ttp",
+	timedTextStylingNamespace:                           "tts",
+	xmlNamespace:                                        "xml",
+	"http://www.w3.org/2006/04/ttaf1#metadata":          "ttm",
+	"http://www.w3.org/2006/04/ttaf1#parameter":         "ttp",
+	"http://www.w3.org/2006/04/ttaf1#style":             "tts",
+	"http://www.w3.org/2006/04/ttaf1#styling":           "tts",
+	"http://www.w3.org/2006/10/ttaf1#metadata":          "ttm",
+	"http://www.w3.org/2006/10/ttaf1#parameter":         "ttp",
+	"http://www.w3.org/2006/10/ttaf1#style":             "tts",
+	"http://www.w3.org/2006/10/ttaf1#styling":           "tts",
+	"http://www.w3.org/ns/ttml/profile/imsc1#metadata":  "ittm",
+	"http://www.w3.org/ns/ttml/profile/imsc1#parameter": "ittp",
+	"http://www.w3.org/ns/ttml/profile/imsc1#styling":   "itts",
+	"ittm": "ittm",
+	"ittp": "ittp",
+	"itts": "itts",
+	"ttm":  "ttm",
+	"ttp":  "ttp",
+	"tts":  "tts",
+	"xml":  "xml",
+}
+
+// timedTextNode is an element or, when it has no name, a text node of a TTML document. Attribute names are
+// normalized to the usual prefixes (tts:, ttp:, ttm:, xml: and itts:, ittp:, ittm: for IMSC) whatever prefixes the
+// document declares
+type timedTextNode struct {
+	attributes map[string]string
+	children   []*timedTextNode
+	name       string
+	parent     *timedTextNode
+	text       string
+}
+
+func (t *timedTextNode) attribute(name string) (string, bool) {
+	value, exists := t.attributes[name]
+
+	return strings.TrimSpace(value), exists
+}
+
+// child returns the first child element with the name, or nil
+func (t *timedTextNode) child(name string) *timedTextNode {
+	for _, child := range t.children {
+		if child.name == name {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// descendants returns all elements below the node with the name, in document order
+func (t *timedTextNode) descendants(name string) []*timedTextNode {
+	nodes := []*timedTextNode{}
+	for _, child := range t.children {
+		if child.name == name {
+			nodes = append(nodes, child)
+		}
+
+		nodes = append(nodes, child.descendants(name)...)
+	}
+
+	return nodes
+}
+
+// inheritedAttribute returns the attribute of the node or of its nearest ancestor that has it
+func (t *timedTextNode) inheritedAttribute(name string) string {
+	for node := t; node != nil; node = node.parent {
+		if value, exists := node.attribute(name); exists {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func timedTextAttributeName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	if prefix, isKnown := timedTextNamespacePrefixes[name.Space]; isKnown {
+		return prefix + ":" + name.Local
+	}
+
+	//Other extension namespaces, such as the SMPTE or EBU ones, keep their full name so they do not clash with TTML
+	//attributes
+	return name.Space + ":" + name.Local
+}
+
+func parseTimedTextDocument(text string) (*timedTextNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	decoder.Strict = false
+
+	var root *timedTextNode
+	var current *timedTextNode
+
+	for {
+		token, tokenErr := decoder.Token()
+		if tokenErr == io.EOF {
+			break
+		}
+		if tokenErr != nil {
+			return nil, errors.Wrap(tokenErr, "failed to parse TTML document")
+		}
+
+		switch typedToken := token.(type) {
+		case xml.StartElement:
+			node := &timedTextNode{attributes: map[string]string{}, name: typedToken.Name.Local, parent: current}
+			for _, attribute := range typedToken.Attr {
+				if attribute.Name.Space == "xmlns" || attribute.Name.Local == "xmlns" {
+					continue
+				}
+
+				node.attributes[timedTextAttributeName(attribute.Name)] = attribute.Value
+			}
+
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("TTML document has more than one root element")
+				}
+
+				root = node
+			} else {
+				current.children = append(current.children, node)
+			}
+
+			current = node
+		case xml.EndElement:
+			if current != nil {
+				current = current.parent
+			}
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, &timedTextNode{parent: current, text: string(typedToken)})
+			}
+		}
+	}
+
+	if root == nil || root.name != "tt" {
+		return nil, errors.New("TTML document has no tt root element")
+	}
+
+	return root, nil
+}
@@ -0,0 +1,155 @@
+package subtitles
+
+import (
+	"maps"
+	"strings"
+)
+
+// timedTextStyles resolves the computed style of content elements: styles inherit from the parent element, the
+// region, the referenced styles (which may reference other styles) and finally the inline tts: attributes
+type timedTextStyles struct {
+	computed map[*timedTextNode]map[string]string
+	regions  map[string]*timedTextNode
+	styles   map[string]*timedTextNode
+}
+
+func newTimedTextStyles(root *timedTextNode) *timedTextStyles {
+	styles := &timedTextStyles{computed: map[*timedTextNode]map[string]string{}, regions: map[string]*timedTextNode{}, styles: map[string]*timedTextNode{}}
+
+	if head := root.child("head"); head != nil {
+		for _, style := range head.descendants("style") {
+			if id, exists := style.attribute("xml:id"); exists {
+				styles.styles[id] = style
+			}
+		}
+
+		for _, region := range head.descendants("region") {
+			if id, exists := region.attribute("xml:id"); exists {
+				styles.regions[id] = region
+			}
+		}
+	}
+
+	return styles
+}
+
+// inlineStyle returns the tts: attributes of the node, including those of style elements nested in a region
+func inlineStyle(node *timedTextNode) map[string]string {
+	style := map[string]string{}
+	for name, value := range node.attributes {
+		if strings.HasPrefix(name, "tts:") {
+			style[name] = strings.TrimSpace(value)
+		}
+	}
+
+	return style
+}
+
+// referencedStyle merges the styles referenced by a space separated list of ids, later ids take precedence
+func (t *timedTextStyles) referencedStyle(ids string, visited map[string]bool) map[string]string {
+	style := map[string]string{}
+
+	for _, id := range strings.Fields(ids) {
+		styleNode, exists := t.styles[id]
+		if !exists || visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		if nestedIds, hasNestedIds := styleNode.attribute("style"); hasNestedIds {
+			maps.Copy(style, t.referencedStyle(nestedIds, visited))
+		}
+		maps.Copy(style, inlineStyle(styleNode))
+
+		delete(visited, id)
+	}
+
+	return style
+}
+
+func (t *timedTextStyles) regionStyle(id string) map[string]string {
+	style := map[string]string{}
+
+	region, exists := t.regions[id]
+	if !exists {
+		return style
+	}
+
+	if ids, hasIds := region.attribute("style"); hasIds {
+		maps.Copy(style, t.referencedStyle(ids, map[string]bool{}))
+	}
+	for _, child := range region.children {
+		if child.name == "style" {
+			maps.Copy(style, inlineStyle(child))
+		}
+	}
+	maps.Copy(style, inlineStyle(region))
+
+	return style
+}
+
+func (t *timedTextStyles) style(node *timedTextNode) map[string]string {
+	if node == nil || node.name == "tt" {
+		return map[string]string{}
+	}
+
+	if style, exists := t.computed[node]; exists {
+		return style
+	}
+
+	style := maps.Clone(t.style(node.parent))
+
+	if regionId, hasRegion := node.attribute("region"); hasRegion {
+		maps.Copy(style, t.regionStyle(regionId))
+	}
+	if ids, hasIds := node.attribute("style"); hasIds {
+		maps.Copy(style, t.referencedStyle(ids, map[string]bool{}))
+	}
+	maps.Copy(style, inlineStyle(node))
+
+	t.computed[node] = style
+
+	return style
+}
+
+func isTimedTextDefaultColor(color string) bool {
+	switch strings.ToLower(color) {
+	case "", "white", "#ffffff", "#ffffffff":
+		return true
+	}
+
+	return false
+}
+
+// timedTextFormattingTags returns the formatting tags for what a style adds to the style it inherits from
+func timedTextFormattingTags(style map[string]string, inheritedStyle map[string]string) (string, string) {
+	openingTags, closingTags := "", ""
+
+	isItalic := func(s map[string]string) bool {
+		return s["tts:fontStyle"] == "italic" || s["tts:fontStyle"] == "oblique"
+	}
+	if isItalic(style) && !isItalic(inheritedStyle) {
+		openingTags += "<i>"
+		closingTags = "</i>" + closingTags
+	}
+
+	if style["tts:fontWeight"] == "bold" && inheritedStyle["tts:fontWeight"] != "bold" {
+		openingTags += "<b>"
+		closingTags = "</b>" + closingTags
+	}
+
+	isUnderlined := func(s map[string]string) bool {
+		return strings.Contains(s["tts:textDecoration"], "underline") && !strings.Contains(s["tts:textDecoration"], "noUnderline")
+	}
+	if isUnderlined(style) && !isUnderlined(inheritedStyle) {
+		openingTags += "<u>"
+		closingTags = "</u>" + closingTags
+	}
+
+	if color := style["tts:color"]; color != inheritedStyle["tts:color"] && !(inheritedStyle["tts:color"] == "" && isTimedTextDefaultColor(color)) {
+		openingTags += `<font color="` + color + `">`
+		closingTags = "</font>" + closingTags
+	}
+
+	return openingTags, closingTags
+}
@@ -0,0 +1,234 @@
+package subtitles
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+)
+
+func TestTimedTextAttributeName(t *testing.T) {
+	tests := []struct {
+		name     xml.Name
+		expected string
+	}{
+		{name: xml.Name{Local: "begin"}, expected: "begin"},
+		{name: xml.Name{Space: timedTextStylingNamespace, Local: "color"}, expected: "tts:color"},
+		{name: xml.Name{Space: timedTextParameterNamespace, Local: "frameRate"}, expected: "ttp:frameRate"},
+		{name: xml.Name{Space: xmlNamespace, Local: "id"}, expected: "xml:id"},
+		{name: xml.Name{Space: "http://www.w3.org/2006/10/ttaf1#style", Local: "fontStyle"}, expected: "tts:fontStyle"},
+		{name: xml.Name{Space: "http://www.w3.org/2006/04/ttaf1#styling", Local: "fontStyle"}, expected: "tts:fontStyle"},
+		{name: xml.Name{Space: "http://www.w3.org/ns/ttml/profile/imsc1#styling", Local: "fillLineGap"}, expected: "itts:fillLineGap"},
+		{name: xml.Name{Space: "http://www.w3.org/ns/ttml/profile/imsc1#parameter", Local: "activeArea"}, expected: "ittp:activeArea"},
+		{name: xml.Name{Space: "tts", Local: "color"}, expected: "tts:color"},
+		{name: xml.Name{Space: "urn:ebu:tt:style", Local: "linePadding"}, expected: "urn:ebu:tt:style:linePadding"},
+	}
+
+	for _, test := range tests {
+		if name := timedTextAttributeName(test.name); name != test.expected {
+			t.Errorf("%v: got %q, expected %q", test.name, name, test.expected)
+		}
+	}
+}
+
+func TestTimedTextTimeExpressions(t *testing.T) {
+	ntsc := timedTextTiming{frameRate: 24000.0 / 1001.0, subFrameRate: 1, tickRate: 10000000}
+	tests := []struct {
+		expression string
+		expected   float64
+	}{
+		{expression: "00:00:01.500", expected: 1500},
+		{expression: "00:00:01,500", expected: 1500},
+		{expression: "01:02:03", expected: 3723000},
+		{expression: "00:00:01:12", expected: 1000 + 12*1001.0/24},
+		{expression: "2.5s", expected: 2500},
+		{expression: "250ms", expected: 250},
+		{expression: "1.5m", expected: 90000},
+		{expression: "24f", expected: 1001},
+		{expression: "25000000t", expected: 2500},
+	}
+
+	for _, test := range tests {
+		milliseconds, millisecondsErr := ntsc.milliseconds(test.expression)
+		if millisecondsErr != nil {
+			t.Errorf("%s: %v", test.expression, millisecondsErr)
+
+			continue
+		}
+		if milliseconds < test.expected-0.001 || milliseconds > test.expected+0.001 {
+			t.Errorf("%s: got %v, expected %v", test.expression, milliseconds, test.expected)
+		}
+	}
+
+	if _, millisecondsErr := ntsc.milliseconds("soon"); millisecondsErr == nil {
+		t.Errorf("expected an error for an invalid time expression")
+	}
+}
+
+func TestTimedTextLoadSubtitle(t *testing.T) {
+	document := `<?xml version="1.0" encoding="utf-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" xmlns:s="http://www.w3.org/ns/ttml#styling" xmlns:itts="http://www.w3.org/ns/ttml/profile/imsc1#styling" ttp:frameRate="25" xml:lang="en">
+  <head>
+    <styling>
+      <style xml:id="italic" s:fontStyle="italic"/>
+    </styling>
+    <layout>
+      <region xml:id="top" s:displayAlign="before"/>
+    </layout>
+  </head>
+  <body>
+    <div begin="10s">
+      <p begin="00:00:01:05" end="00:00:03:00" region="top">First   line<br/>  second line</p>
+      <p begin="5s" dur="1s" style="italic">Styled <span s:fontWeight="bold">bold</span></p>
+      <p begin="7s" end="8s"><span itts:fontStyle="italic">not</span> italic</p>
+      <p><span begin="9s" end="9.5s">Timed</span> <span begin="9.2s" end="9.8s">by spans</span></p>
+    </div>
+  </body>
+</tt>`
+
+	subtitle := loadSubtitle(t, NewTimedText(TimedTextProfileTtml), document, "test.xml")
+
+	checkParagraphs(t, "TTML", subtitle.Paragraphs, []expectedParagraph{
+		{start: 11200, end: 13000, text: "First line\nsecond line"},
+		{start: 15000, end: 16000, text: "<i>Styled <b>bold</b></i>"},
+		{start: 17000, end: 18000, text: "not italic"},
+		{start: 19000, end: 19800, text: "Timed by spans"},
+	})
+	if len(subtitle.Paragraphs) > 0 && (subtitle.Paragraphs[0].Region != "top" || subtitle.Paragraphs[0].Language != "en") {
+		t.Errorf("got region %q and language %q, expected top and en", subtitle.Paragraphs[0].Region, subtitle.Paragraphs[0].Language)
+	}
+}
+
+func TestTimedTextLoadDfxpDraftNamespace(t *testing.T) {
+	document := `<tt xmlns="http://www.w3.org/2006/10/ttaf1" xmlns:tts="http://www.w3.org/2006/10/ttaf1#style">
+  <body>
+    <div>
+      <p begin="1s" end="2s" tts:fontStyle="italic">Old draft</p>
+    </div>
+  </body>
+</tt>`
+
+	subtitle := loadSubtitle(t, NewTimedText(TimedTextProfileNetflixDfxp), document, "test.dfxp")
+
+	checkParagraphs(t, "DFXP", subtitle.Paragraphs, []expectedParagraph{{start: 1000, end: 2000, text: "<i>Old draft</i>"}})
+}
+
+func TestTimedTextRoundTrip(t *testing.T) {
+	original := &common.Subtitle{Paragraphs: []common.Paragraph{
+		*common.NewParagraph("<i>Hello</i> & goodbye", 1000, 2500),
+		*common.NewParagraph("Two\n<b>lines</b>", 3000, 4200),
+	}}
+
+	for _, test := range []struct {
+		profile  TimedTextProfile
+		contains string
+		fileName string
+	}{
+		{profile: TimedTextProfileTtml, contains: `begin="00:00:01.000" end="00:00:02.500"`, fileName: "test.xml"},
+		{profile: TimedTextProfileImsc1Text, contains: `ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text"`, fileName: "test.ttml"},
+		{profile: TimedTextProfileNetflixDfxp, contains: `begin="10000000t" end="25000000t"`, fileName: "test.dfxp"},
+	} {
+		format := NewTimedText(test.profile)
+		text := format.ToText(original, "Title")
+		if !strings.Contains(text, test.contains) {
+			t.Errorf("%s: %q is not in\n%s", format.Name(), test.contains, text)
+		}
+
+		isMine, isMineErr := format.IsMine(strings.Split(text, "\n"), test.fileName)
+		if isMineErr != nil || !isMine {
+			t.Errorf("%s: does not recognize its own output", format.Name())
+		}
+
+		subtitle := loadSubtitle(t, format, text, test.fileName)
+		checkParagraphs(t, format.Name(), subtitle.Paragraphs, []expectedParagraph{
+			{start: 1000, end: 2500, text: "<i>Hello</i> & goodbye"},
+			{start: 3000, end: 4200, text: "Two\n<b>lines</b>"},
+		})
+	}
+}
+
+func TestTimedTextNetflixFrameRate(t *testing.T) {
+	for _, test := range []struct {
+		frameRate          float64
+		expectedRate       string
+		expectedMultiplier string
+	}{
+		{frameRate: 0, expectedRate: "24", expectedMultiplier: "1000 1001"},
+		{frameRate: 25, expectedRate: "25", expectedMultiplier: ""},
+		{frameRate: 29.97, expectedRate: "30", expectedMultiplier: "1000 1001"},
+	} {
+		format := &TimedText{FrameRate: test.frameRate, Profile: TimedTextProfileNetflixDfxp}
+		rate, multiplier := format.netflixFrameRate()
+		if rate != test.expectedRate || multiplier != test.expectedMultiplier {
+			t.Errorf("%v: got %q and %q, expected %q and %q", test.frameRate, rate, multiplier, test.expectedRate, test.expectedMultiplier)
+		}
+	}
+}
+
+func TestTimedTextIsMineChecksProfile(t *testing.T) {
+	text := NewTimedText(TimedTextProfileTtml).ToText(&common.Subtitle{Paragraphs: []common.Paragraph{*common.NewParagraph("Text", 0, 1000)}}, "")
+
+	isMine, _ := NewTimedText(TimedTextProfileImsc1Text).IsMine(strings.Split(text, "\n"), "test.xml")
+	if isMine {
+		t.Errorf("IMSC1 Text recognizes a document without the IMSC1 profile")
+	}
+}
+
+func TestTimedTextKeepsNamespacesOfOriginalHead(t *testing.T) {
+	document := `<?xml version="1.0" encoding="utf-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" xmlns:tts="http://www.w3.org/ns/ttml#styling" xmlns:itts="http://www.w3.org/ns/ttml/profile/imsc1#styling" xmlns:ebutts="urn:ebu:tt:style" ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text" xml:lang="en">
+  <head>
+    <styling>
+      <style xml:id="s1" tts:color="yellow" itts:fillLineGap="true" ebutts:linePadding="0.5c"/>
+    </styling>
+  </head>
+  <body>
+    <div>
+      <p begin="00:00:01.000" end="00:00:02.000" style="s1">Hello</p>
+    </div>
+  </body>
+</tt>`
+
+	subtitle := loadSubtitle(t, NewTimedText(TimedTextProfileImsc1Text), document, "test.ttml")
+
+	for _, profile := range []TimedTextProfile{TimedTextProfileTtml, TimedTextProfileImsc1Text} {
+		format := NewTimedText(profile)
+		text := format.ToText(subtitle, "Title")
+		if !strings.Contains(text, `itts:fillLineGap="true"`) {
+			t.Errorf("%s: the original head is not kept in\n%s", format.Name(), text)
+		}
+
+		//An undeclared prefix is left as the namespace of a name
+		spaces := map[string]bool{}
+		decoder := xml.NewDecoder(strings.NewReader(text))
+		for {
+			token, tokenErr := decoder.Token()
+			if tokenErr != nil {
+				break
+			}
+
+			if startElement, isStartElement := token.(xml.StartElement); isStartElement {
+				spaces[startElement.Name.Space] = true
+				for _, attribute := range startElement.Attr {
+					spaces[attribute.Name.Space] = true
+				}
+			}
+		}
+
+		for _, prefix := range []string{"ebutts", "itts", "tts"} {
+			if spaces[prefix] {
+				t.Errorf("%s: prefix %s is not declared in\n%s", format.Name(), prefix, text)
+			}
+		}
+		for _, namespace := range []string{"urn:ebu:tt:style", "http://www.w3.org/ns/ttml/profile/imsc1#styling"} {
+			if !spaces[namespace] {
+				t.Errorf("%s: namespace %s is not used in\n%s", format.Name(), namespace, text)
+			}
+		}
+
+		if strings.Count(text, "xmlns:itts=") != 1 {
+			t.Errorf("%s: itts is not declared exactly once in\n%s", format.Name(), text)
+		}
+	}
+}
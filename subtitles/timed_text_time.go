@@ -0,0 +1,126 @@
+package subtitles
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+var (
+	timedTextClockTimeRegex  = regexp.MustCompile(`^(\d{1,}):(\d{2}):(\d{2})(?:\.(\d+)|:(\d{2,})(?:\.(\d+))?)?$`)
+	timedTextOffsetTimeRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)(h|ms|m|s|f|t)$`)
+)
+
+// timedTextTiming holds the parameters of the tt element needed to convert time expressions
+type timedTextTiming struct {
+	frameRate    float64
+	subFrameRate float64
+	tickRate     float64
+}
+
+func newTimedTextTiming(root *timedTextNode) timedTextTiming {
+	timing := timedTextTiming{frameRate: 30, subFrameRate: 1}
+
+	frameRate, hasFrameRate := root.attribute("ttp:frameRate")
+	if value, parseErr := strconv.ParseFloat(frameRate, 64); hasFrameRate && parseErr == nil && value > 0 {
+		timing.frameRate = value
+	}
+
+	//The multiplier is a ratio such as "1000 1001" for NTSC rates
+	if multiplier, exists := root.attribute("ttp:frameRateMultiplier"); exists {
+		if parts := strings.Fields(multiplier); len(parts) == 2 {
+			numerator, numeratorErr := strconv.ParseFloat(parts[0], 64)
+			denominator, denominatorErr := strconv.ParseFloat(parts[1], 64)
+			if numeratorErr == nil && denominatorErr == nil && numerator > 0 && denominator > 0 {
+				timing.frameRate = timing.frameRate * numerator / denominator
+			}
+		}
+	}
+
+	subFrameRate, _ := root.attribute("ttp:subFrameRate")
+	if value, parseErr := strconv.ParseFloat(subFrameRate, 64); parseErr == nil && value > 0 {
+		timing.subFrameRate = value
+	}
+
+	//Without an explicit tick rate, ticks are sub-frames if a frame rate is given and seconds otherwise
+	timing.tickRate = 1
+	if hasFrameRate {
+		timing.tickRate = timing.frameRate * timing.subFrameRate
+	}
+	tickRate, _ := root.attribute("ttp:tickRate")
+	if value, parseErr := strconv.ParseFloat(tickRate, 64); parseErr == nil && value > 0 {
+		timing.tickRate = value
+	}
+
+	return timing
+}
+
+// milliseconds converts a clock-time (hh:mm:ss.fraction or hh:mm:ss:frames.subframes) or an offset-time
+// (a number followed by h, m, s, ms, f or t) to milliseconds
+func (t timedTextTiming) milliseconds(expression string) (float64, error) {
+	expression = strings.TrimSpace(expression)
+
+	//Seen in the wild: a comma as decimal separator
+	expression = strings.Replace(expression, ",", ".", 1)
+
+	if match := timedTextClockTimeRegex.FindStringSubmatch(expression); match != nil {
+		hours, _ := strconv.ParseFloat(match[1], 64)
+		minutes, _ := strconv.ParseFloat(match[2], 64)
+		seconds, _ := strconv.ParseFloat(match[3], 64)
+		milliseconds := ((hours*60+minutes)*60 + seconds) * 1000
+
+		if match[4] != "" {
+			fraction, _ := strconv.ParseFloat("0."+match[4], 64)
+			milliseconds += fraction * 1000
+		}
+
+		if match[5] != "" {
+			frames, _ := strconv.ParseFloat(match[5], 64)
+			if match[6] != "" {
+				subFrames, _ := strconv.ParseFloat(match[6], 64)
+				frames += subFrames / t.subFrameRate
+			}
+
+			milliseconds += frames / t.frameRate * 1000
+		}
+
+		return milliseconds, nil
+	}
+
+	if match := timedTextOffsetTimeRegex.FindStringSubmatch(expression); match != nil {
+		value, _ := strconv.ParseFloat(match[1], 64)
+
+		switch match[2] {
+		case "h":
+			return value * 3600000, nil
+		case "m":
+			return value * 60000, nil
+		case "s":
+			return value * 1000, nil
+		case "ms":
+			return value, nil
+		case "f":
+			return value / t.frameRate * 1000, nil
+		case "t":
+			return value / t.tickRate * 1000, nil
+		}
+	}
+
+	return 0, errors.Newf("invalid TTML time expression %q", expression)
+}
+
+// timedTextClockTime formats milliseconds as a clock-time with a fraction, such as 00:01:02.345
+func timedTextClockTime(milliseconds float64) string {
+	total := int64(math.Round(math.Max(0, milliseconds)))
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", total/3600000, total/60000%60, total/1000%60, total%1000)
+}
+
+// timedTextTicks formats milliseconds as an offset-time in ticks, such as 20000000t
+func timedTextTicks(milliseconds float64, tickRate float64) string {
+	return strconv.FormatInt(int64(math.Round(math.Max(0, milliseconds)*tickRate/1000)), 10) + "t"
+}
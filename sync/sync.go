@@ -0,0 +1,111 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ristryder/gse/common"
+	"github.com/ristryder/gse/containers/matroska"
+)
+
+// Point is a time in milliseconds of a subtitle and the time it should be at
+type Point struct {
+	New float64
+	Old float64
+}
+
+func (p *Point) String() string {
+	return fmt.Sprintf("Old: %v , New: %v", p.Old, p.New)
+}
+
+// ChangeFrameRate retimes a subtitle made for video at one frame rate to video at another, such as from 25 to
+// 23.976, so that every paragraph stays on the same frames
+func ChangeFrameRate(subtitle *common.Subtitle, oldFrameRate float64, newFrameRate float64) {
+	subtitle.ChangeFrameRate(oldFrameRate, newFrameRate)
+}
+
+// ChangeFrameRateFromMatroska retimes a subtitle made for the video of a Matroska file to video at another frame
+// rate, taking the frame rate of the Matroska file as the old frame rate
+func ChangeFrameRateFromMatroska(subtitle *common.Subtitle, matroskaFile *matroska.MatroskaFile, newFrameRate float64) error {
+	frameRateErr := readMatroskaFrameRate(matroskaFile)
+	if frameRateErr != nil {
+		return frameRateErr
+	}
+
+	ChangeFrameRate(subtitle, matroskaFile.FrameRate, newFrameRate)
+
+	return nil
+}
+
+// ChangeFrameRateToMatroska retimes a subtitle made for video at a frame rate to the video of a Matroska file,
+// taking the frame rate of the Matroska file as the new frame rate
+func ChangeFrameRateToMatroska(subtitle *common.Subtitle, oldFrameRate float64, matroskaFile *matroska.MatroskaFile) error {
+	frameRateErr := readMatroskaFrameRate(matroskaFile)
+	if frameRateErr != nil {
+		return frameRateErr
+	}
+
+	ChangeFrameRate(subtitle, oldFrameRate, matroskaFile.FrameRate)
+
+	return nil
+}
+
+// Shift adds an offset in milliseconds, which may be negative, to the times of every paragraph
+func Shift(subtitle *common.Subtitle, offset float64) {
+	ShiftFrom(subtitle, 0, offset)
+}
+
+// ShiftFrom adds an offset in milliseconds to the times of the paragraph at an index and every paragraph after it
+func ShiftFrom(subtitle *common.Subtitle, index int, offset float64) {
+	transform(subtitle, index, func(milliseconds float64) float64 {
+		return milliseconds + offset
+	})
+}
+
+// Stretch multiplies the times of every paragraph by a factor, so that a factor of 1.1 makes the subtitle 10% longer
+func Stretch(subtitle *common.Subtitle, factor float64) {
+	transform(subtitle, 0, func(milliseconds float64) float64 {
+		return milliseconds * factor
+	})
+}
+
+// SyncByPoints moves two times of a subtitle to where they should be, such as the first and last paragraph, and
+// moves and stretches all other times in proportion
+func SyncByPoints(subtitle *common.Subtitle, first Point, second Point) error {
+	if first.Old == second.Old {
+		return errors.Newf("points need different old times, both are %v", first.Old)
+	}
+
+	factor := (second.New - first.New) / (second.Old - first.Old)
+	if factor <= 0 {
+		return errors.Newf("points would reverse the order of paragraphs, factor is %v", factor)
+	}
+
+	transform(subtitle, 0, func(milliseconds float64) float64 {
+		return first.New + (milliseconds-first.Old)*factor
+	})
+
+	return nil
+}
+
+// readMatroskaFrameRate reads the tracks of a Matroska file, which give its frame rate, and fails if the frame rate
+// is still unknown
+func readMatroskaFrameRate(matroskaFile *matroska.MatroskaFile) error {
+	_, tracksErr := matroskaFile.Tracks(false)
+	if tracksErr != nil {
+		return errors.Wrap(tracksErr, "failed to read tracks for frame rate")
+	}
+
+	if matroskaFile.FrameRate <= 0 {
+		return errors.New("frame rate of Matroska file is unknown")
+	}
+
+	return nil
+}
+
+func transform(subtitle *common.Subtitle, index int, transformTime func(milliseconds float64) float64) {
+	for i := max(0, index); i < len(subtitle.Paragraphs); i++ {
+		subtitle.Paragraphs[i].StartTime.TotalMilliseconds = transformTime(subtitle.Paragraphs[i].StartTime.TotalMilliseconds)
+		subtitle.Paragraphs[i].EndTime.TotalMilliseconds = transformTime(subtitle.Paragraphs[i].EndTime.TotalMilliseconds)
+	}
+}
@@ -0,0 +1,149 @@
+package sync
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ristryder/gse/common"
+	"github.com/ristryder/gse/containers/matroska"
+	"github.com/ristryder/gse/internal/mkvtest"
+)
+
+func checkTimes(t *testing.T, name string, subtitle *common.Subtitle, expected [][2]float64) {
+	t.Helper()
+
+	if len(subtitle.Paragraphs) != len(expected) {
+		t.Fatalf("%s: got %d paragraphs, expected %d", name, len(subtitle.Paragraphs), len(expected))
+	}
+	for i, paragraph := range subtitle.Paragraphs {
+		if math.Abs(paragraph.StartTime.TotalMilliseconds-expected[i][0]) > 0.001 || math.Abs(paragraph.EndTime.TotalMilliseconds-expected[i][1]) > 0.001 {
+			t.Errorf("%s: paragraph %d is from %v to %v, expected from %v to %v", name, i, paragraph.StartTime.TotalMilliseconds, paragraph.EndTime.TotalMilliseconds, expected[i][0], expected[i][1])
+		}
+	}
+}
+
+// matroskaFile returns a file with a video track, which has a frame rate when the default duration is not zero
+func matroskaFile(t *testing.T, defaultDuration uint64) *matroska.MatroskaFile {
+	t.Helper()
+
+	trackEntry := [][]byte{
+		mkvtest.UInt(uint32(matroska.ElementTrackNumber), 1),
+		mkvtest.UInt(uint32(matroska.ElementTrackType), mkvtest.TrackTypeVideo),
+		mkvtest.String(uint32(matroska.ElementCodecId), "V_MPEG4/ISO/AVC"),
+	}
+	if defaultDuration > 0 {
+		trackEntry = append(trackEntry, mkvtest.UInt(uint32(matroska.ElementDefaultDuration), defaultDuration))
+	}
+
+	data := mkvtest.File(
+		mkvtest.Element(uint32(matroska.ElementInfo), mkvtest.UInt(uint32(matroska.ElementTimecodeScale), 1000000)),
+		mkvtest.Element(uint32(matroska.ElementTracks), mkvtest.Element(uint32(matroska.ElementTrackEntry), trackEntry...)),
+	)
+
+	file, fileErr := matroska.NewMatroskaFileFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if fileErr != nil {
+		t.Fatal(fileErr)
+	}
+
+	return file
+}
+
+func newSubtitle() *common.Subtitle {
+	return &common.Subtitle{Paragraphs: []common.Paragraph{
+		*common.NewParagraph("First", 1000, 2000),
+		*common.NewParagraph("Second", 5000, 6000),
+		*common.NewParagraph("Third", 10000, 11000),
+	}}
+}
+
+func TestShift(t *testing.T) {
+	subtitle := newSubtitle()
+	Shift(subtitle, -500)
+	checkTimes(t, "shift", subtitle, [][2]float64{{500, 1500}, {4500, 5500}, {9500, 10500}})
+
+	subtitle = newSubtitle()
+	ShiftFrom(subtitle, 1, 250)
+	checkTimes(t, "shift from", subtitle, [][2]float64{{1000, 2000}, {5250, 6250}, {10250, 11250}})
+
+	subtitle = newSubtitle()
+	ShiftFrom(subtitle, -1, 100)
+	checkTimes(t, "shift from before start", subtitle, [][2]float64{{1100, 2100}, {5100, 6100}, {10100, 11100}})
+}
+
+func TestStretch(t *testing.T) {
+	subtitle := newSubtitle()
+	Stretch(subtitle, 1.5)
+	checkTimes(t, "stretch", subtitle, [][2]float64{{1500, 3000}, {7500, 9000}, {15000, 16500}})
+}
+
+func TestChangeFrameRate(t *testing.T) {
+	subtitle := newSubtitle()
+	ChangeFrameRate(subtitle, 25, 23.976)
+
+	factor := 25 / 23.976
+	checkTimes(t, "frame rate", subtitle, [][2]float64{{1000 * factor, 2000 * factor}, {5000 * factor, 6000 * factor}, {10000 * factor, 11000 * factor}})
+}
+
+func TestSyncByPoints(t *testing.T) {
+	subtitle := newSubtitle()
+	if syncErr := SyncByPoints(subtitle, Point{New: 1500, Old: 1000}, Point{New: 12000, Old: 10000}); syncErr != nil {
+		t.Fatal(syncErr)
+	}
+
+	//The factor is 10500 / 9000
+	checkTimes(t, "sync", subtitle, [][2]float64{{1500, 1500 + 1000*10500.0/9000}, {1500 + 4000*10500.0/9000, 1500 + 5000*10500.0/9000}, {12000, 12000 + 1000*10500.0/9000}})
+}
+
+func TestSyncByPointsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  Point
+		second Point
+	}{
+		{name: "equal old times", first: Point{New: 1000, Old: 1000}, second: Point{New: 2000, Old: 1000}},
+		{name: "negative factor", first: Point{New: 2000, Old: 1000}, second: Point{New: 1000, Old: 2000}},
+		{name: "zero factor", first: Point{New: 1000, Old: 1000}, second: Point{New: 1000, Old: 2000}},
+	}
+
+	for _, test := range tests {
+		subtitle := newSubtitle()
+		if syncErr := SyncByPoints(subtitle, test.first, test.second); syncErr == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		checkTimes(t, test.name, subtitle, [][2]float64{{1000, 2000}, {5000, 6000}, {10000, 11000}})
+	}
+}
+
+func TestChangeFrameRateMatroska(t *testing.T) {
+	factor := 25 / 23.976
+
+	//A default duration of 40 ms is 25 frames per second
+	subtitle := newSubtitle()
+	if frameRateErr := ChangeFrameRateFromMatroska(subtitle, matroskaFile(t, 40000000), 23.976); frameRateErr != nil {
+		t.Fatal(frameRateErr)
+	}
+	checkTimes(t, "from Matroska", subtitle, [][2]float64{{1000 * factor, 2000 * factor}, {5000 * factor, 6000 * factor}, {10000 * factor, 11000 * factor}})
+
+	subtitle = newSubtitle()
+	if frameRateErr := ChangeFrameRateToMatroska(subtitle, 23.976, matroskaFile(t, 40000000)); frameRateErr != nil {
+		t.Fatal(frameRateErr)
+	}
+	checkTimes(t, "to Matroska", subtitle, [][2]float64{{1000 / factor, 2000 / factor}, {5000 / factor, 6000 / factor}, {10000 / factor, 11000 / factor}})
+
+	subtitle = newSubtitle()
+	if frameRateErr := ChangeFrameRateFromMatroska(subtitle, matroskaFile(t, 0), 23.976); frameRateErr == nil {
+		t.Error("expected an error for a file without a frame rate")
+	}
+	if frameRateErr := ChangeFrameRateToMatroska(subtitle, 23.976, matroskaFile(t, 0)); frameRateErr == nil {
+		t.Error("expected an error for a file without a frame rate")
+	}
+	checkTimes(t, "unknown frame rate", subtitle, [][2]float64{{1000, 2000}, {5000, 6000}, {10000, 11000}})
+}
+
+func TestPointString(t *testing.T) {
+	point := Point{New: 1500, Old: 1000}
+	if text := point.String(); text != "Old: 1000 , New: 1500" {
+		t.Errorf("got %q", text)
+	}
+}